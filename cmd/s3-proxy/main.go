@@ -1,6 +1,12 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
@@ -12,6 +18,9 @@ import (
 
 // Main package
 
+// shutdownTimeout Maximum duration to wait for requests in progress when servers are stopped
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	// Create new logger
 	logger := log.NewLogger()
@@ -83,8 +92,30 @@ func main() {
 
 	g.Go(svr.Listen)
 	g.Go(intSvr.Listen)
+	// Stop servers gracefully on termination signals
+	g.Go(func() error { return stopOnSignal(logger, svr, intSvr) })
 
 	if err := g.Wait(); err != nil {
 		logger.Fatal(err)
 	}
 }
+
+// stopOnSignal will wait for a SIGINT or SIGTERM signal and will stop servers
+// Requests in progress are finished and pending asynchronous mirror writes are done before servers are stopped.
+func stopOnSignal(logger log.Logger, svr *server.Server, intSvr *server.InternalServer) error {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigs
+
+	logger.Infof("Signal %s received, stopping servers", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := svr.Shutdown(ctx)
+	if err != nil {
+		return err
+	}
+
+	return intSvr.Shutdown(ctx)
+}
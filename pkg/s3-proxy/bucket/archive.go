@@ -0,0 +1,251 @@
+package bucket
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
+)
+
+// ErrArchiveDisabled will be raised when end user is asking an archive and it isn't enabled
+var ErrArchiveDisabled = errors.New("archive download isn't enabled")
+
+// ErrArchiveFormat will be raised when end user is asking an archive with an unsupported format
+var ErrArchiveFormat = fmt.Errorf("archive format must be %s or %s", ArchiveZipFormat, ArchiveTarGzFormat)
+
+// ErrArchiveNotFolder will be raised when end user is asking an archive on a file path
+var ErrArchiveNotFolder = errors.New("archive can only be asked on folders")
+
+// ErrArchiveTooManyObjects will be raised when folder contains more objects than the maximum allowed in an archive
+var ErrArchiveTooManyObjects = errors.New("folder contains too many objects for an archive")
+
+// ErrArchiveTooLarge will be raised when folder objects total size is greater than the maximum allowed in an archive
+var ErrArchiveTooLarge = errors.New("folder is too large for an archive")
+
+// archiveWriter Archive writer used to stream objects in response
+type archiveWriter interface {
+	// addFile will add a file entry with content in archive
+	addFile(entry *s3client.ListElementOutput, body io.Reader) error
+	// Close will flush and close archive
+	Close() error
+}
+
+// zipArchiveWriter ZIP archive writer
+type zipArchiveWriter struct {
+	writer *zip.Writer
+}
+
+func (z *zipArchiveWriter) addFile(entry *s3client.ListElementOutput, body io.Reader) error {
+	w, err := z.writer.CreateHeader(&zip.FileHeader{
+		Name:     entry.Name,
+		Method:   zip.Deflate,
+		Modified: entry.LastModified,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, body)
+
+	return err
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.writer.Close()
+}
+
+// tarGzArchiveWriter TAR GZ archive writer
+type tarGzArchiveWriter struct {
+	gzWriter  *gzip.Writer
+	tarWriter *tar.Writer
+}
+
+func (t *tarGzArchiveWriter) addFile(entry *s3client.ListElementOutput, body io.Reader) error {
+	err := t.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     entry.Name,
+		Size:     entry.Size,
+		Mode:     0644,
+		ModTime:  entry.LastModified,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(t.tarWriter, body)
+
+	return err
+}
+
+func (t *tarGzArchiveWriter) Close() error {
+	err := t.tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return t.gzWriter.Close()
+}
+
+// newArchiveWriter will create an archive writer for format with the associated content type
+func newArchiveWriter(format string, w io.Writer) (archiveWriter, string) {
+	switch format {
+	case ArchiveZipFormat:
+		return &zipArchiveWriter{writer: zip.NewWriter(w)}, "application/zip"
+	case ArchiveTarGzFormat:
+		gzWriter := gzip.NewWriter(w)
+
+		return &tarGzArchiveWriter{gzWriter: gzWriter, tarWriter: tar.NewWriter(gzWriter)}, "application/gzip"
+	default:
+		return nil, ""
+	}
+}
+
+// manageGetArchive will stream all objects under folder key in an archive
+func (rctx *requestContext) manageGetArchive(key string, input *GetInput) {
+	requestPath := input.RequestPath
+	// Get archive configuration
+	archiveCfg := rctx.targetCfg.GetArchive()
+	if archiveCfg == nil {
+		rctx.logger.Error(ErrArchiveDisabled)
+		rctx.HandleBadRequest(ErrArchiveDisabled, requestPath)
+		// Stop
+		return
+	}
+	// Check that request path is a folder
+	if !strings.HasSuffix(requestPath, "/") && requestPath != "" {
+		rctx.logger.Error(ErrArchiveNotFolder)
+		rctx.HandleBadRequest(ErrArchiveNotFolder, requestPath)
+		// Stop
+		return
+	}
+	// Check format
+	if input.Archive != ArchiveZipFormat && input.Archive != ArchiveTarGzFormat {
+		rctx.logger.Error(ErrArchiveFormat)
+		rctx.HandleBadRequest(ErrArchiveFormat, requestPath)
+		// Stop
+		return
+	}
+	// List all objects before streaming to check limits because status code cannot be changed after
+	listOutput, err := rctx.s3Context.ListAllObjects(&s3client.ListAllObjectsInput{
+		Prefix:     key,
+		MaxObjects: archiveCfg.GetMaxObjects(),
+	})
+	if err != nil {
+		rctx.logger.Error(err)
+		rctx.HandleInternalServerError(err, requestPath)
+		// Stop
+		return
+	}
+	// Check number of objects
+	if listOutput.Truncated {
+		rctx.logger.Error(ErrArchiveTooManyObjects)
+		rctx.HandleBadRequest(ErrArchiveTooManyObjects, requestPath)
+		// Stop
+		return
+	}
+	// Keep entries with a safe name in archive
+	entries := make([]*s3client.ListElementOutput, 0, len(listOutput.Entries))
+	for _, entry := range listOutput.Entries {
+		name, ok := getArchiveEntryName(entry.Name)
+		if !ok {
+			rctx.logger.Warnf("Object %s is ignored in archive of path %s because its name escapes folder", entry.Key, requestPath)
+
+			continue
+		}
+
+		archiveEntry := *entry
+		archiveEntry.Name = name
+		entries = append(entries, &archiveEntry)
+	}
+	// Check if folder exists
+	if len(entries) == 0 {
+		rctx.HandleNotFound(requestPath)
+		// Stop
+		return
+	}
+	// Check total size
+	var totalSize int64
+	for _, entry := range entries {
+		totalSize += entry.Size
+	}
+
+	if totalSize > archiveCfg.GetMaxTotalSize() {
+		rctx.logger.Error(ErrArchiveTooLarge)
+		rctx.HandleBadRequest(ErrArchiveTooLarge, requestPath)
+		// Stop
+		return
+	}
+	// Create archive writer on response
+	writer, contentType := newArchiveWriter(input.Archive, rctx.httpRW)
+	// Set headers
+	rctx.httpRW.Header().Set("Content-Type", contentType)
+	rctx.httpRW.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", rctx.getArchiveName(key)+"."+input.Archive),
+	)
+	rctx.httpRW.WriteHeader(http.StatusOK)
+	// Check if archive content is needed
+	if input.HeadOnly {
+		// Stop
+		return
+	}
+	// Stream objects in archive
+	for _, entry := range entries {
+		err = rctx.addObjectInArchive(writer, entry)
+		// Check if error exists
+		if err != nil {
+			// Status code is already sent so archive is left incomplete to show the error to client
+			rctx.logger.Errorf("Archive of path %s failed on object %s: %v", requestPath, entry.Key, err)
+			// Stop
+			return
+		}
+	}
+	// Close archive
+	err = writer.Close()
+	if err != nil {
+		rctx.logger.Error(err)
+	}
+}
+
+// addObjectInArchive will get object and add it in archive
+func (rctx *requestContext) addObjectInArchive(writer archiveWriter, entry *s3client.ListElementOutput) error {
+	objOutput, err := rctx.s3Context.GetObject(&s3client.GetInput{Key: entry.Key})
+	if err != nil {
+		return err
+	}
+	// Close object body at the end
+	defer (*objOutput.Body).Close()
+
+	return writer.addFile(entry, *objOutput.Body)
+}
+
+// getArchiveEntryName will clean object name relative to archive folder to use it in archive
+// Names escaping folder (like "../file" or "/file") aren't valid because they could be extracted outside of destination folder.
+func getArchiveEntryName(name string) (string, bool) {
+	// Backslashes are path separators for some archive extractors
+	cleaned := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	// Check if name escapes folder
+	if path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false
+	}
+
+	return cleaned, true
+}
+
+// getArchiveName will return archive name without extension from folder key
+func (rctx *requestContext) getArchiveName(key string) string {
+	name := path.Base(strings.TrimSuffix(key, "/"))
+	// Use target name for bucket root
+	if name == "." || name == "/" || name == "" {
+		return rctx.targetCfg.Name
+	}
+
+	return name
+}
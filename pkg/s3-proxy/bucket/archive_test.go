@@ -0,0 +1,304 @@
+// +build unit
+
+package bucket
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
+)
+
+func Test_requestContext_manageGetArchive(t *testing.T) {
+	handleNotFoundCalled := false
+	handleInternalServerErrorCalled := false
+	handleBadRequestCalled := false
+	errorHandlers := &ErrorHandlers{
+		HandleNotFoundWithTemplate: func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
+			handleNotFoundCalled = true
+		},
+		HandleInternalServerErrorWithTemplate: func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+			handleInternalServerErrorCalled = true
+		},
+		HandleBadRequestWithTemplate: func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+			handleBadRequestCalled = true
+		},
+	}
+	archiveTarget := func(archiveCfg *config.ArchiveConfig) *config.TargetConfig {
+		return &config.TargetConfig{
+			Name:   "target",
+			Bucket: &config.BucketConfig{Prefix: "/"},
+			Actions: &config.ActionsConfig{
+				GET: &config.GetActionConfig{Enabled: true, Config: &config.GetActionConfigConfig{Archive: archiveCfg}},
+			},
+		}
+	}
+	lastModified := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	entries := []*s3client.ListElementOutput{
+		{Type: s3client.FileType, Name: "sub/file.txt", Key: "/folder/sub/file.txt", Size: 7, LastModified: lastModified},
+	}
+	newBody := func() *io.ReadCloser {
+		body := ioutil.NopCloser(strings.NewReader("content"))
+		return &body
+	}
+	tests := []struct {
+		name                                    string
+		s3Context                               *s3clientTest
+		targetCfg                               *config.TargetConfig
+		input                                   *GetInput
+		expectedHandleNotFoundCalled            bool
+		expectedHandleInternalServerErrorCalled bool
+		expectedHandleBadRequestCalled          bool
+		expectedS3ClientListAllInput            *s3client.ListAllObjectsInput
+		expectedS3ClientGetCalled               bool
+		expectedContentType                     string
+		expectedContentDisposition              string
+	}{
+		{
+			name:                           "should fail when archive isn't enabled",
+			s3Context:                      &s3clientTest{},
+			targetCfg:                      archiveTarget(nil),
+			input:                          &GetInput{RequestPath: "/folder/", Archive: ArchiveZipFormat},
+			expectedHandleBadRequestCalled: true,
+		},
+		{
+			name:                           "should fail when request path is a file",
+			s3Context:                      &s3clientTest{},
+			targetCfg:                      archiveTarget(&config.ArchiveConfig{Enabled: true}),
+			input:                          &GetInput{RequestPath: "/folder/file", Archive: ArchiveZipFormat},
+			expectedHandleBadRequestCalled: true,
+		},
+		{
+			name:                           "should fail when format isn't supported",
+			s3Context:                      &s3clientTest{},
+			targetCfg:                      archiveTarget(&config.ArchiveConfig{Enabled: true}),
+			input:                          &GetInput{RequestPath: "/folder/", Archive: "rar"},
+			expectedHandleBadRequestCalled: true,
+		},
+		{
+			name:                                    "should fail when list fails",
+			s3Context:                               &s3clientTest{ListAllErr: errors.New("test")},
+			targetCfg:                               archiveTarget(&config.ArchiveConfig{Enabled: true}),
+			input:                                   &GetInput{RequestPath: "/folder/", Archive: ArchiveZipFormat},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientListAllInput:            &s3client.ListAllObjectsInput{Prefix: "/folder/", MaxObjects: config.DefaultArchiveMaxObjects},
+		},
+		{
+			name:                           "should fail when folder contains too many objects",
+			s3Context:                      &s3clientTest{ListAllResult: &s3client.ListAllObjectsOutput{Entries: entries, Truncated: true}},
+			targetCfg:                      archiveTarget(&config.ArchiveConfig{Enabled: true, MaxObjects: 1}),
+			input:                          &GetInput{RequestPath: "/folder/", Archive: ArchiveZipFormat},
+			expectedHandleBadRequestCalled: true,
+			expectedS3ClientListAllInput:   &s3client.ListAllObjectsInput{Prefix: "/folder/", MaxObjects: 1},
+		},
+		{
+			name:                           "should fail when folder is too large",
+			s3Context:                      &s3clientTest{ListAllResult: &s3client.ListAllObjectsOutput{Entries: entries}},
+			targetCfg:                      archiveTarget(&config.ArchiveConfig{Enabled: true, MaxTotalSize: 6}),
+			input:                          &GetInput{RequestPath: "/folder/", Archive: ArchiveZipFormat},
+			expectedHandleBadRequestCalled: true,
+			expectedS3ClientListAllInput:   &s3client.ListAllObjectsInput{Prefix: "/folder/", MaxObjects: config.DefaultArchiveMaxObjects},
+		},
+		{
+			name:                         "should answer not found when folder is empty",
+			s3Context:                    &s3clientTest{ListAllResult: &s3client.ListAllObjectsOutput{Entries: []*s3client.ListElementOutput{}}},
+			targetCfg:                    archiveTarget(&config.ArchiveConfig{Enabled: true}),
+			input:                        &GetInput{RequestPath: "/folder/", Archive: ArchiveZipFormat},
+			expectedHandleNotFoundCalled: true,
+			expectedS3ClientListAllInput: &s3client.ListAllObjectsInput{Prefix: "/folder/", MaxObjects: config.DefaultArchiveMaxObjects},
+		},
+		{
+			name: "should stream a zip archive",
+			s3Context: &s3clientTest{
+				ListAllResult: &s3client.ListAllObjectsOutput{Entries: entries},
+				GetResult:     &s3client.GetOutput{Body: newBody()},
+			},
+			targetCfg:                    archiveTarget(&config.ArchiveConfig{Enabled: true}),
+			input:                        &GetInput{RequestPath: "/folder/", Archive: ArchiveZipFormat},
+			expectedS3ClientListAllInput: &s3client.ListAllObjectsInput{Prefix: "/folder/", MaxObjects: config.DefaultArchiveMaxObjects},
+			expectedS3ClientGetCalled:    true,
+			expectedContentType:          "application/zip",
+			expectedContentDisposition:   `attachment; filename="folder.zip"`,
+		},
+		{
+			name: "should skip objects with a name escaping folder",
+			s3Context: &s3clientTest{
+				ListAllResult: &s3client.ListAllObjectsOutput{Entries: []*s3client.ListElementOutput{
+					{Type: s3client.FileType, Name: "../evil.txt", Key: "/folder/../evil.txt", Size: 7},
+					{Type: s3client.FileType, Name: "/evil.txt", Key: "/folder//evil.txt", Size: 7},
+					{Type: s3client.FileType, Name: "sub/../../evil.txt", Key: "/folder/sub/../../evil.txt", Size: 7},
+					{Type: s3client.FileType, Name: "sub/./file.txt", Key: "/folder/sub/./file.txt", Size: 7},
+				}},
+				GetResult: &s3client.GetOutput{Body: newBody()},
+			},
+			targetCfg:                    archiveTarget(&config.ArchiveConfig{Enabled: true}),
+			input:                        &GetInput{RequestPath: "/folder/", Archive: ArchiveZipFormat},
+			expectedS3ClientListAllInput: &s3client.ListAllObjectsInput{Prefix: "/folder/", MaxObjects: config.DefaultArchiveMaxObjects},
+			expectedS3ClientGetCalled:    true,
+			expectedContentType:          "application/zip",
+			expectedContentDisposition:   `attachment; filename="folder.zip"`,
+		},
+		{
+			name: "should answer not found when all objects have a name escaping folder",
+			s3Context: &s3clientTest{ListAllResult: &s3client.ListAllObjectsOutput{Entries: []*s3client.ListElementOutput{
+				{Type: s3client.FileType, Name: "../evil.txt", Key: "/folder/../evil.txt", Size: 7},
+			}}},
+			targetCfg:                    archiveTarget(&config.ArchiveConfig{Enabled: true}),
+			input:                        &GetInput{RequestPath: "/folder/", Archive: ArchiveTarGzFormat},
+			expectedHandleNotFoundCalled: true,
+			expectedS3ClientListAllInput: &s3client.ListAllObjectsInput{Prefix: "/folder/", MaxObjects: config.DefaultArchiveMaxObjects},
+		},
+		{
+			name: "should stream a tar.gz archive named with target name for bucket root",
+			s3Context: &s3clientTest{
+				ListAllResult: &s3client.ListAllObjectsOutput{Entries: entries},
+				GetResult:     &s3client.GetOutput{Body: newBody()},
+			},
+			targetCfg:                    archiveTarget(&config.ArchiveConfig{Enabled: true}),
+			input:                        &GetInput{RequestPath: "", Archive: ArchiveTarGzFormat},
+			expectedS3ClientListAllInput: &s3client.ListAllObjectsInput{Prefix: "/", MaxObjects: config.DefaultArchiveMaxObjects},
+			expectedS3ClientGetCalled:    true,
+			expectedContentType:          "application/gzip",
+			expectedContentDisposition:   `attachment; filename="target.tar.gz"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handleNotFoundCalled = false
+			handleInternalServerErrorCalled = false
+			handleBadRequestCalled = false
+			recorder := httptest.NewRecorder()
+			rctx := &requestContext{
+				s3Context:      tt.s3Context,
+				logger:         log.NewLogger(),
+				targetCfg:      tt.targetCfg,
+				tplConfig:      &config.TemplateConfig{},
+				mountPath:      "/mount",
+				httpRW:         recorder,
+				errorsHandlers: errorHandlers,
+			}
+			rctx.Get(tt.input)
+			if handleNotFoundCalled != tt.expectedHandleNotFoundCalled {
+				t.Errorf("requestContext.Get() => handleNotFoundCalled = %+v, want %+v", handleNotFoundCalled, tt.expectedHandleNotFoundCalled)
+			}
+			if handleInternalServerErrorCalled != tt.expectedHandleInternalServerErrorCalled {
+				t.Errorf("requestContext.Get() => handleInternalServerErrorCalled = %+v, want %+v", handleInternalServerErrorCalled, tt.expectedHandleInternalServerErrorCalled)
+			}
+			if handleBadRequestCalled != tt.expectedHandleBadRequestCalled {
+				t.Errorf("requestContext.Get() => handleBadRequestCalled = %+v, want %+v", handleBadRequestCalled, tt.expectedHandleBadRequestCalled)
+			}
+			if !reflect.DeepEqual(tt.expectedS3ClientListAllInput, tt.s3Context.ListAllInput) {
+				t.Errorf("requestContext.Get() => s3client.ListAllInput = %+v, want %+v", tt.s3Context.ListAllInput, tt.expectedS3ClientListAllInput)
+			}
+			if tt.expectedS3ClientGetCalled != tt.s3Context.GetCalled {
+				t.Errorf("requestContext.Get() => s3client.GetCalled = %+v, want %+v", tt.s3Context.GetCalled, tt.expectedS3ClientGetCalled)
+			}
+			// Check archive content when one is expected
+			if tt.expectedContentType == "" {
+				return
+			}
+			if recorder.Code != http.StatusOK {
+				t.Errorf("requestContext.Get() => status = %d, want %d", recorder.Code, http.StatusOK)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.expectedContentType {
+				t.Errorf("requestContext.Get() => Content-Type = %s, want %s", got, tt.expectedContentType)
+			}
+			if got := recorder.Header().Get("Content-Disposition"); got != tt.expectedContentDisposition {
+				t.Errorf("requestContext.Get() => Content-Disposition = %s, want %s", got, tt.expectedContentDisposition)
+			}
+			files := readTestArchive(t, tt.input.Archive, recorder.Body.Bytes())
+			if len(files) != 1 || files["sub/file.txt"] != "content" {
+				t.Errorf("requestContext.Get() => archive files = %+v, want %+v", files, map[string]string{"sub/file.txt": "content"})
+			}
+		})
+	}
+}
+
+// readTestArchive will read all files of an archive
+func readTestArchive(t *testing.T, format string, content []byte) map[string]string {
+	files := map[string]string{}
+
+	if format == ArchiveZipFormat {
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, f := range zr.File {
+			r, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Close()
+			files[f.Name] = string(b)
+		}
+
+		return files
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[h.Name] = string(b)
+	}
+
+	return files
+}
+
+func Test_getArchiveEntryName(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOk bool
+	}{
+		{name: "file.txt", want: "file.txt", wantOk: true},
+		{name: "sub/file.txt", want: "sub/file.txt", wantOk: true},
+		{name: "sub/../file.txt", want: "file.txt", wantOk: true},
+		{name: "sub//./file.txt", want: "sub/file.txt", wantOk: true},
+		{name: "..file.txt", want: "..file.txt", wantOk: true},
+		{name: "../file.txt", wantOk: false},
+		{name: "sub/../../file.txt", wantOk: false},
+		{name: "..", wantOk: false},
+		{name: "/file.txt", wantOk: false},
+		{name: "..\\file.txt", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOk := getArchiveEntryName(tt.name)
+			if got != tt.want || gotOk != tt.wantOk {
+				t.Errorf("getArchiveEntryName() = %v, %v, want %v, %v", got, gotOk, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
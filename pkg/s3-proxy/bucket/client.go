@@ -3,22 +3,24 @@ package bucket
 import (
 	"io"
 	"net/http"
+	"time"
 
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/authx/models"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
-	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
-	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/tracing"
 )
 
-// Client represents a client in order to GET, PUT or DELETE file on a bucket with a html output
+// Client represents a client in order to GET, HEAD, PUT or DELETE file on a bucket with a html output
 type Client interface {
-	// Get allow to GET what's inside a request path
-	Get(requestPath string)
+	// Get allow to GET (or HEAD) what's inside a request path
+	Get(input *GetInput)
 	// Put will put a file following input
 	Put(inp *PutInput)
-	// Delete will delete file on request path
-	Delete(requestPath string)
+	// PresignUpload will answer with a presigned upload URL following input
+	PresignUpload(input *PresignUploadInput)
+	// Delete will delete file or folder (if enabled) following input
+	Delete(input *DeleteInput)
 	// Handle not found errors with bucket configuration
 	HandleNotFound(requestPath string)
 	// Handle forbidden errors with bucket configuration
@@ -31,44 +33,153 @@ type Client interface {
 	HandleUnauthorized(requestPath string)
 }
 
+// GetInput represents Get input
+type GetInput struct {
+	RequestPath       string
+	Range             string
+	IfRange           string
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+	// ContinuationToken is the continuation token of the folder listing page
+	ContinuationToken string
+	// MaxKeys is the maximum number of entries asked for the folder listing page
+	MaxKeys int64
+	// PreviousContinuationTokens is the list of continuation tokens of previous folder listing pages
+	PreviousContinuationTokens []string
+	// JSONListing is enabled when folder listing must be answered as a JSON document
+	JSONListing bool
+	// Archive is the archive format asked for a folder download (empty if no archive is asked)
+	Archive string
+	// VersionID is the file version asked (current version if empty)
+	VersionID string
+	// Versions is enabled when the list of file versions is asked
+	Versions bool
+	// AcceptEncoding is the Accept-Encoding header value used to select precompressed object variants
+	AcceptEncoding string
+	// HeadOnly is enabled for HEAD requests to answer with the same headers as GET requests without getting objects content
+	HeadOnly bool
+}
+
+// ContinuationTokenQueryParam Query parameter used for folder listing continuation token
+const ContinuationTokenQueryParam = "continuation-token"
+
+// MaxKeysQueryParam Query parameter used for folder listing maximum number of entries
+const MaxKeysQueryParam = "max-keys"
+
+// PreviousContinuationTokenQueryParam Query parameter used for folder listing previous pages continuation tokens
+const PreviousContinuationTokenQueryParam = "previous-token"
+
+// ArchiveQueryParam Query parameter used to download a folder as an archive
+const ArchiveQueryParam = "archive"
+
+// ArchiveZipFormat ZIP archive format
+const ArchiveZipFormat = "zip"
+
+// ArchiveTarGzFormat TAR GZ archive format
+const ArchiveTarGzFormat = "tar.gz"
+
+// VersionIDQueryParam Query parameter used to get or delete a file version
+const VersionIDQueryParam = "versionId"
+
+// VersionsQueryParam Query parameter used to list file versions
+const VersionsQueryParam = "versions"
+
 // PutInput represents Put input
 type PutInput struct {
 	RequestPath string
+	// Files to upload. When more than one file is given, a report is answered
+	Files []*PutFileInput
+	// UploadContext is used in metadata and tags templates
+	UploadContext *UploadContext
+}
+
+// UploadContext represents upload request information used in metadata and tags templates
+type UploadContext struct {
+	// User is the authenticated user (nil if request isn't authenticated)
+	User     models.GenericUser
+	ClientIP string
+	Time     time.Time
+}
+
+// PutFileInput represents a file in Put input
+type PutFileInput struct {
+	// Filename will be added to request path directory to generate key. It can be a relative path.
+	// If empty, request path will be used as key
 	Filename    string
 	Body        io.Reader
 	ContentType string
+	// Size of the file if known (0 otherwise)
+	Size int64
+}
+
+// DeleteInput represents Delete input
+type DeleteInput struct {
+	RequestPath string
+	// DryRun will only report what would be deleted
+	DryRun bool
+	// VersionID is the file version to delete (current version if empty)
+	VersionID string
+}
+
+// PresignUploadInput represents PresignUpload input
+type PresignUploadInput struct {
+	RequestPath string
+	// Method is the presigned upload method (put or post)
+	Method string
+	// UploadContext is used in metadata and tags templates
+	UploadContext *UploadContext
+	// ContentType is the declared file content type (empty if not declared)
+	ContentType string
+	// Size is the declared file size (-1 if not declared)
+	Size int64
 }
 
+// PutFileFormKey Multipart form key containing files in PUT requests
+const PutFileFormKey = "file"
+
+// PutRelativePathFormKey Multipart form key containing optional relative paths of files in PUT requests
+const PutRelativePathFormKey = "relativePath"
+
+// PresignedUploadQueryParam Query parameter used to ask a presigned upload URL on PUT requests
+const PresignedUploadQueryParam = "presigned-upload"
+
+// PresignedUploadContentTypeQueryParam Query parameter used to declare file content type of a presigned upload
+const PresignedUploadContentTypeQueryParam = "content-type"
+
+// PresignedUploadSizeQueryParam Query parameter used to declare file size of a presigned upload
+const PresignedUploadSizeQueryParam = "size"
+
+// DryRunQueryParam Query parameter used to enable dry run mode on DELETE requests
+const DryRunQueryParam = "dry-run"
+
 // ErrorHandlers error handlers
 type ErrorHandlers struct {
-	HandleNotFoundWithTemplate            func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string)            //nolint: lll
-	HandleForbiddenWithTemplate           func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string)            //nolint: lll
-	HandleUnauthorizedWithTemplate        func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string)            //nolint: lll
-	HandleBadRequestWithTemplate          func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) //nolint: lll
-	HandleInternalServerErrorWithTemplate func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) //nolint: lll
+	HandleNotFoundWithTemplate              func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string)            //nolint: lll
+	HandleForbiddenWithTemplate             func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string)            //nolint: lll
+	HandleUnauthorizedWithTemplate          func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string)            //nolint: lll
+	HandleBadRequestWithTemplate            func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) //nolint: lll
+	HandleRequestEntityTooLargeWithTemplate func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) //nolint: lll
+	HandleBadGatewayWithTemplate            func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) //nolint: lll
+	HandleInternalServerErrorWithTemplate   func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) //nolint: lll
 }
 
-// NewClient will generate a new client to do GET,PUT or DELETE actions
+// NewClient will generate a new client to do GET, HEAD, PUT or DELETE actions
 // nolint:whitespace
 func NewClient(
 	tgt *config.TargetConfig, tplConfig *config.TemplateConfig, logger log.Logger,
 	mountPath string, httpRW http.ResponseWriter,
-	metricsCtx metrics.Client,
+	s3Context s3client.Client,
 	errorHandlers *ErrorHandlers,
-	parentTrace tracing.Trace,
-) (Client, error) {
-	s3ctx, err := s3client.NewS3Context(tgt, logger, metricsCtx, parentTrace)
-	if err != nil {
-		return nil, err
-	}
-
+) Client {
 	return &requestContext{
-		s3Context:      s3ctx,
+		s3Context:      s3Context,
 		logger:         logger,
 		targetCfg:      tgt,
 		mountPath:      mountPath,
 		httpRW:         httpRW,
 		tplConfig:      tplConfig,
 		errorsHandlers: errorHandlers,
-	}, nil
+	}
 }
@@ -0,0 +1,48 @@
+package bucket
+
+import (
+	"strings"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+)
+
+// rewriteRequestPath will apply the first matching rewrite rule on request path
+// Request path is given and returned with a leading /
+func rewriteRequestPath(rules []*config.KeyRewriteConfig, requestPath string) string {
+	// Loop over rules in order
+	for _, rule := range rules {
+		// Check if rule is matching
+		if rule.SourceRegexp != nil && rule.SourceRegexp.MatchString(requestPath) {
+			return rule.SourceRegexp.ReplaceAllString(requestPath, rule.Target)
+		}
+	}
+	// Default
+	return requestPath
+}
+
+// reverseRewriteKeyPath will apply the first matching reverse rewrite rule on key path
+// Key path is given and returned with a leading /
+func reverseRewriteKeyPath(rules []*config.KeyRewriteConfig, keyPath string) string {
+	// Loop over rules in order
+	for _, rule := range rules {
+		// Check if rule have a reverse mapping and if it is matching
+		if rule.ReverseSourceRegexp != nil && rule.ReverseSourceRegexp.MatchString(keyPath) {
+			return rule.ReverseSourceRegexp.ReplaceAllString(keyPath, rule.ReverseTarget)
+		}
+	}
+	// Default
+	return keyPath
+}
+
+// generateRequestPath will generate request path with mount path from key (reverse of generateStartKey)
+func (rctx *requestContext) generateRequestPath(key string) string {
+	bucketRootPrefixKey := rctx.targetCfg.Bucket.GetRootPrefix()
+	// Remove bucket prefix
+	p := strings.TrimPrefix(key, bucketRootPrefixKey)
+	// Check if rewrite rules are present
+	if len(rctx.targetCfg.KeyRewriteList) != 0 {
+		p = strings.TrimPrefix(reverseRewriteKeyPath(rctx.targetCfg.KeyRewriteList, "/"+strings.TrimPrefix(p, "/")), "/")
+	}
+
+	return rctx.mountPath + p
+}
@@ -0,0 +1,131 @@
+// +build unit
+
+package bucket
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+)
+
+func Test_requestContext_generateStartKey(t *testing.T) {
+	rules := []*config.KeyRewriteConfig{
+		{
+			SourceRegexp: regexp.MustCompile("^/v1/(?P<project>[^/]+)/(?P<file>.+)$"),
+			Target:       "/projects/${project}/files/${file}",
+		},
+		{
+			SourceRegexp: regexp.MustCompile("^/v1/"),
+			Target:       "/projects/",
+		},
+	}
+	tests := []struct {
+		name        string
+		prefix      string
+		rules       []*config.KeyRewriteConfig
+		requestPath string
+		want        string
+	}{
+		{
+			name:        "should add prefix without rules",
+			prefix:      "prefix",
+			requestPath: "/v1/project1/file",
+			want:        "prefix/v1/project1/file",
+		},
+		{
+			name:        "should apply first matching rule with capture groups",
+			prefix:      "prefix/",
+			rules:       rules,
+			requestPath: "/v1/project1/dir/file",
+			want:        "prefix/projects/project1/files/dir/file",
+		},
+		{
+			name:        "should apply rule on request path without leading slash",
+			prefix:      "prefix/",
+			rules:       rules,
+			requestPath: "v1/project1/file",
+			want:        "prefix/projects/project1/files/file",
+		},
+		{
+			name:        "should apply next rule when first isn't matching",
+			prefix:      "prefix/",
+			rules:       rules,
+			requestPath: "/v1/",
+			want:        "prefix/projects/",
+		},
+		{
+			name:        "should keep request path when no rule is matching",
+			prefix:      "",
+			rules:       rules,
+			requestPath: "/v2/file",
+			want:        "v2/file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rctx := &requestContext{
+				targetCfg: &config.TargetConfig{
+					Bucket:         &config.BucketConfig{Prefix: tt.prefix},
+					KeyRewriteList: tt.rules,
+				},
+			}
+			if got := rctx.generateStartKey(tt.requestPath); got != tt.want {
+				t.Errorf("requestContext.generateStartKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_requestContext_generateRequestPath(t *testing.T) {
+	rules := []*config.KeyRewriteConfig{
+		{
+			SourceRegexp: regexp.MustCompile("^/v1/([^/]+)/(.+)$"),
+			Target:       "/projects/$1/files/$2",
+		},
+		{
+			SourceRegexp:        regexp.MustCompile("^/v1/([^/]+)/(.+)$"),
+			Target:              "/projects/$1/files/$2",
+			ReverseSourceRegexp: regexp.MustCompile("^/projects/([^/]+)/files/(.+)$"),
+			ReverseTarget:       "/v1/$1/$2",
+		},
+	}
+	tests := []struct {
+		name  string
+		rules []*config.KeyRewriteConfig
+		key   string
+		want  string
+	}{
+		{
+			name: "should remove prefix without rules",
+			key:  "prefix/projects/project1/files/file",
+			want: "/mount/projects/project1/files/file",
+		},
+		{
+			name:  "should apply first reverse rule",
+			rules: rules,
+			key:   "prefix/projects/project1/files/dir/",
+			want:  "/mount/v1/project1/dir/",
+		},
+		{
+			name:  "should keep key path when no reverse rule is matching",
+			rules: rules,
+			key:   "prefix/other/file",
+			want:  "/mount/other/file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rctx := &requestContext{
+				mountPath: "/mount/",
+				targetCfg: &config.TargetConfig{
+					Bucket:         &config.BucketConfig{Prefix: "prefix/"},
+					KeyRewriteList: tt.rules,
+				},
+			}
+			if got := rctx.generateRequestPath(tt.key); got != tt.want {
+				t.Errorf("requestContext.generateRequestPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,41 +19,88 @@ func (r *respWriterTest) Write(in []byte) (int, error) { r.Resp = in; return len
 func (r *respWriterTest) WriteHeader(s int)            { r.Status = s }
 
 type s3clientTest struct {
-	ListErr      error
-	HeadErr      error
-	GetErr       error
-	PutErr       error
-	DeleteErr    error
-	ListResult   []*s3client.ListElementOutput
-	HeadResult   *s3client.HeadOutput
-	GetResult    *s3client.GetOutput
-	ListCalled   bool
-	HeadCalled   bool
-	GetCalled    bool
-	PutCalled    bool
-	DeleteCalled bool
-	ListInput    string
-	HeadInput    string
-	GetInput     string
-	PutInput     *s3client.PutInput
-	DeleteInput  string
+	ListErr            error
+	ListAllErr         error
+	HeadErr            error
+	GetErr             error
+	PutErr             error
+	DeleteErr          error
+	DeleteFolderErr    error
+	PresignGetErr      error
+	PresignPutErr      error
+	PresignPostErr     error
+	ListVersionsErr    error
+	ListResult         *s3client.ListOutput
+	ListAllResult      *s3client.ListAllObjectsOutput
+	HeadResult         *s3client.HeadOutput
+	GetResult          *s3client.GetOutput
+	GetResultsByKey    map[string]*s3client.GetOutput
+	GetHeadOnlyResult  *s3client.GetOutput
+	DeleteFolderResult *s3client.DeleteFolderOutput
+	PresignGetResult   string
+	PresignPutResult   *s3client.PresignPutOutput
+	PresignPostResult  *s3client.PresignPostOutput
+	ListVersionsResult []*s3client.ObjectVersionOutput
+	ListCalled         bool
+	ListAllCalled      bool
+	HeadCalled         bool
+	GetCalled          bool
+	PutCalled          bool
+	DeleteCalled       bool
+	DeleteFolderCalled bool
+	PresignGetCalled   bool
+	PresignPutCalled   bool
+	PresignPostCalled  bool
+	ListVersionsCalled bool
+	ListInput          *s3client.ListInput
+	ListAllInput       *s3client.ListAllObjectsInput
+	HeadInput          string
+	GetInput           *s3client.GetInput
+	GetKeys            []string
+	PutInput           *s3client.PutInput
+	DeleteInput        *s3client.DeleteInput
+	DeleteFolderInput  *s3client.DeleteFolderInput
+	PresignGetInput    *s3client.PresignGetInput
+	PresignPutInput    *s3client.PresignPutInput
+	PresignPostInput   *s3client.PresignPutInput
+	ListVersionsInput  string
 }
 
-func (s *s3clientTest) ListFilesAndDirectories(key string) ([]*s3client.ListElementOutput, error) {
-	s.ListInput = key
+func (s *s3clientTest) ListFilesAndDirectories(input *s3client.ListInput) (*s3client.ListOutput, error) {
+	s.ListInput = input
 	s.ListCalled = true
 	return s.ListResult, s.ListErr
 }
 
+func (s *s3clientTest) ListAllObjects(input *s3client.ListAllObjectsInput) (*s3client.ListAllObjectsOutput, error) {
+	s.ListAllInput = input
+	s.ListAllCalled = true
+	return s.ListAllResult, s.ListAllErr
+}
+
 func (s *s3clientTest) HeadObject(key string) (*s3client.HeadOutput, error) {
 	s.HeadInput = key
 	s.HeadCalled = true
 	return s.HeadResult, s.HeadErr
 }
 
-func (s *s3clientTest) GetObject(key string) (*s3client.GetOutput, error) {
-	s.GetInput = key
+func (s *s3clientTest) GetObject(input *s3client.GetInput) (*s3client.GetOutput, error) {
+	s.GetInput = input
 	s.GetCalled = true
+	s.GetKeys = append(s.GetKeys, input.Key)
+	// Check if a result is declared for head only requests
+	if input.HeadOnly && s.GetHeadOnlyResult != nil {
+		return s.GetHeadOnlyResult, nil
+	}
+	// Check if results are declared by key
+	if s.GetResultsByKey != nil {
+		res, ok := s.GetResultsByKey[input.Key]
+		if !ok {
+			return nil, s3client.ErrNotFound
+		}
+
+		return res, nil
+	}
 	return s.GetResult, s.GetErr
 }
 
@@ -63,8 +110,38 @@ func (s *s3clientTest) PutObject(input *s3client.PutInput) error {
 	return s.PutErr
 }
 
-func (s *s3clientTest) DeleteObject(key string) error {
-	s.DeleteInput = key
+func (s *s3clientTest) DeleteObject(input *s3client.DeleteInput) error {
+	s.DeleteInput = input
 	s.DeleteCalled = true
 	return s.DeleteErr
 }
+
+func (s *s3clientTest) DeleteFolder(input *s3client.DeleteFolderInput) (*s3client.DeleteFolderOutput, error) {
+	s.DeleteFolderInput = input
+	s.DeleteFolderCalled = true
+	return s.DeleteFolderResult, s.DeleteFolderErr
+}
+
+func (s *s3clientTest) PresignGetObject(input *s3client.PresignGetInput) (string, error) {
+	s.PresignGetInput = input
+	s.PresignGetCalled = true
+	return s.PresignGetResult, s.PresignGetErr
+}
+
+func (s *s3clientTest) PresignPutObject(input *s3client.PresignPutInput) (*s3client.PresignPutOutput, error) {
+	s.PresignPutInput = input
+	s.PresignPutCalled = true
+	return s.PresignPutResult, s.PresignPutErr
+}
+
+func (s *s3clientTest) PresignPostObject(input *s3client.PresignPutInput) (*s3client.PresignPostOutput, error) {
+	s.PresignPostInput = input
+	s.PresignPostCalled = true
+	return s.PresignPostResult, s.PresignPostErr
+}
+
+func (s *s3clientTest) ListObjectVersions(key string) ([]*s3client.ObjectVersionOutput, error) {
+	s.ListVersionsInput = key
+	s.ListVersionsCalled = true
+	return s.ListVersionsResult, s.ListVersionsErr
+}
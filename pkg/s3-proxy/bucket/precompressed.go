@@ -0,0 +1,96 @@
+package bucket
+
+import (
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
+)
+
+// precompressedExtensions Key extensions of precompressed object variants by encoding
+var precompressedExtensions = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// getObject will get object from s3 or its precompressed variant matching accepted encodings if enabled
+func (rctx *requestContext) getObject(input *s3client.GetInput, acceptEncoding string) (*s3client.GetOutput, error) {
+	precompressedCfg := rctx.targetCfg.GetPrecompressed()
+	// Versions are only available on original objects
+	if precompressedCfg == nil || input.VersionID != "" {
+		return rctx.s3Context.GetObject(input)
+	}
+	// Response depends on accepted encodings
+	rctx.httpRW.Header().Set("Vary", "Accept-Encoding")
+	// Search first precompressed variant accepted by client
+	for _, encoding := range precompressedCfg.GetEncodings() {
+		if !isEncodingAccepted(acceptEncoding, encoding) {
+			continue
+		}
+		// Get precompressed variant
+		variantInput := *input
+		variantInput.Key = input.Key + precompressedExtensions[encoding]
+
+		objOutput, err := rctx.s3Context.GetObject(&variantInput)
+		// Check if variant exists
+		if err == s3client.ErrNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		// Answer with original content type and variant encoding
+		// Content type is guessed from original key to not request original object
+		objOutput.ContentEncoding = encoding
+		objOutput.ContentType = getContentTypeFromKey(input.Key)
+
+		return objOutput, nil
+	}
+	// Default to original object
+	return rctx.s3Context.GetObject(input)
+}
+
+// getContentTypeFromKey will get content type from key extension
+func getContentTypeFromKey(key string) string {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	// Check if content type is known
+	if contentType == "" {
+		return "application/octet-stream"
+	}
+
+	return contentType
+}
+
+// isEncodingAccepted will check if encoding is accepted in an Accept-Encoding header value
+// Encodings with a zero quality value are refused
+func isEncodingAccepted(acceptEncoding, encoding string) bool {
+	wildcardAccepted := false
+	// Loop over header items
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(item, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		// Parse quality value
+		accepted := true
+
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				accepted = err == nil && q > 0
+			}
+		}
+		// Explicit encoding has priority over wildcard
+		if name == encoding {
+			return accepted
+		}
+
+		if name == "*" {
+			wildcardAccepted = accepted
+		}
+	}
+
+	return wildcardAccepted
+}
@@ -0,0 +1,189 @@
+// +build unit
+
+package bucket
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
+)
+
+func Test_isEncodingAccepted(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		encoding       string
+		want           bool
+	}{
+		{
+			name:           "should refuse with empty header",
+			acceptEncoding: "",
+			encoding:       "gzip",
+			want:           false,
+		},
+		{
+			name:           "should accept listed encoding",
+			acceptEncoding: "gzip, deflate, br",
+			encoding:       "br",
+			want:           true,
+		},
+		{
+			name:           "should refuse missing encoding",
+			acceptEncoding: "gzip, deflate",
+			encoding:       "br",
+			want:           false,
+		},
+		{
+			name:           "should accept encoding with quality value",
+			acceptEncoding: "br;q=0.8, gzip;q=1.0",
+			encoding:       "br",
+			want:           true,
+		},
+		{
+			name:           "should refuse encoding with zero quality value",
+			acceptEncoding: "gzip, br;q=0",
+			encoding:       "br",
+			want:           false,
+		},
+		{
+			name:           "should accept encoding with wildcard",
+			acceptEncoding: "*",
+			encoding:       "gzip",
+			want:           true,
+		},
+		{
+			name:           "should refuse explicitly refused encoding with wildcard",
+			acceptEncoding: "*, gzip;q=0",
+			encoding:       "gzip",
+			want:           false,
+		},
+		{
+			name:           "should be case insensitive",
+			acceptEncoding: "GZIP",
+			encoding:       "gzip",
+			want:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isEncodingAccepted(tt.acceptEncoding, tt.encoding); got != tt.want {
+				t.Errorf("isEncodingAccepted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_requestContext_getObject(t *testing.T) {
+	newObject := func(content, contentType string) *s3client.GetOutput {
+		body := ioutil.NopCloser(strings.NewReader(content))
+		return &s3client.GetOutput{Body: &body, ContentType: contentType}
+	}
+	tests := []struct {
+		name                    string
+		precompressedCfg        *config.PrecompressedConfig
+		acceptEncoding          string
+		versionID               string
+		expectedBody            string
+		expectedContentEncoding string
+		expectedContentType     string
+		expectedVary            string
+		expectedGetKeys         []string
+	}{
+		{
+			name:                "should get original object when disabled",
+			acceptEncoding:      "gzip, br",
+			expectedBody:        "css",
+			expectedContentType: "text/css",
+			expectedGetKeys:     []string{"app.css"},
+		},
+		{
+			name:                    "should get first precompressed variant in preference order",
+			precompressedCfg:        &config.PrecompressedConfig{Enabled: true},
+			acceptEncoding:          "gzip, br",
+			expectedBody:            "br",
+			expectedContentEncoding: "br",
+			expectedContentType:     "text/css; charset=utf-8",
+			expectedVary:            "Accept-Encoding",
+			expectedGetKeys:         []string{"app.css.br"},
+		},
+		{
+			name:                    "should get precompressed variant with configured encodings",
+			precompressedCfg:        &config.PrecompressedConfig{Enabled: true, Encodings: []string{"gzip"}},
+			acceptEncoding:          "gzip, br",
+			expectedBody:            "gz",
+			expectedContentEncoding: "gzip",
+			expectedContentType:     "text/css; charset=utf-8",
+			expectedVary:            "Accept-Encoding",
+			expectedGetKeys:         []string{"app.css.gz"},
+		},
+		{
+			name:                "should get original object when no encoding is accepted",
+			precompressedCfg:    &config.PrecompressedConfig{Enabled: true},
+			acceptEncoding:      "deflate",
+			expectedBody:        "css",
+			expectedContentType: "text/css",
+			expectedVary:        "Accept-Encoding",
+			expectedGetKeys:     []string{"app.css"},
+		},
+		{
+			name:                "should get original object when a version is asked",
+			precompressedCfg:    &config.PrecompressedConfig{Enabled: true},
+			acceptEncoding:      "gzip",
+			versionID:           "version1",
+			expectedBody:        "css",
+			expectedContentType: "text/css",
+			expectedGetKeys:     []string{"app.css"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3ctx := &s3clientTest{GetResultsByKey: map[string]*s3client.GetOutput{
+				"app.css":    newObject("css", "text/css"),
+				"app.css.gz": newObject("gz", "application/gzip"),
+				"app.css.br": newObject("br", "binary/octet-stream"),
+			}}
+			recorder := httptest.NewRecorder()
+			rctx := &requestContext{
+				s3Context: s3ctx,
+				logger:    log.NewLogger(),
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{},
+					Actions: &config.ActionsConfig{
+						GET: &config.GetActionConfig{
+							Enabled: true,
+							Config:  &config.GetActionConfigConfig{Precompressed: tt.precompressedCfg},
+						},
+					},
+				},
+				httpRW: recorder,
+			}
+			got, err := rctx.getObject(&s3client.GetInput{Key: "app.css", VersionID: tt.versionID}, tt.acceptEncoding)
+			if err != nil {
+				t.Errorf("requestContext.getObject() error = %v", err)
+				return
+			}
+			body, _ := ioutil.ReadAll(*got.Body)
+			if string(body) != tt.expectedBody {
+				t.Errorf("requestContext.getObject() body = %s, want %s", string(body), tt.expectedBody)
+			}
+			if got.ContentEncoding != tt.expectedContentEncoding {
+				t.Errorf("requestContext.getObject() ContentEncoding = %s, want %s", got.ContentEncoding, tt.expectedContentEncoding)
+			}
+			if got.ContentType != tt.expectedContentType {
+				t.Errorf("requestContext.getObject() ContentType = %s, want %s", got.ContentType, tt.expectedContentType)
+			}
+			if vary := recorder.Header().Get("Vary"); vary != tt.expectedVary {
+				t.Errorf("requestContext.getObject() => Vary = %s, want %s", vary, tt.expectedVary)
+			}
+			if !reflect.DeepEqual(s3ctx.GetKeys, tt.expectedGetKeys) {
+				t.Errorf("requestContext.getObject() => s3client.GetKeys = %+v, want %+v", s3ctx.GetKeys, tt.expectedGetKeys)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package bucket
+
+import (
+	"bytes"
+	"path"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/authx/models"
+)
+
+// PutTemplateData Data given to metadata and tags templates on upload
+type PutTemplateData struct {
+	// User is the uploader (empty values if request isn't authenticated)
+	User     *PutTemplateUser
+	ClientIP string
+	Time     time.Time
+	// Filename is the original file name
+	Filename string
+	// Key is the object key in bucket
+	Key string
+}
+
+// PutTemplateUser Uploader given to metadata and tags templates
+type PutTemplateUser struct {
+	Identifier string
+	Type       string
+	// Groups are only available for OIDC users
+	Groups []string
+}
+
+// newPutTemplateData will create metadata and tags template data from upload context
+func newPutTemplateData(uploadCtx *UploadContext, key, filename string) *PutTemplateData {
+	data := &PutTemplateData{
+		User:     &PutTemplateUser{Groups: []string{}},
+		Time:     time.Now(),
+		Filename: path.Base(filename),
+		Key:      key,
+	}
+	// Check if upload context exists
+	if uploadCtx == nil {
+		return data
+	}
+
+	data.ClientIP = uploadCtx.ClientIP
+	// Keep request time if set
+	if !uploadCtx.Time.IsZero() {
+		data.Time = uploadCtx.Time
+	}
+	// Check if user is authenticated
+	if uploadCtx.User == nil {
+		return data
+	}
+
+	data.User.Identifier = uploadCtx.User.GetIdentifier()
+	data.User.Type = uploadCtx.User.GetType()
+	// Add groups for OIDC users
+	if oidcUser, ok := uploadCtx.User.(*models.OIDCUser); ok && oidcUser.Groups != nil {
+		data.User.Groups = oidcUser.Groups
+	}
+
+	return data
+}
+
+// renderPutTemplates will execute all templates values with data
+func renderPutTemplates(values map[string]string, data *PutTemplateData) (map[string]string, error) {
+	// Check if values exist
+	if values == nil {
+		return nil, nil
+	}
+
+	res := make(map[string]string, len(values))
+	for k, v := range values {
+		// Create template executor
+		tmpl, err := template.New(k).Funcs(sprig.TxtFuncMap()).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		// Execute template
+		buf := &bytes.Buffer{}
+
+		err = tmpl.Execute(buf, data)
+		if err != nil {
+			return nil, err
+		}
+
+		res[k] = buf.String()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,141 @@
+// +build unit
+
+package bucket
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/authx/models"
+)
+
+func Test_newPutTemplateData(t *testing.T) {
+	now := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		uploadCtx *UploadContext
+		key       string
+		filename  string
+		want      *PutTemplateData
+	}{
+		{
+			name: "should return empty user when request isn't authenticated",
+			uploadCtx: &UploadContext{
+				ClientIP: "1.2.3.4",
+				Time:     now,
+			},
+			key:      "/folder/file.txt",
+			filename: "file.txt",
+			want: &PutTemplateData{
+				User:     &PutTemplateUser{Groups: []string{}},
+				ClientIP: "1.2.3.4",
+				Time:     now,
+				Filename: "file.txt",
+				Key:      "/folder/file.txt",
+			},
+		},
+		{
+			name: "should keep only file name from relative path",
+			uploadCtx: &UploadContext{
+				User: &models.BasicAuthUser{Username: "user1"},
+				Time: now,
+			},
+			key:      "/folder/sub/file.txt",
+			filename: "sub/file.txt",
+			want: &PutTemplateData{
+				User:     &PutTemplateUser{Identifier: "user1", Type: models.BasicAuthUserType, Groups: []string{}},
+				Time:     now,
+				Filename: "file.txt",
+				Key:      "/folder/sub/file.txt",
+			},
+		},
+		{
+			name: "should add groups for OIDC users",
+			uploadCtx: &UploadContext{
+				User:     &models.OIDCUser{Email: "user@example.com", Groups: []string{"group1", "group2"}},
+				ClientIP: "1.2.3.4",
+				Time:     now,
+			},
+			key:      "/file.txt",
+			filename: "file.txt",
+			want: &PutTemplateData{
+				User:     &PutTemplateUser{Identifier: "user@example.com", Type: models.OIDCUserType, Groups: []string{"group1", "group2"}},
+				ClientIP: "1.2.3.4",
+				Time:     now,
+				Filename: "file.txt",
+				Key:      "/file.txt",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newPutTemplateData(tt.uploadCtx, tt.key, tt.filename); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newPutTemplateData() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_renderPutTemplates(t *testing.T) {
+	data := &PutTemplateData{
+		User:     &PutTemplateUser{Identifier: "user@example.com", Type: models.OIDCUserType, Groups: []string{"group1", "group2"}},
+		ClientIP: "1.2.3.4",
+		Time:     time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC),
+		Filename: "file.txt",
+		Key:      "/file.txt",
+	}
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "should return nil without values",
+			values: nil,
+			want:   nil,
+		},
+		{
+			name: "should render static and templated values",
+			values: map[string]string{
+				"static":   "value",
+				"uploader": "{{ .User.Identifier }}",
+				"groups":   `{{ join "," .User.Groups }}`,
+				"ip":       "{{ .ClientIP }}",
+				"date":     "{{ .Time.Format \"2006-01-02\" }}",
+				"filename": "{{ .Filename | upper }}",
+			},
+			want: map[string]string{
+				"static":   "value",
+				"uploader": "user@example.com",
+				"groups":   "group1,group2",
+				"ip":       "1.2.3.4",
+				"date":     "2020-06-01",
+				"filename": "FILE.TXT",
+			},
+		},
+		{
+			name:    "should fail when template is invalid",
+			values:  map[string]string{"uploader": "{{ .User.Identifier"},
+			wantErr: true,
+		},
+		{
+			name:    "should fail when template execution fails",
+			values:  map[string]string{"uploader": "{{ .User.NotFound }}"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderPutTemplates(tt.values, data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("renderPutTemplates() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("renderPutTemplates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package bucket
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"html/template"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,6 +24,44 @@ import (
 // ErrRemovalFolder will be raised when end user is trying to delete a folder and not a file
 var ErrRemovalFolder = errors.New("can't remove folder")
 
+// ErrRemovalRootFolder will be raised when end user is trying to delete all objects of the target with a recursive deletion
+var ErrRemovalRootFolder = errors.New("can't remove root folder")
+
+// ErrPutKeyMissing will be raised when end user is trying to upload a raw body on a folder path
+var ErrPutKeyMissing = errors.New("request path must contain a file name for raw body uploads")
+
+// ErrPutInvalidFilename will be raised when end user is trying to upload a file with an empty name or a name going up in the tree
+var ErrPutInvalidFilename = errors.New("file name must not be empty or contain \"..\" elements")
+
+// ErrPutOverrideNotAllowed will be raised when end user is trying to override an object and override isn't allowed
+var ErrPutOverrideNotAllowed = errors.New("object already exists and override isn't allowed")
+
+// ErrPutTooLarge will be raised when end user is trying to upload a file greater than the maximum size allowed
+var ErrPutTooLarge = errors.New("file size is greater than the maximum size allowed")
+
+// ErrPutContentTypeNotAllowed will be raised when end user is trying to upload a file with a content type not allowed
+var ErrPutContentTypeNotAllowed = errors.New("file content type isn't allowed")
+
+// ErrPutFilenameNotAllowed will be raised when end user is trying to upload a file with a name not allowed
+var ErrPutFilenameNotAllowed = errors.New("file name isn't allowed")
+
+// ErrPresignedUploadDisabled will be raised when end user is asking a presigned upload URL and it isn't enabled
+var ErrPresignedUploadDisabled = errors.New("presigned upload isn't enabled")
+
+// ErrPresignedUploadMethod will be raised when end user is asking a presigned upload URL with an unsupported method
+var ErrPresignedUploadMethod = errors.New("presigned upload method must be put or post")
+
+// ErrPresignedUploadContentTypeMissing will be raised when end user is asking a presigned upload URL without content type
+// and upload policy checks content types
+var ErrPresignedUploadContentTypeMissing = errors.New("content type must be declared for presigned upload because of upload policy")
+
+// ErrPresignedUploadSizeMissing will be raised when end user is asking a presigned upload URL without size
+// and upload policy has a maximum size
+var ErrPresignedUploadSizeMissing = errors.New("size must be declared for presigned upload because of upload policy")
+
+// ErrPresignedUploadSizeInvalid will be raised when end user is asking a presigned upload URL with an invalid size
+var ErrPresignedUploadSizeInvalid = errors.New("presigned upload size must be a positive integer")
+
 // requestContext Bucket request context
 type requestContext struct {
 	s3Context      s3client.Client
@@ -33,23 +73,59 @@ type requestContext struct {
 	errorsHandlers *ErrorHandlers
 }
 
-// Entry Entry with path for internal use (template)
+// Entry Entry with path for internal use (template and JSON)
 type Entry struct {
-	Type         string
-	ETag         string
-	Name         string
-	LastModified time.Time
-	Size         int64
-	Key          string
-	Path         string
+	Type         string    `json:"type"`
+	ETag         string    `json:"etag"`
+	Name         string    `json:"name"`
+	LastModified time.Time `json:"lastModified"`
+	Size         int64     `json:"size"`
+	Key          string    `json:"-"`
+	Path         string    `json:"path"`
 }
 
-// bucketListingData Bucket listing data for templating
+// bucketListingData Bucket listing data for templating and JSON
 type bucketListingData struct {
-	Entries    []*Entry
-	BucketName string
-	Name       string
-	Path       string
+	Entries          []*Entry `json:"entries"`
+	BucketName       string   `json:"bucketName"`
+	Name             string   `json:"name"`
+	Path             string   `json:"path"`
+	NextPageLink     string   `json:"nextPageLink"`
+	PreviousPageLink string   `json:"previousPageLink"`
+}
+
+// deleteReport Deletion report
+type deleteReport struct {
+	DryRun  bool                 `json:"dryRun"`
+	Deleted []string             `json:"deleted"`
+	Failed  []*deleteReportError `json:"failed"`
+}
+
+// deleteReportError Deletion report error
+type deleteReportError struct {
+	Path    string `json:"path"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// putReport Upload report
+type putReport struct {
+	Uploaded []string          `json:"uploaded"`
+	Failed   []*putReportError `json:"failed"`
+}
+
+// putReportError Upload report error
+type putReportError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// presignedUpload Presigned upload answer
+type presignedUpload struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
 }
 
 // generateStartKey will generate start key used in all functions
@@ -57,6 +133,10 @@ func (rctx *requestContext) generateStartKey(requestPath string) string {
 	bucketRootPrefixKey := rctx.targetCfg.Bucket.GetRootPrefix()
 	// Key must begin by bucket prefix
 	key := bucketRootPrefixKey
+	// Check if rewrite rules are present
+	if len(rctx.targetCfg.KeyRewriteList) != 0 {
+		requestPath = rewriteRequestPath(rctx.targetCfg.KeyRewriteList, "/"+strings.TrimPrefix(requestPath, "/"))
+	}
 	// Trim first / if exists
 	key += strings.TrimPrefix(requestPath, "/")
 
@@ -147,6 +227,50 @@ func (rctx *requestContext) HandleBadRequest(err error, requestPath string) {
 	rctx.errorsHandlers.HandleBadRequestWithTemplate(rctx.logger, rctx.httpRW, rctx.tplConfig, content, rpath, err)
 }
 
+// handleRequestEntityTooLarge will answer with a request entity too large error using bad request template
+func (rctx *requestContext) handleRequestEntityTooLarge(err error, requestPath string) {
+	// Initialize content
+	content := ""
+	// Check if file is in bucket
+	if rctx.targetCfg != nil &&
+		rctx.targetCfg.Templates != nil &&
+		rctx.targetCfg.Templates.BadRequest != nil {
+		// Declare error
+		var err2 error
+		// Try to get file from bucket
+		content, err2 = rctx.loadTemplateContent(rctx.targetCfg.Templates.BadRequest)
+		if err2 != nil {
+			rctx.HandleInternalServerError(err2, requestPath)
+			return
+		}
+	}
+
+	rpath := path.Join(rctx.mountPath, requestPath)
+	rctx.errorsHandlers.HandleRequestEntityTooLargeWithTemplate(rctx.logger, rctx.httpRW, rctx.tplConfig, content, rpath, err)
+}
+
+// handleBadGateway will answer with a bad gateway error using internal server error template
+func (rctx *requestContext) handleBadGateway(err error, requestPath string) {
+	// Initialize content
+	content := ""
+	// Check if file is in bucket
+	if rctx.targetCfg != nil &&
+		rctx.targetCfg.Templates != nil &&
+		rctx.targetCfg.Templates.InternalServerError != nil {
+		// Put error err2 to avoid erase of err
+		var err2 error
+		content, err2 = rctx.loadTemplateContent(rctx.targetCfg.Templates.InternalServerError)
+		// Check if error exists
+		if err2 != nil {
+			rctx.HandleInternalServerError(err2, requestPath)
+			return
+		}
+	}
+
+	rpath := path.Join(rctx.mountPath, requestPath)
+	rctx.errorsHandlers.HandleBadGatewayWithTemplate(rctx.logger, rctx.httpRW, rctx.tplConfig, content, rpath, err)
+}
+
 func (rctx *requestContext) HandleUnauthorized(requestPath string) {
 	// Initialize content
 	content := ""
@@ -169,87 +293,245 @@ func (rctx *requestContext) HandleUnauthorized(requestPath string) {
 }
 
 // Get proxy GET requests
-func (rctx *requestContext) Get(requestPath string) {
+func (rctx *requestContext) Get(input *GetInput) {
+	requestPath := input.RequestPath
 	key := rctx.generateStartKey(requestPath)
+	// Check if folder must be downloaded as an archive
+	if input.Archive != "" {
+		rctx.manageGetArchive(key, input)
+		// Stop
+		return
+	}
+	// Check if file versions are asked
+	if input.Versions {
+		rctx.manageGetVersions(key, input)
+		// Stop
+		return
+	}
 	// Check that the path ends with a / for a directory listing or the main path special case (empty path)
-	if strings.HasSuffix(requestPath, "/") || requestPath == "" {
-		rctx.manageGetFolder(key, requestPath)
+	isFolder := strings.HasSuffix(requestPath, "/") || requestPath == ""
+	// Folders don't have versions
+	if isFolder && input.VersionID != "" {
+		rctx.logger.Error(ErrVersionNotFile)
+		rctx.HandleBadRequest(ErrVersionNotFile, requestPath)
+		// Stop
+		return
+	}
+	// Check if static website mode is enabled
+	if websiteCfg := rctx.targetCfg.GetWebsite(); websiteCfg != nil {
+		rctx.manageGetWebsite(key, input, websiteCfg)
+		// Stop
+		return
+	}
+
+	if isFolder {
+		rctx.manageGetFolder(key, input)
+		// Stop
+		return
+	}
+
+	// Check if download must be done with a redirect to a presigned URL
+	if redirectCfg := rctx.targetCfg.GetPresignedURLRedirect(); redirectCfg != nil {
+		rctx.redirectToPresignedURL(key, input.VersionID, requestPath, redirectCfg)
 		// Stop
 		return
 	}
 
 	// Get object case
-	err := rctx.streamFileForResponse(key)
+	err := rctx.streamFileForResponse(&s3client.GetInput{
+		Key:               key,
+		Range:             input.Range,
+		IfRange:           input.IfRange,
+		IfMatch:           input.IfMatch,
+		IfNoneMatch:       input.IfNoneMatch,
+		IfModifiedSince:   input.IfModifiedSince,
+		IfUnmodifiedSince: input.IfUnmodifiedSince,
+		VersionID:         input.VersionID,
+		HeadOnly:          input.HeadOnly,
+	}, input.AcceptEncoding)
 	if err != nil {
-		// Check if error is a not found error
-		if err == s3client.ErrNotFound {
-			// Not found
-			rctx.HandleNotFound(requestPath)
-			// Stop
-			return
+		// Give object size on range errors as asked by RFC 7233
+		if err == s3client.ErrRangeNotSatisfiable {
+			rctx.setUnsatisfiedContentRange(key, input.VersionID)
 		}
-		// Log error
+
+		rctx.manageStreamFileError(err, requestPath)
+		// Stop
+		return
+	}
+}
+
+// setUnsatisfiedContentRange will set object size in Content-Range header of a range not satisfiable answer
+// Size is got from a head request to support object versions.
+func (rctx *requestContext) setUnsatisfiedContentRange(key, versionID string) {
+	// Get object size with a head request on the same version
+	objOutput, err := rctx.s3Context.GetObject(&s3client.GetInput{
+		Key:       key,
+		VersionID: versionID,
+		HeadOnly:  true,
+	})
+	// Check if error exists
+	if err != nil {
 		rctx.logger.Error(err)
-		// Manage error response
-		rctx.HandleInternalServerError(err, requestPath)
 		// Stop
 		return
 	}
+
+	rctx.httpRW.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(objOutput.ContentLength, 10))
 }
 
-func (rctx *requestContext) manageGetFolder(key, requestPath string) {
-	// Directory listing case
-	s3Entries, err := rctx.s3Context.ListFilesAndDirectories(key)
+// redirectToPresignedURL will answer with a redirect to a presigned URL of the object
+func (rctx *requestContext) redirectToPresignedURL(key, versionID, requestPath string, redirectCfg *config.PresignedURLRedirectConfig) {
+	input := &s3client.PresignGetInput{
+		Key:       key,
+		VersionID: versionID,
+		Expiry:    redirectCfg.GetExpiry(),
+	}
+	// Add response headers overrides
+	if redirectCfg.ResponseHeaders != nil {
+		input.ResponseCacheControl = redirectCfg.ResponseHeaders.CacheControl
+		input.ResponseContentDisposition = redirectCfg.ResponseHeaders.ContentDisposition
+		input.ResponseContentEncoding = redirectCfg.ResponseHeaders.ContentEncoding
+		input.ResponseContentLanguage = redirectCfg.ResponseHeaders.ContentLanguage
+		input.ResponseContentType = redirectCfg.ResponseHeaders.ContentType
+	}
+	// Generate presigned URL
+	url, err := rctx.s3Context.PresignGetObject(input)
 	if err != nil {
 		rctx.logger.Error(err)
 		rctx.HandleInternalServerError(err, requestPath)
 		// Stop
 		return
 	}
+	// Redirect to presigned URL
+	rctx.httpRW.Header().Set("Location", url)
+	rctx.httpRW.WriteHeader(http.StatusFound)
+}
 
-	// Transform entries in entry with path objects
-	bucketRootPrefixKey := rctx.targetCfg.Bucket.GetRootPrefix()
-	entries := transformS3Entries(s3Entries, rctx, bucketRootPrefixKey)
+func (rctx *requestContext) manageStreamFileError(err error, requestPath string) {
+	// Check if error is a not found error
+	if err == s3client.ErrNotFound {
+		// Not found
+		rctx.HandleNotFound(requestPath)
+		// Stop
+		return
+	}
+	// Check if error is a not modified error
+	if err == s3client.ErrNotModified {
+		// Set status code
+		rctx.httpRW.WriteHeader(http.StatusNotModified)
+		// Stop
+		return
+	}
+	// Check if error is a precondition failed error
+	if err == s3client.ErrPreconditionFailed {
+		rctx.logger.Error(err)
+		// Set status code
+		rctx.httpRW.WriteHeader(http.StatusPreconditionFailed)
+		// Stop
+		return
+	}
+	// Check if error is a range not satisfiable error
+	if err == s3client.ErrRangeNotSatisfiable {
+		rctx.logger.Error(err)
+		// Set status code
+		rctx.httpRW.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		// Stop
+		return
+	}
+	// Log error
+	rctx.logger.Error(err)
+	// Manage error response
+	rctx.HandleInternalServerError(err, requestPath)
+}
 
+func (rctx *requestContext) manageGetFolder(key string, input *GetInput) {
+	requestPath := input.RequestPath
 	// Check if index document is activated
-	if rctx.targetCfg.IndexDocument != "" {
-		// Search if the file is present
-		indexDocumentEntry := funk.Find(entries, func(ent *Entry) bool {
-			return rctx.targetCfg.IndexDocument == ent.Name
-		})
-		// Check if index document entry exists
-		if indexDocumentEntry != nil {
-			// Get data
-			err = rctx.streamFileForResponse(indexDocumentEntry.(*Entry).Key)
-			// Check if error exists
-			if err != nil {
-				// Check if error is a not found error
-				if err == s3client.ErrNotFound {
-					// Not found
-					rctx.HandleNotFound(requestPath)
-					return
-				}
-				// Log error
-				rctx.logger.Error(err)
-				// Response with error
-				rctx.HandleInternalServerError(err, requestPath)
-				// Stop
-				return
-			}
+	// Index document is ignored when a JSON listing is asked
+	if rctx.targetCfg.IndexDocument != "" && !input.JSONListing {
+		// Try to get index document directly
+		err := rctx.streamFileForResponse(&s3client.GetInput{
+			Key:               key + rctx.targetCfg.IndexDocument,
+			IfMatch:           input.IfMatch,
+			IfNoneMatch:       input.IfNoneMatch,
+			IfModifiedSince:   input.IfModifiedSince,
+			IfUnmodifiedSince: input.IfUnmodifiedSince,
+			HeadOnly:          input.HeadOnly,
+		}, input.AcceptEncoding)
+		// Check if index document exists
+		if err == nil {
 			// Stop here because no error are present
 			return
 		}
+		// Check if error isn't a not found error
+		if err != s3client.ErrNotFound {
+			rctx.manageStreamFileError(err, requestPath)
+			// Stop
+			return
+		}
+	}
+
+	// Get maximum number of entries allowed for target
+	maxKeys := rctx.targetCfg.GetListMaxKeys()
+	// Check if asked number of entries is valid and lower than maximum
+	if input.MaxKeys > 0 && input.MaxKeys < maxKeys {
+		maxKeys = input.MaxKeys
 	}
 
+	// Directory listing case
+	s3Output, err := rctx.s3Context.ListFilesAndDirectories(&s3client.ListInput{
+		Key:               key,
+		ContinuationToken: input.ContinuationToken,
+		MaxKeys:           maxKeys,
+	})
+	if err != nil {
+		rctx.logger.Error(err)
+		rctx.HandleInternalServerError(err, requestPath)
+		// Stop
+		return
+	}
+
+	// Transform entries in entry with path objects
+	entries := transformS3Entries(s3Output.Entries, rctx)
+
+	// Create bucket list data
+	data := &bucketListingData{
+		Entries:    entries,
+		BucketName: rctx.targetCfg.Bucket.Name,
+		Name:       rctx.targetCfg.Name,
+		Path:       rctx.mountPath + requestPath,
+	}
+	// Generate pagination links
+	data.NextPageLink, data.PreviousPageLink = generatePageLinks(data.Path, input, s3Output.NextContinuationToken)
+
+	// Check if JSON listing is asked
+	if input.JSONListing {
+		rctx.writeJSON(data, http.StatusOK, requestPath)
+		// Stop
+		return
+	}
+
+	// Get per target template if declared
+	var targetTpl *config.TargetTemplateConfigItem
+	if rctx.targetCfg != nil && rctx.targetCfg.Templates != nil {
+		targetTpl = rctx.targetCfg.Templates.FolderList
+	}
+	// Answer with template
+	rctx.writeTemplate(targetTpl, rctx.tplConfig.FolderList, data, requestPath)
+}
+
+// writeTemplate will execute per target template if declared or global one with data and write it in response
+func (rctx *requestContext) writeTemplate(targetTpl *config.TargetTemplateConfigItem, tplPath string, data interface{}, requestPath string) {
 	var tmpl *template.Template
+	var err error
 	// Check if per target template is declared
-	if rctx.targetCfg != nil && rctx.targetCfg.Templates != nil &&
-		rctx.targetCfg.Templates.FolderList != nil {
+	if targetTpl != nil {
 		// Load template file name
-		tplFileName := filepath.Base(rctx.targetCfg.Templates.FolderList.Path)
+		tplFileName := filepath.Base(targetTpl.Path)
 		// Get template content
 		var content string
-		content, err = rctx.loadTemplateContent(rctx.targetCfg.Templates.FolderList)
+		content, err = rctx.loadTemplateContent(targetTpl)
 		// Check if errors exists in load file content
 		if err == nil {
 			// Create template executor
@@ -257,9 +539,9 @@ func (rctx *requestContext) manageGetFolder(key, requestPath string) {
 		}
 	} else {
 		// Load template file name
-		tplFileName := filepath.Base(rctx.tplConfig.FolderList)
+		tplFileName := filepath.Base(tplPath)
 		// Create template executor
-		tmpl, err = template.New(tplFileName).Funcs(sprig.HtmlFuncMap()).Funcs(s3ProxyFuncMap()).ParseFiles(rctx.tplConfig.FolderList)
+		tmpl, err = template.New(tplFileName).Funcs(sprig.HtmlFuncMap()).Funcs(s3ProxyFuncMap()).ParseFiles(tplPath)
 	}
 
 	// Check error
@@ -269,13 +551,6 @@ func (rctx *requestContext) manageGetFolder(key, requestPath string) {
 		// Stop
 		return
 	}
-	// Create bucket list data for templating
-	data := &bucketListingData{
-		Entries:    entries,
-		BucketName: rctx.targetCfg.Bucket.Name,
-		Name:       rctx.targetCfg.Name,
-		Path:       rctx.mountPath + requestPath,
-	}
 	// Generate template in buffer
 	buf := &bytes.Buffer{}
 	// Execute template
@@ -300,30 +575,207 @@ func (rctx *requestContext) manageGetFolder(key, requestPath string) {
 	}
 }
 
+func (rctx *requestContext) writeJSON(data interface{}, status int, requestPath string) {
+	// Generate JSON content
+	content, err := json.Marshal(data)
+	if err != nil {
+		rctx.logger.Error(err)
+		rctx.HandleInternalServerError(err, requestPath)
+		// Stop
+		return
+	}
+	// Set the header
+	rctx.httpRW.Header().Set("Content-Type", "application/json; charset=utf-8")
+	// Set status code
+	rctx.httpRW.WriteHeader(status)
+	// Write content to output
+	_, err = rctx.httpRW.Write(content)
+	if err != nil {
+		rctx.logger.Error(err)
+		// Stop
+		return
+	}
+}
+
 // Put proxy PUT requests
 func (rctx *requestContext) Put(inp *PutInput) {
-	key := rctx.generateStartKey(inp.RequestPath)
-	// Add / at the end if not present
-	if !strings.HasSuffix(key, "/") {
-		key += "/"
+	// Check if it is a single file upload
+	if len(inp.Files) == 1 {
+		rctx.putSingleFile(inp.RequestPath, inp.Files[0], inp.UploadContext)
+		// Stop
+		return
+	}
+	// Create report
+	report := &putReport{
+		Uploaded: make([]string, 0, len(inp.Files)),
+		Failed:   make([]*putReportError, 0),
+	}
+	// Initialize status of failed uploads
+	failedStatus := 0
+	// Loop over files
+	for _, file := range inp.Files {
+		key, err := rctx.putFile(inp.RequestPath, file, inp.UploadContext)
+		// Check if error exists
+		if err != nil {
+			rctx.logger.Errorf("Upload of file %s on path %s failed: %v", file.Filename, inp.RequestPath, err)
+			report.Failed = append(report.Failed, &putReportError{
+				Path:    rctx.generateRequestPath(key),
+				Message: err.Error(),
+			})
+			failedStatus = mergePutErrorStatus(failedStatus, getPutErrorStatus(err))
+			// Continue with next file
+			continue
+		}
+		report.Uploaded = append(report.Uploaded, rctx.generateRequestPath(key))
+	}
+	// Answer with an error status when some uploads have failed
+	status := http.StatusOK
+	if len(report.Failed) != 0 {
+		status = failedStatus
+		// Check if backend errors happened while some files are uploaded
+		if status >= http.StatusInternalServerError && len(report.Uploaded) != 0 {
+			status = http.StatusMultiStatus
+		}
+	}
+	// Write report
+	rctx.writeJSON(report, status, inp.RequestPath)
+}
+
+// putSingleFile will upload a single file and answer with a status depending on result
+func (rctx *requestContext) putSingleFile(requestPath string, file *PutFileInput, uploadCtx *UploadContext) {
+	_, err := rctx.putFile(requestPath, file, uploadCtx)
+	// Check if error exists
+	if err != nil {
+		rctx.logger.Error(err)
+		// Manage error
+		switch getPutErrorStatus(err) {
+		case http.StatusBadRequest:
+			rctx.HandleBadRequest(err, requestPath)
+		case http.StatusRequestEntityTooLarge:
+			rctx.handleRequestEntityTooLarge(err, requestPath)
+		case http.StatusForbidden:
+			rctx.HandleForbidden(requestPath)
+		case http.StatusBadGateway:
+			rctx.handleBadGateway(err, requestPath)
+		default:
+			rctx.HandleInternalServerError(err, requestPath)
+		}
+		// Stop
+		return
+	}
+	// Set status code
+	rctx.httpRW.WriteHeader(http.StatusNoContent)
+}
+
+// getPutErrorStatus will return the status code answered for an upload error
+// Client and policy errors have a 4xx status code, other ones are backend errors.
+// Mirror bucket failures have a bad gateway status code because object is written on target bucket.
+func getPutErrorStatus(err error) int {
+	// Check if upload failed only on mirror buckets
+	if _, ok := err.(*s3client.MirrorWriteError); ok {
+		return http.StatusBadGateway
+	}
+
+	switch err {
+	case ErrPutKeyMissing, ErrPutInvalidFilename, ErrPutContentTypeNotAllowed, ErrPutFilenameNotAllowed:
+		return http.StatusBadRequest
+	case ErrPutTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case ErrPutOverrideNotAllowed:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
 	}
-	// Add filename at the end of key
-	key += inp.Filename
+}
+
+// mergePutErrorStatus will merge status codes of failed uploads in a report
+// Same client errors keep their status code, different ones give a bad request and backend errors win.
+func mergePutErrorStatus(current, status int) int {
+	switch {
+	case current == 0 || current == status:
+		return status
+	case current >= http.StatusInternalServerError || status >= http.StatusInternalServerError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// putFile will upload a file in S3 and will return the generated key
+func (rctx *requestContext) putFile(requestPath string, file *PutFileInput, uploadCtx *UploadContext) (string, error) {
+	// Check if filename is given (multipart upload)
+	if file.Filename != "" {
+		// Add / at the end if not present
+		if !strings.HasSuffix(requestPath, "/") {
+			requestPath += "/"
+		}
+		// Filename can be a relative path with windows separators
+		filename := strings.ReplaceAll(file.Filename, "\\", "/")
+		// Check that filename isn't trying to go up in the tree
+		if funk.ContainsString(strings.Split(filename, "/"), "..") {
+			return rctx.generateStartKey(requestPath + filename), ErrPutInvalidFilename
+		}
+		// Clean filename
+		filename = strings.TrimPrefix(path.Clean("/"+filename), "/")
+		// Check that filename isn't empty
+		if filename == "" {
+			return rctx.generateStartKey(requestPath), ErrPutInvalidFilename
+		}
+		// Add filename at the end of request path
+		// Key is generated from full file path to apply rewrite rules on it
+		requestPath += filename
+	} else if strings.HasSuffix(requestPath, "/") || requestPath == "" {
+		// Request path is used as key so it must be a file
+		return rctx.generateStartKey(requestPath), ErrPutKeyMissing
+	}
+
+	key := rctx.generateStartKey(requestPath)
 	// Create input
 	input := &s3client.PutInput{
 		Key:         key,
-		Body:        inp.Body,
-		ContentType: inp.ContentType,
+		Body:        file.Body,
+		ContentType: file.ContentType,
 	}
+	// Initialize size limited body
+	var limitedBody *maxSizeReader
 
 	// Check if post actions configuration exists
 	if rctx.targetCfg.Actions.PUT != nil &&
 		rctx.targetCfg.Actions.PUT.Config != nil {
-		// Check if metadata is configured in target configuration
-		if rctx.targetCfg.Actions.PUT.Config.Metadata != nil {
-			input.Metadata = rctx.targetCfg.Actions.PUT.Config.Metadata
+		// Check upload policy
+		err := checkPutPolicy(rctx.targetCfg.Actions.PUT.Config, key, file)
+		if err != nil {
+			return key, err
+		}
+		// Limit body size when maximum size is set because size can be unknown or wrong
+		if rctx.targetCfg.Actions.PUT.Config.MaxSize > 0 {
+			limitedBody = &maxSizeReader{reader: file.Body, remaining: rctx.targetCfg.Actions.PUT.Config.MaxSize}
+			input.Body = limitedBody
+		}
+
+		// Generate metadata and tags from templates in target configuration
+		// Original file name is the request path one for raw body uploads
+		filename := file.Filename
+		if filename == "" {
+			filename = key
+		}
+
+		templateData := newPutTemplateData(uploadCtx, key, filename)
+
+		metadata, err := renderPutTemplates(rctx.targetCfg.Actions.PUT.Config.Metadata, templateData)
+		if err != nil {
+			return key, err
+		}
+
+		input.Metadata = metadata
+
+		tags, err := renderPutTemplates(rctx.targetCfg.Actions.PUT.Config.Tags, templateData)
+		if err != nil {
+			return key, err
 		}
 
+		input.Tags = tags
+
 		// Check if storage class is present in target configuration
 		if rctx.targetCfg.Actions.PUT.Config.StorageClass != "" {
 			input.StorageClass = rctx.targetCfg.Actions.PUT.Config.StorageClass
@@ -335,47 +787,231 @@ func (rctx *requestContext) Put(inp *PutInput) {
 			headOutput, err := rctx.s3Context.HeadObject(key)
 			// Check if error is not found if exists
 			if err != nil && err != s3client.ErrNotFound {
-				rctx.logger.Error(err)
-				rctx.HandleInternalServerError(err, inp.RequestPath)
-				// Stop
-				return
+				return key, err
 			}
 			// Check if file exists
 			if headOutput != nil {
 				rctx.logger.Errorf("File detected on path %s for PUT request and override isn't allowed", key)
-				rctx.HandleForbidden(inp.RequestPath)
-				// Stop
-				return
+
+				return key, ErrPutOverrideNotAllowed
 			}
 		}
 	}
 	// Put file
 	err := rctx.s3Context.PutObject(input)
+	// Check if upload failed because body is too large
+	if err != nil && limitedBody != nil && limitedBody.exceeded {
+		return key, ErrPutTooLarge
+	}
+
+	return key, err
+}
+
+// PresignUpload will answer with a presigned upload URL for the request path
+func (rctx *requestContext) PresignUpload(input *PresignUploadInput) {
+	requestPath := input.RequestPath
+	// Get presigned upload configuration
+	uploadCfg := rctx.targetCfg.GetPresignedUpload()
+	if uploadCfg == nil {
+		rctx.logger.Error(ErrPresignedUploadDisabled)
+		rctx.HandleBadRequest(ErrPresignedUploadDisabled, requestPath)
+		// Stop
+		return
+	}
+	// Check method
+	method := strings.ToUpper(input.Method)
+	if method != http.MethodPut && method != http.MethodPost {
+		rctx.logger.Error(ErrPresignedUploadMethod)
+		rctx.HandleBadRequest(ErrPresignedUploadMethod, requestPath)
+		// Stop
+		return
+	}
+	// Request path is used as key so it must be a file
+	if strings.HasSuffix(requestPath, "/") || requestPath == "" {
+		rctx.logger.Error(ErrPutKeyMissing)
+		rctx.HandleBadRequest(ErrPutKeyMissing, requestPath)
+		// Stop
+		return
+	}
+
+	key := rctx.generateStartKey(requestPath)
+	putCfg := rctx.targetCfg.Actions.PUT.Config
+	// File isn't sent to the backend so content type and size must be declared when policy checks them
+	// They are signed to be enforced by S3
+	if input.ContentType == "" && (len(putCfg.AllowedContentTypes) != 0 || len(putCfg.DeniedContentTypes) != 0) {
+		rctx.logger.Error(ErrPresignedUploadContentTypeMissing)
+		rctx.HandleBadRequest(ErrPresignedUploadContentTypeMissing, requestPath)
+		// Stop
+		return
+	}
+
+	if input.Size < 0 && putCfg.MaxSize > 0 {
+		rctx.logger.Error(ErrPresignedUploadSizeMissing)
+		rctx.HandleBadRequest(ErrPresignedUploadSizeMissing, requestPath)
+		// Stop
+		return
+	}
+	// Check upload policy with declared content type and size
+	err := checkPutPolicy(putCfg, key, &PutFileInput{ContentType: input.ContentType, Size: input.Size})
 	if err != nil {
 		rctx.logger.Error(err)
-		rctx.HandleInternalServerError(err, inp.RequestPath)
+		// Manage error
+		switch getPutErrorStatus(err) {
+		case http.StatusBadRequest:
+			rctx.HandleBadRequest(err, requestPath)
+		case http.StatusRequestEntityTooLarge:
+			rctx.handleRequestEntityTooLarge(err, requestPath)
+		default:
+			rctx.HandleInternalServerError(err, requestPath)
+		}
 		// Stop
 		return
 	}
-	// Set status code
-	rctx.httpRW.WriteHeader(http.StatusNoContent)
+	// Check if allow override is enabled
+	if !putCfg.AllowOverride {
+		// Need to check if file already exists
+		headOutput, err := rctx.s3Context.HeadObject(key)
+		// Check if error is not found if exists
+		if err != nil && err != s3client.ErrNotFound {
+			rctx.logger.Error(err)
+			rctx.HandleInternalServerError(err, requestPath)
+			// Stop
+			return
+		}
+		// Check if file exists
+		if headOutput != nil {
+			rctx.logger.Errorf("File detected on path %s for presigned upload request and override isn't allowed", key)
+			rctx.HandleForbidden(requestPath)
+			// Stop
+			return
+		}
+	}
+	// Generate metadata and tags from templates
+	templateData := newPutTemplateData(input.UploadContext, key, key)
+
+	metadata, err := renderPutTemplates(putCfg.Metadata, templateData)
+	if err != nil {
+		rctx.logger.Error(err)
+		rctx.HandleInternalServerError(err, requestPath)
+		// Stop
+		return
+	}
+
+	tags, err := renderPutTemplates(putCfg.Tags, templateData)
+	if err != nil {
+		rctx.logger.Error(err)
+		rctx.HandleInternalServerError(err, requestPath)
+		// Stop
+		return
+	}
+	// Create input
+	presignInput := &s3client.PresignPutInput{
+		Key:          key,
+		Expiry:       uploadCfg.GetExpiry(),
+		Metadata:     metadata,
+		Tags:         tags,
+		StorageClass: putCfg.StorageClass,
+		ContentType:  input.ContentType,
+		Size:         input.Size,
+		MaxSize:      putCfg.MaxSize,
+	}
+	// Create answer
+	answer := &presignedUpload{Method: method}
+	// Presign request depending on method
+	if method == http.MethodPut {
+		output, err := rctx.s3Context.PresignPutObject(presignInput)
+		if err != nil {
+			rctx.logger.Error(err)
+			rctx.HandleInternalServerError(err, requestPath)
+			// Stop
+			return
+		}
+
+		answer.URL = output.URL
+		answer.Headers = output.Headers
+	} else {
+		output, err := rctx.s3Context.PresignPostObject(presignInput)
+		if err != nil {
+			rctx.logger.Error(err)
+			rctx.HandleInternalServerError(err, requestPath)
+			// Stop
+			return
+		}
+
+		answer.URL = output.URL
+		answer.Fields = output.Fields
+	}
+	// Write answer
+	rctx.writeJSON(answer, http.StatusOK, requestPath)
 }
 
 // Delete will delete object in S3
-func (rctx *requestContext) Delete(requestPath string) {
+func (rctx *requestContext) Delete(input *DeleteInput) {
+	requestPath := input.RequestPath
 	key := rctx.generateStartKey(requestPath)
 	// Check that the path ends with a / for a directory or the main path special case (empty path)
 	if strings.HasSuffix(requestPath, "/") || requestPath == "" {
-		rctx.logger.Error(ErrRemovalFolder)
-		rctx.HandleInternalServerError(ErrRemovalFolder, requestPath)
+		// Folders don't have versions
+		if input.VersionID != "" {
+			rctx.logger.Error(ErrVersionNotFile)
+			rctx.HandleBadRequest(ErrVersionNotFile, requestPath)
+			// Stop
+			return
+		}
+		// Check if recursive deletion is enabled
+		if rctx.targetCfg.Actions == nil || rctx.targetCfg.Actions.DELETE == nil ||
+			rctx.targetCfg.Actions.DELETE.Config == nil || !rctx.targetCfg.Actions.DELETE.Config.Recursive {
+			rctx.logger.Error(ErrRemovalFolder)
+			rctx.HandleInternalServerError(ErrRemovalFolder, requestPath)
+			// Stop
+			return
+		}
+		// Refuse to delete the whole bucket or bucket prefix
+		if requestPath == "" || requestPath == "/" {
+			rctx.logger.Error(ErrRemovalRootFolder)
+			rctx.HandleForbidden(requestPath)
+			// Stop
+			return
+		}
+		// Delete folder
+		rctx.manageDeleteFolder(key, input)
+		// Stop
+		return
+	}
+	// Check if it is a dry run
+	if input.DryRun {
+		// Check that file or file version exists
+		var err error
+		if input.VersionID != "" {
+			err = rctx.checkVersionExists(key, input.VersionID)
+		} else {
+			_, err = rctx.s3Context.HeadObject(key)
+		}
+		if err != nil {
+			rctx.manageStreamFileError(err, requestPath)
+			// Stop
+			return
+		}
+		// Answer with report
+		rctx.writeDeleteReport(&s3client.DeleteFolderOutput{DeletedKeys: []string{key}}, true, requestPath)
 		// Stop
 		return
 	}
 	// Delete object in S3
-	err := rctx.s3Context.DeleteObject(key)
+	err := rctx.s3Context.DeleteObject(&s3client.DeleteInput{
+		Key:       key,
+		VersionID: input.VersionID,
+	})
 	// Check if error exists
 	if err != nil {
 		rctx.logger.Error(err)
+		// Check if deletion failed only on mirror buckets
+		if _, ok := err.(*s3client.MirrorWriteError); ok {
+			rctx.handleBadGateway(err, requestPath)
+			// Stop
+			return
+		}
+
 		rctx.HandleInternalServerError(err, requestPath)
 		// Stop
 		return
@@ -384,7 +1020,55 @@ func (rctx *requestContext) Delete(requestPath string) {
 	rctx.httpRW.WriteHeader(http.StatusNoContent)
 }
 
-func transformS3Entries(s3Entries []*s3client.ListElementOutput, rctx *requestContext, bucketRootPrefixKey string) []*Entry {
+func (rctx *requestContext) manageDeleteFolder(key string, input *DeleteInput) {
+	// Delete all objects under folder
+	output, err := rctx.s3Context.DeleteFolder(&s3client.DeleteFolderInput{
+		Prefix: key,
+		DryRun: input.DryRun,
+	})
+	// Check if error exists
+	if err != nil {
+		rctx.logger.Error(err)
+		rctx.HandleInternalServerError(err, input.RequestPath)
+		// Stop
+		return
+	}
+	// Log failed keys
+	for _, item := range output.Errors {
+		rctx.logger.Errorf("cannot delete key %s: %s (%s)", item.Key, item.Message, item.Code)
+	}
+	// Answer with report
+	rctx.writeDeleteReport(output, input.DryRun, input.RequestPath)
+}
+
+func (rctx *requestContext) writeDeleteReport(output *s3client.DeleteFolderOutput, dryRun bool, requestPath string) {
+	// Create report with paths
+	report := &deleteReport{
+		DryRun:  dryRun,
+		Deleted: make([]string, 0, len(output.DeletedKeys)),
+		Failed:  make([]*deleteReportError, 0, len(output.Errors)),
+	}
+	for _, k := range output.DeletedKeys {
+		report.Deleted = append(report.Deleted, rctx.generateRequestPath(k))
+	}
+
+	for _, item := range output.Errors {
+		report.Failed = append(report.Failed, &deleteReportError{
+			Path:    rctx.generateRequestPath(item.Key),
+			Code:    item.Code,
+			Message: item.Message,
+		})
+	}
+	// Answer with an error status when some deletions have failed
+	status := http.StatusOK
+	if len(report.Failed) != 0 {
+		status = http.StatusInternalServerError
+	}
+	// Write report
+	rctx.writeJSON(report, status, requestPath)
+}
+
+func transformS3Entries(s3Entries []*s3client.ListElementOutput, rctx *requestContext) []*Entry {
 	// Prepare result
 	entries := make([]*Entry, 0)
 	// Loop over s3 entries
@@ -396,7 +1080,7 @@ func transformS3Entries(s3Entries []*s3client.ListElementOutput, rctx *requestCo
 			LastModified: item.LastModified,
 			Size:         item.Size,
 			Key:          item.Key,
-			Path:         rctx.mountPath + strings.TrimPrefix(item.Key, bucketRootPrefixKey),
+			Path:         rctx.generateRequestPath(item.Key),
 		})
 	}
 	// Return result
@@ -422,10 +1106,12 @@ func (rctx *requestContext) loadTemplateContent(item *config.TargetTemplateConfi
 
 func (rctx *requestContext) getFileContent(path string) (string, error) {
 	// Get object from s3
-	objOutput, err := rctx.s3Context.GetObject(path)
+	objOutput, err := rctx.s3Context.GetObject(&s3client.GetInput{Key: path})
 	if err != nil {
 		return "", err
 	}
+	// Close body at the end
+	defer (*objOutput.Body).Close()
 
 	// Read all body
 	bb, err := ioutil.ReadAll(*objOutput.Body)
@@ -437,12 +1123,14 @@ func (rctx *requestContext) getFileContent(path string) (string, error) {
 	return string(bb), nil
 }
 
-func (rctx *requestContext) streamFileForResponse(key string) error {
+func (rctx *requestContext) streamFileForResponse(input *s3client.GetInput, acceptEncoding string) error {
 	// Get object from s3
-	objOutput, err := rctx.s3Context.GetObject(key)
+	objOutput, err := rctx.getObject(input, acceptEncoding)
 	if err != nil {
 		return err
 	}
+	// Close body at the end
+	defer (*objOutput.Body).Close()
 	// Set headers from object
 	setHeadersFromObjectOutput(rctx.httpRW, objOutput)
 	// Copy data stream to output stream
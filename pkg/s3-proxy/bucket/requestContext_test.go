@@ -6,13 +6,17 @@ import (
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gobwas/glob"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/authx/models"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
@@ -21,9 +25,8 @@ import (
 func Test_transformS3Entries(t *testing.T) {
 	now := time.Now()
 	type args struct {
-		s3Entries           []*s3client.ListElementOutput
-		rctx                *requestContext
-		bucketRootPrefixKey string
+		s3Entries []*s3client.ListElementOutput
+		rctx      *requestContext
 	}
 	tests := []struct {
 		name string
@@ -33,9 +36,10 @@ func Test_transformS3Entries(t *testing.T) {
 		{
 			name: "Empty list",
 			args: args{
-				s3Entries:           []*s3client.ListElementOutput{},
-				rctx:                &requestContext{},
-				bucketRootPrefixKey: "prefix/",
+				s3Entries: []*s3client.ListElementOutput{},
+				rctx: &requestContext{
+					targetCfg: &config.TargetConfig{Bucket: &config.BucketConfig{Prefix: "prefix/"}},
+				},
 			},
 			want: []*Entry{},
 		},
@@ -54,8 +58,8 @@ func Test_transformS3Entries(t *testing.T) {
 				},
 				rctx: &requestContext{
 					mountPath: "mount/",
+					targetCfg: &config.TargetConfig{Bucket: &config.BucketConfig{Prefix: "prefix/"}},
 				},
-				bucketRootPrefixKey: "prefix/",
 			},
 			want: []*Entry{
 				{
@@ -69,10 +73,55 @@ func Test_transformS3Entries(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "List with reverse rewrite rules",
+			args: args{
+				s3Entries: []*s3client.ListElementOutput{
+					{
+						Type: "FILE",
+						Name: "file",
+						Key:  "prefix/projects/project1/files/file",
+					},
+					{
+						Type: "FILE",
+						Name: "other",
+						Key:  "prefix/other",
+					},
+				},
+				rctx: &requestContext{
+					mountPath: "mount/",
+					targetCfg: &config.TargetConfig{
+						Bucket: &config.BucketConfig{Prefix: "prefix/"},
+						KeyRewriteList: []*config.KeyRewriteConfig{
+							{
+								SourceRegexp:        regexp.MustCompile("^/v1/([^/]+)/(.*)$"),
+								Target:              "/projects/$1/files/$2",
+								ReverseSourceRegexp: regexp.MustCompile("^/projects/([^/]+)/files/(.*)$"),
+								ReverseTarget:       "/v1/$1/$2",
+							},
+						},
+					},
+				},
+			},
+			want: []*Entry{
+				{
+					Type: "FILE",
+					Name: "file",
+					Key:  "prefix/projects/project1/files/file",
+					Path: "mount/v1/project1/file",
+				},
+				{
+					Type: "FILE",
+					Name: "other",
+					Key:  "prefix/other",
+					Path: "mount/other",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := transformS3Entries(tt.args.s3Entries, tt.args.rctx, tt.args.bucketRootPrefixKey); !reflect.DeepEqual(got, tt.want) {
+			if got := transformS3Entries(tt.args.s3Entries, tt.args.rctx); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("transformS3Entries() = %+v, want %+v", got, tt.want)
 			}
 		})
@@ -100,8 +149,10 @@ func Test_requestContext_Delete(t *testing.T) {
 		httpRW        http.ResponseWriter
 		errorHandlers *ErrorHandlers
 	}
+	hJSON := http.Header{}
+	hJSON.Set("Content-Type", "application/json; charset=utf-8")
 	type args struct {
-		requestPath string
+		input *DeleteInput
 	}
 	tests := []struct {
 		name                                    string
@@ -112,7 +163,10 @@ func Test_requestContext_Delete(t *testing.T) {
 		expectedHandleForbiddenCalled           bool
 		expectedHTTPWriter                      *respWriterTest
 		expectedS3ClientDeleteCalled            bool
-		expectedS3ClientDeleteInput             string
+		expectedS3ClientDeleteInput             *s3client.DeleteInput
+		expectedS3ClientDeleteFolderCalled      bool
+		expectedS3ClientDeleteFolderInput       *s3client.DeleteFolderInput
+		expectedS3ClientHeadCalled              bool
 	}{
 		{
 			name: "Can't delete a directory with empty request path",
@@ -130,7 +184,7 @@ func Test_requestContext_Delete(t *testing.T) {
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args:                                    args{requestPath: ""},
+			args:                                    args{input: &DeleteInput{RequestPath: ""}},
 			expectedHTTPWriter:                      &respWriterTest{},
 			expectedHandleInternalServerErrorCalled: true,
 		},
@@ -150,18 +204,22 @@ func Test_requestContext_Delete(t *testing.T) {
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args:                                    args{requestPath: "/directory/"},
+			args:                                    args{input: &DeleteInput{RequestPath: "/directory/"}},
 			expectedHTTPWriter:                      &respWriterTest{},
 			expectedHandleInternalServerErrorCalled: true,
 		},
 		{
-			name: "Can't delete file because of error",
+			name: "Can't delete root directory recursively",
 			fields: fields{
-				s3Context: &s3clientTest{
-					DeleteErr: errors.New("test"),
-				},
+				s3Context: &s3clientTest{},
 				targetCfg: &config.TargetConfig{
 					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						DELETE: &config.DeleteActionConfig{
+							Enabled: true,
+							Config:  &config.DeleteActionConfigConfig{Recursive: true},
+						},
+					},
 				},
 				tplConfig: &config.TemplateConfig{},
 				mountPath: "/mount",
@@ -172,18 +230,22 @@ func Test_requestContext_Delete(t *testing.T) {
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args:                                    args{requestPath: "/file"},
-			expectedHTTPWriter:                      &respWriterTest{},
-			expectedHandleInternalServerErrorCalled: true,
-			expectedS3ClientDeleteCalled:            true,
-			expectedS3ClientDeleteInput:             "/file",
+			args:                          args{input: &DeleteInput{RequestPath: ""}},
+			expectedHTTPWriter:            &respWriterTest{},
+			expectedHandleForbiddenCalled: true,
 		},
 		{
-			name: "Delete file succeed",
+			name: "Can't delete root directory recursively with slash request path",
 			fields: fields{
 				s3Context: &s3clientTest{},
 				targetCfg: &config.TargetConfig{
 					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						DELETE: &config.DeleteActionConfig{
+							Enabled: true,
+							Config:  &config.DeleteActionConfigConfig{Recursive: true},
+						},
+					},
 				},
 				tplConfig: &config.TemplateConfig{},
 				mountPath: "/mount",
@@ -194,95 +256,24 @@ func Test_requestContext_Delete(t *testing.T) {
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args:                         args{requestPath: "/file"},
-			expectedHTTPWriter:           &respWriterTest{Status: http.StatusNoContent},
-			expectedS3ClientDeleteCalled: true,
-			expectedS3ClientDeleteInput:  "/file",
+			args:                          args{input: &DeleteInput{RequestPath: "/", DryRun: true}},
+			expectedHTTPWriter:            &respWriterTest{},
+			expectedHandleForbiddenCalled: true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			handleForbiddenCalled = false
-			handleInternalServerErrorCalled = false
-			handleNotFoundCalled = false
-			rctx := &requestContext{
-				s3Context:      tt.fields.s3Context,
-				logger:         log.NewLogger(),
-				targetCfg:      tt.fields.targetCfg,
-				tplConfig:      tt.fields.tplConfig,
-				mountPath:      tt.fields.mountPath,
-				httpRW:         tt.fields.httpRW,
-				errorsHandlers: tt.fields.errorHandlers,
-			}
-			rctx.Delete(tt.args.requestPath)
-			if handleNotFoundCalled != tt.expectedHandleNotFoundCalled {
-				t.Errorf("requestContext.Delete() => handleNotFoundCalled = %+v, want %+v", handleNotFoundCalled, tt.expectedHandleNotFoundCalled)
-			}
-			if handleInternalServerErrorCalled != tt.expectedHandleInternalServerErrorCalled {
-				t.Errorf("requestContext.Delete() => handleInternalServerErrorCalled = %+v, want %+v", handleInternalServerErrorCalled, tt.expectedHandleInternalServerErrorCalled)
-			}
-			if handleForbiddenCalled != tt.expectedHandleForbiddenCalled {
-				t.Errorf("requestContext.Delete() => handleForbiddenCalled = %+v, want %+v", handleForbiddenCalled, tt.expectedHandleForbiddenCalled)
-			}
-			if tt.expectedS3ClientDeleteCalled != tt.fields.s3Context.(*s3clientTest).DeleteCalled {
-				t.Errorf("requestContext.Delete() => s3client.DeleteCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).DeleteCalled, tt.expectedS3ClientDeleteCalled)
-			}
-			if tt.expectedS3ClientDeleteInput != tt.fields.s3Context.(*s3clientTest).DeleteInput {
-				t.Errorf("requestContext.Delete() => s3client.DeleteInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).DeleteInput, tt.expectedS3ClientDeleteInput)
-			}
-			if !reflect.DeepEqual(tt.expectedHTTPWriter, tt.fields.httpRW) {
-				t.Errorf("requestContext.Delete() => httpWriter = %+v, want %+v", tt.fields.httpRW, tt.expectedHTTPWriter)
-			}
-		})
-	}
-}
-
-func Test_requestContext_Put(t *testing.T) {
-	handleNotFoundCalled := false
-	handleInternalServerErrorCalled := false
-	handleForbiddenCalled := false
-	handleNotFoundWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
-		handleNotFoundCalled = true
-	}
-	handleInternalServerErrorWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
-		handleInternalServerErrorCalled = true
-	}
-	handleForbiddenWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
-		handleForbiddenCalled = true
-	}
-	type fields struct {
-		s3Context     s3client.Client
-		targetCfg     *config.TargetConfig
-		tplConfig     *config.TemplateConfig
-		mountPath     string
-		httpRW        http.ResponseWriter
-		errorHandlers *ErrorHandlers
-	}
-	type args struct {
-		inp *PutInput
-	}
-	tests := []struct {
-		name                                    string
-		fields                                  fields
-		args                                    args
-		expectedHandleNotFoundCalled            bool
-		expectedHandleInternalServerErrorCalled bool
-		expectedHandleForbiddenCalled           bool
-		expectedHTTPWriter                      *respWriterTest
-		expectedS3ClientPutCalled               bool
-		expectedS3ClientPutInput                *s3client.PutInput
-		expectedS3ClientHeadCalled              bool
-		expectedS3ClientHeadInput               string
-	}{
 		{
-			name: "should fail when put object failed and no put configuration exists",
+			name: "Can't delete a directory recursively because of error",
 			fields: fields{
 				s3Context: &s3clientTest{
-					PutErr: errors.New("test"),
+					DeleteFolderErr: errors.New("test"),
 				},
 				targetCfg: &config.TargetConfig{
-					Bucket:  &config.BucketConfig{Prefix: "/"},
-					Actions: &config.ActionsConfig{},
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						DELETE: &config.DeleteActionConfig{
+							Enabled: true,
+							Config:  &config.DeleteActionConfigConfig{Recursive: true},
+						},
+					},
 				},
 				tplConfig: &config.TemplateConfig{},
 				mountPath: "/mount",
@@ -293,210 +284,154 @@ func Test_requestContext_Put(t *testing.T) {
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args: args{
-				inp: &PutInput{
-					RequestPath: "/test",
-					Filename:    "file",
-					Body:        nil,
-					ContentType: "content-type",
-				},
-			},
-			expectedS3ClientPutCalled:               true,
+			args:                                    args{input: &DeleteInput{RequestPath: "/directory/"}},
 			expectedHTTPWriter:                      &respWriterTest{},
 			expectedHandleInternalServerErrorCalled: true,
-			expectedS3ClientPutInput: &s3client.PutInput{
-				Key:         "/test/file",
-				ContentType: "content-type",
-			},
+			expectedS3ClientDeleteFolderCalled:      true,
+			expectedS3ClientDeleteFolderInput:       &s3client.DeleteFolderInput{Prefix: "/directory/"},
 		},
 		{
-			name: "should fail when put object failed and put configuration exists with allow override",
+			name: "Delete directory recursively succeed",
 			fields: fields{
 				s3Context: &s3clientTest{
-					PutErr: errors.New("test"),
+					DeleteFolderResult: &s3client.DeleteFolderOutput{
+						DeletedKeys: []string{"/directory/file1", "/directory/sub/file2"},
+					},
 				},
 				targetCfg: &config.TargetConfig{
 					Bucket: &config.BucketConfig{Prefix: "/"},
 					Actions: &config.ActionsConfig{
-						PUT: &config.PutActionConfig{
-							Config: &config.PutActionConfigConfig{
-								Metadata: map[string]string{
-									"testkey": "testvalue",
-								},
-								StorageClass:  "storage-class",
-								AllowOverride: true,
-							},
+						DELETE: &config.DeleteActionConfig{
+							Enabled: true,
+							Config:  &config.DeleteActionConfigConfig{Recursive: true},
 						},
 					},
 				},
 				tplConfig: &config.TemplateConfig{},
 				mountPath: "/mount",
-				httpRW:    &respWriterTest{},
+				httpRW:    &respWriterTest{Headers: http.Header{}},
 				errorHandlers: &ErrorHandlers{
 					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
 					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args: args{
-				inp: &PutInput{
-					RequestPath: "/test",
-					Filename:    "file",
-					Body:        nil,
-					ContentType: "content-type",
-				},
-			},
-			expectedS3ClientPutCalled:               true,
-			expectedHTTPWriter:                      &respWriterTest{},
-			expectedHandleInternalServerErrorCalled: true,
-			expectedS3ClientPutInput: &s3client.PutInput{
-				Key:         "/test/file",
-				ContentType: "content-type",
-				Metadata: map[string]string{
-					"testkey": "testvalue",
-				},
-				StorageClass: "storage-class",
+			args: args{input: &DeleteInput{RequestPath: "/directory/"}},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hJSON,
+				Status:  http.StatusOK,
+				Resp:    []byte(`{"dryRun":false,"deleted":["/mountdirectory/file1","/mountdirectory/sub/file2"],"failed":[]}`),
 			},
+			expectedS3ClientDeleteFolderCalled: true,
+			expectedS3ClientDeleteFolderInput:  &s3client.DeleteFolderInput{Prefix: "/directory/"},
 		},
 		{
-			name: "should be ok with allow override",
+			name: "Delete directory recursively in dry run mode",
 			fields: fields{
-				s3Context: &s3clientTest{},
+				s3Context: &s3clientTest{
+					DeleteFolderResult: &s3client.DeleteFolderOutput{
+						DeletedKeys: []string{"/directory/file1"},
+					},
+				},
 				targetCfg: &config.TargetConfig{
 					Bucket: &config.BucketConfig{Prefix: "/"},
 					Actions: &config.ActionsConfig{
-						PUT: &config.PutActionConfig{
-							Config: &config.PutActionConfigConfig{
-								Metadata: map[string]string{
-									"testkey": "testvalue",
-								},
-								StorageClass:  "storage-class",
-								AllowOverride: true,
-							},
+						DELETE: &config.DeleteActionConfig{
+							Enabled: true,
+							Config:  &config.DeleteActionConfigConfig{Recursive: true},
 						},
 					},
 				},
 				tplConfig: &config.TemplateConfig{},
 				mountPath: "/mount",
-				httpRW:    &respWriterTest{},
+				httpRW:    &respWriterTest{Headers: http.Header{}},
 				errorHandlers: &ErrorHandlers{
 					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
 					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args: args{
-				inp: &PutInput{
-					RequestPath: "/test",
-					Filename:    "file",
-					Body:        nil,
-					ContentType: "content-type",
-				},
-			},
-			expectedS3ClientPutCalled: true,
+			args: args{input: &DeleteInput{RequestPath: "/directory/", DryRun: true}},
 			expectedHTTPWriter: &respWriterTest{
-				Status: http.StatusNoContent,
-			},
-			expectedS3ClientPutInput: &s3client.PutInput{
-				Key:         "/test/file",
-				ContentType: "content-type",
-				Metadata: map[string]string{
-					"testkey": "testvalue",
-				},
-				StorageClass: "storage-class",
+				Headers: hJSON,
+				Status:  http.StatusOK,
+				Resp:    []byte(`{"dryRun":true,"deleted":["/mountdirectory/file1"],"failed":[]}`),
 			},
+			expectedS3ClientDeleteFolderCalled: true,
+			expectedS3ClientDeleteFolderInput:  &s3client.DeleteFolderInput{Prefix: "/directory/", DryRun: true},
 		},
 		{
-			name: "should be failed when head object failed",
+			name: "Delete directory recursively with failed keys",
 			fields: fields{
 				s3Context: &s3clientTest{
-					HeadErr: errors.New("test"),
+					DeleteFolderResult: &s3client.DeleteFolderOutput{
+						DeletedKeys: []string{"/directory/file1"},
+						Errors: []*s3client.DeleteErrorOutput{
+							{Key: "/directory/file2", Code: "AccessDenied", Message: "Access Denied"},
+						},
+					},
 				},
 				targetCfg: &config.TargetConfig{
 					Bucket: &config.BucketConfig{Prefix: "/"},
 					Actions: &config.ActionsConfig{
-						PUT: &config.PutActionConfig{
-							Config: &config.PutActionConfigConfig{
-								AllowOverride: false,
-							},
+						DELETE: &config.DeleteActionConfig{
+							Enabled: true,
+							Config:  &config.DeleteActionConfigConfig{Recursive: true},
 						},
 					},
 				},
 				tplConfig: &config.TemplateConfig{},
 				mountPath: "/mount",
-				httpRW:    &respWriterTest{},
+				httpRW:    &respWriterTest{Headers: http.Header{}},
 				errorHandlers: &ErrorHandlers{
 					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
 					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args: args{
-				inp: &PutInput{
-					RequestPath: "/test",
-					Filename:    "file",
-					Body:        nil,
-					ContentType: "content-type",
-				},
+			args: args{input: &DeleteInput{RequestPath: "/directory/"}},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hJSON,
+				Status:  http.StatusInternalServerError,
+				Resp:    []byte(`{"dryRun":false,"deleted":["/mountdirectory/file1"],"failed":[{"path":"/mountdirectory/file2","code":"AccessDenied","message":"Access Denied"}]}`),
 			},
-			expectedS3ClientHeadCalled:              true,
-			expectedHandleInternalServerErrorCalled: true,
-			expectedS3ClientHeadInput:               "/test/file",
-			expectedHTTPWriter:                      &respWriterTest{},
+			expectedS3ClientDeleteFolderCalled: true,
+			expectedS3ClientDeleteFolderInput:  &s3client.DeleteFolderInput{Prefix: "/directory/"},
 		},
 		{
-			name: "should be failed when head object result that file exists",
+			name: "Delete file in dry run mode",
 			fields: fields{
 				s3Context: &s3clientTest{
-					HeadResult: &s3client.HeadOutput{Key: "/test/file"},
+					HeadResult: &s3client.HeadOutput{Type: "FILE", Key: "/file"},
 				},
 				targetCfg: &config.TargetConfig{
 					Bucket: &config.BucketConfig{Prefix: "/"},
-					Actions: &config.ActionsConfig{
-						PUT: &config.PutActionConfig{
-							Config: &config.PutActionConfigConfig{
-								AllowOverride: false,
-							},
-						},
-					},
 				},
 				tplConfig: &config.TemplateConfig{},
 				mountPath: "/mount",
-				httpRW:    &respWriterTest{},
+				httpRW:    &respWriterTest{Headers: http.Header{}},
 				errorHandlers: &ErrorHandlers{
 					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
 					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args: args{
-				inp: &PutInput{
-					RequestPath: "/test",
-					Filename:    "file",
-					Body:        nil,
-					ContentType: "content-type",
-				},
+			args: args{input: &DeleteInput{RequestPath: "/file", DryRun: true}},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hJSON,
+				Status:  http.StatusOK,
+				Resp:    []byte(`{"dryRun":true,"deleted":["/mountfile"],"failed":[]}`),
 			},
-			expectedS3ClientHeadCalled:    true,
-			expectedHandleForbiddenCalled: true,
-			expectedS3ClientHeadInput:     "/test/file",
-			expectedHTTPWriter:            &respWriterTest{},
+			expectedS3ClientHeadCalled: true,
 		},
 		{
-			name: "should be failed when head object result that file doesn't exist",
+			name: "Delete not found file in dry run mode",
 			fields: fields{
 				s3Context: &s3clientTest{
-					HeadResult: nil,
+					HeadErr: s3client.ErrNotFound,
 				},
 				targetCfg: &config.TargetConfig{
 					Bucket: &config.BucketConfig{Prefix: "/"},
-					Actions: &config.ActionsConfig{
-						PUT: &config.PutActionConfig{
-							Config: &config.PutActionConfigConfig{
-								AllowOverride: false,
-							},
-						},
-					},
 				},
 				tplConfig: &config.TemplateConfig{},
 				mountPath: "/mount",
@@ -507,23 +442,1096 @@ func Test_requestContext_Put(t *testing.T) {
 					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
 				},
 			},
-			args: args{
-				inp: &PutInput{
-					RequestPath: "/test",
-					Filename:    "file",
-					Body:        nil,
-					ContentType: "content-type",
+			args:                         args{input: &DeleteInput{RequestPath: "/file", DryRun: true}},
+			expectedHTTPWriter:           &respWriterTest{},
+			expectedHandleNotFoundCalled: true,
+			expectedS3ClientHeadCalled:   true,
+		},
+		{
+			name: "Can't delete file because of error",
+			fields: fields{
+				s3Context: &s3clientTest{
+					DeleteErr: errors.New("test"),
 				},
-			},
-			expectedS3ClientHeadCalled: true,
-			expectedS3ClientPutCalled:  true,
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args:                                    args{input: &DeleteInput{RequestPath: "/file"}},
+			expectedHTTPWriter:                      &respWriterTest{},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientDeleteCalled:            true,
+			expectedS3ClientDeleteInput:             &s3client.DeleteInput{Key: "/file"},
+		},
+		{
+			name: "Delete file succeed",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args:                         args{input: &DeleteInput{RequestPath: "/file"}},
+			expectedHTTPWriter:           &respWriterTest{Status: http.StatusNoContent},
+			expectedS3ClientDeleteCalled: true,
+			expectedS3ClientDeleteInput:  &s3client.DeleteInput{Key: "/file"},
+		},
+		{
+			name: "Delete file version succeed",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args:                         args{input: &DeleteInput{RequestPath: "/file", VersionID: "version1"}},
+			expectedHTTPWriter:           &respWriterTest{Status: http.StatusNoContent},
+			expectedS3ClientDeleteCalled: true,
+			expectedS3ClientDeleteInput:  &s3client.DeleteInput{Key: "/file", VersionID: "version1"},
+		},
+		{
+			name: "Delete not found file version in dry run mode",
+			fields: fields{
+				s3Context: &s3clientTest{
+					ListVersionsResult: []*s3client.ObjectVersionOutput{{VersionID: "version2"}},
+				},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args:                         args{input: &DeleteInput{RequestPath: "/file", DryRun: true, VersionID: "version1"}},
+			expectedHTTPWriter:           &respWriterTest{},
+			expectedHandleNotFoundCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handleForbiddenCalled = false
+			handleInternalServerErrorCalled = false
+			handleNotFoundCalled = false
+			rctx := &requestContext{
+				s3Context:      tt.fields.s3Context,
+				logger:         log.NewLogger(),
+				targetCfg:      tt.fields.targetCfg,
+				tplConfig:      tt.fields.tplConfig,
+				mountPath:      tt.fields.mountPath,
+				httpRW:         tt.fields.httpRW,
+				errorsHandlers: tt.fields.errorHandlers,
+			}
+			rctx.Delete(tt.args.input)
+			if handleNotFoundCalled != tt.expectedHandleNotFoundCalled {
+				t.Errorf("requestContext.Delete() => handleNotFoundCalled = %+v, want %+v", handleNotFoundCalled, tt.expectedHandleNotFoundCalled)
+			}
+			if handleInternalServerErrorCalled != tt.expectedHandleInternalServerErrorCalled {
+				t.Errorf("requestContext.Delete() => handleInternalServerErrorCalled = %+v, want %+v", handleInternalServerErrorCalled, tt.expectedHandleInternalServerErrorCalled)
+			}
+			if handleForbiddenCalled != tt.expectedHandleForbiddenCalled {
+				t.Errorf("requestContext.Delete() => handleForbiddenCalled = %+v, want %+v", handleForbiddenCalled, tt.expectedHandleForbiddenCalled)
+			}
+			if tt.expectedS3ClientDeleteCalled != tt.fields.s3Context.(*s3clientTest).DeleteCalled {
+				t.Errorf("requestContext.Delete() => s3client.DeleteCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).DeleteCalled, tt.expectedS3ClientDeleteCalled)
+			}
+			if !reflect.DeepEqual(tt.expectedS3ClientDeleteInput, tt.fields.s3Context.(*s3clientTest).DeleteInput) {
+				t.Errorf("requestContext.Delete() => s3client.DeleteInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).DeleteInput, tt.expectedS3ClientDeleteInput)
+			}
+			if tt.expectedS3ClientDeleteFolderCalled != tt.fields.s3Context.(*s3clientTest).DeleteFolderCalled {
+				t.Errorf("requestContext.Delete() => s3client.DeleteFolderCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).DeleteFolderCalled, tt.expectedS3ClientDeleteFolderCalled)
+			}
+			if !reflect.DeepEqual(tt.expectedS3ClientDeleteFolderInput, tt.fields.s3Context.(*s3clientTest).DeleteFolderInput) {
+				t.Errorf("requestContext.Delete() => s3client.DeleteFolderInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).DeleteFolderInput, tt.expectedS3ClientDeleteFolderInput)
+			}
+			if tt.expectedS3ClientHeadCalled != tt.fields.s3Context.(*s3clientTest).HeadCalled {
+				t.Errorf("requestContext.Delete() => s3client.HeadCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).HeadCalled, tt.expectedS3ClientHeadCalled)
+			}
+			if !reflect.DeepEqual(tt.expectedHTTPWriter, tt.fields.httpRW) {
+				t.Errorf("requestContext.Delete() => httpWriter = %+v, want %+v", tt.fields.httpRW, tt.expectedHTTPWriter)
+			}
+		})
+	}
+}
+
+func Test_requestContext_Put(t *testing.T) {
+	handleNotFoundCalled := false
+	handleInternalServerErrorCalled := false
+	handleForbiddenCalled := false
+	handleBadRequestCalled := false
+	handleRequestEntityTooLargeCalled := false
+	handleBadGatewayCalled := false
+	handleNotFoundWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
+		handleNotFoundCalled = true
+	}
+	handleInternalServerErrorWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+		handleInternalServerErrorCalled = true
+	}
+	handleForbiddenWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
+		handleForbiddenCalled = true
+	}
+	handleBadRequestWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+		handleBadRequestCalled = true
+	}
+	handleRequestEntityTooLargeWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+		handleRequestEntityTooLargeCalled = true
+	}
+	handleBadGatewayWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+		handleBadGatewayCalled = true
+	}
+	type fields struct {
+		s3Context     s3client.Client
+		targetCfg     *config.TargetConfig
+		tplConfig     *config.TemplateConfig
+		mountPath     string
+		httpRW        http.ResponseWriter
+		errorHandlers *ErrorHandlers
+	}
+	hJSON := http.Header{}
+	hJSON.Set("Content-Type", "application/json; charset=utf-8")
+	type args struct {
+		inp *PutInput
+	}
+	tests := []struct {
+		name                                      string
+		fields                                    fields
+		args                                      args
+		expectedHandleNotFoundCalled              bool
+		expectedHandleInternalServerErrorCalled   bool
+		expectedHandleForbiddenCalled             bool
+		expectedHandleBadRequestCalled            bool
+		expectedHandleRequestEntityTooLargeCalled bool
+		expectedHandleBadGatewayCalled            bool
+		expectedHTTPWriter                        *respWriterTest
+		expectedS3ClientPutCalled                 bool
+		expectedS3ClientPutInput                  *s3client.PutInput
+		expectedS3ClientHeadCalled                bool
+		expectedS3ClientHeadInput                 string
+	}{
+		{
+			name: "should put object with request path as key when filename is empty",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket:  &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleBadRequestWithTemplate:          handleBadRequestWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test/file",
+					Files: []*PutFileInput{{
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedS3ClientPutCalled: true,
+			expectedHTTPWriter: &respWriterTest{
+				Status: http.StatusNoContent,
+			},
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/file",
+				ContentType: "content-type",
+			},
+		},
+		{
+			name: "should fail when filename is empty and request path is a folder",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket:  &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleBadRequestWithTemplate:          handleBadRequestWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test/",
+					Files: []*PutFileInput{{
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedHTTPWriter:             &respWriterTest{},
+			expectedHandleBadRequestCalled: true,
+		},
+		{
+			name: "should put multiple files with relative paths and answer with a report",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket:  &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{Headers: http.Header{}},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleBadRequestWithTemplate:          handleBadRequestWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test/",
+					Files: []*PutFileInput{
+						{Filename: "file1", ContentType: "content-type"},
+						{Filename: "dir/sub/file2", ContentType: "content-type2"},
+					},
+				},
+			},
+			expectedS3ClientPutCalled: true,
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hJSON,
+				Status:  http.StatusOK,
+				Resp:    []byte(`{"uploaded":["/mounttest/file1","/mounttest/dir/sub/file2"],"failed":[]}`),
+			},
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/dir/sub/file2",
+				ContentType: "content-type2",
+			},
+		},
+		{
+			name: "should report failed files when putting multiple files",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket:  &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{Headers: http.Header{}},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleBadRequestWithTemplate:          handleBadRequestWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test/",
+					Files: []*PutFileInput{
+						{Filename: "file1", ContentType: "content-type"},
+						{Filename: "../file2", ContentType: "content-type2"},
+					},
+				},
+			},
+			expectedS3ClientPutCalled: true,
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hJSON,
+				Status:  http.StatusBadRequest,
+				Resp:    []byte(`{"uploaded":["/mounttest/file1"],"failed":[{"path":"/mounttest/../file2","message":"file name must not be empty or contain \"..\" elements"}]}`),
+			},
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/file1",
+				ContentType: "content-type",
+			},
+		},
+		{
+			name: "should answer with an internal server error when uploads of multiple files fail on backend",
+			fields: fields{
+				s3Context: &s3clientTest{PutErr: errors.New("fake")},
+				targetCfg: &config.TargetConfig{
+					Bucket:  &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{Headers: http.Header{}},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleBadRequestWithTemplate:          handleBadRequestWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test/",
+					Files: []*PutFileInput{
+						{Filename: "file1", ContentType: "content-type"},
+						{Filename: "../file2", ContentType: "content-type2"},
+					},
+				},
+			},
+			expectedS3ClientPutCalled: true,
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hJSON,
+				Status:  http.StatusInternalServerError,
+				Resp:    []byte(`{"uploaded":[],"failed":[{"path":"/mounttest/file1","message":"fake"},{"path":"/mounttest/../file2","message":"file name must not be empty or contain \"..\" elements"}]}`),
+			},
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/file1",
+				ContentType: "content-type",
+			},
+		},
+		{
+			name: "should fail when filename goes up in the tree",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket:  &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleBadRequestWithTemplate:          handleBadRequestWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test/",
+					Files: []*PutFileInput{{
+						Filename:    "dir/../../file",
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedHTTPWriter:             &respWriterTest{},
+			expectedHandleBadRequestCalled: true,
+		},
+		{
+			name: "should fail when file is greater than maximum size",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						PUT: &config.PutActionConfig{
+							Config: &config.PutActionConfigConfig{
+								AllowOverride: true,
+								MaxSize:       10,
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:             handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:              handleNotFoundWithTemplate,
+					HandleBadRequestWithTemplate:            handleBadRequestWithTemplate,
+					HandleRequestEntityTooLargeWithTemplate: handleRequestEntityTooLargeWithTemplate,
+					HandleInternalServerErrorWithTemplate:   handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test/",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						ContentType: "content-type",
+						Size:        11,
+					}},
+				},
+			},
+			expectedHTTPWriter:                        &respWriterTest{},
+			expectedHandleRequestEntityTooLargeCalled: true,
+		},
+		{
+			name: "should fail when filename isn't allowed",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						PUT: &config.PutActionConfig{
+							Config: &config.PutActionConfigConfig{
+								AllowOverride:         true,
+								AllowedFilenames:      []string{"*.pdf"},
+								AllowedFilenamesGlobs: []glob.Glob{glob.MustCompile("*.pdf")},
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:             handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:              handleNotFoundWithTemplate,
+					HandleBadRequestWithTemplate:            handleBadRequestWithTemplate,
+					HandleRequestEntityTooLargeWithTemplate: handleRequestEntityTooLargeWithTemplate,
+					HandleInternalServerErrorWithTemplate:   handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test/",
+					Files: []*PutFileInput{{
+						Filename:    "file.exe",
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedHTTPWriter:             &respWriterTest{},
+			expectedHandleBadRequestCalled: true,
+		},
+		{
+			name: "should fail when put object failed and no put configuration exists",
+			fields: fields{
+				s3Context: &s3clientTest{
+					PutErr: errors.New("test"),
+				},
+				targetCfg: &config.TargetConfig{
+					Bucket:  &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						Body:        nil,
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedS3ClientPutCalled:               true,
+			expectedHTTPWriter:                      &respWriterTest{},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/file",
+				ContentType: "content-type",
+			},
+		},
+		{
+			name: "should fail with bad gateway when put object failed on mirror buckets",
+			fields: fields{
+				s3Context: &s3clientTest{
+					PutErr: &s3client.MirrorWriteError{},
+				},
+				targetCfg: &config.TargetConfig{
+					Bucket:  &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+					HandleBadGatewayWithTemplate:          handleBadGatewayWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						Body:        nil,
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedS3ClientPutCalled:      true,
+			expectedHTTPWriter:             &respWriterTest{},
+			expectedHandleBadGatewayCalled: true,
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/file",
+				ContentType: "content-type",
+			},
+		},
+		{
+			name: "should fail when put object failed and put configuration exists with allow override",
+			fields: fields{
+				s3Context: &s3clientTest{
+					PutErr: errors.New("test"),
+				},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						PUT: &config.PutActionConfig{
+							Config: &config.PutActionConfigConfig{
+								Metadata: map[string]string{
+									"testkey": "testvalue",
+								},
+								StorageClass:  "storage-class",
+								AllowOverride: true,
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						Body:        nil,
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedS3ClientPutCalled:               true,
+			expectedHTTPWriter:                      &respWriterTest{},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/file",
+				ContentType: "content-type",
+				Metadata: map[string]string{
+					"testkey": "testvalue",
+				},
+				StorageClass: "storage-class",
+			},
+		},
+		{
+			name: "should be ok with allow override",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						PUT: &config.PutActionConfig{
+							Config: &config.PutActionConfigConfig{
+								Metadata: map[string]string{
+									"testkey": "testvalue",
+								},
+								StorageClass:  "storage-class",
+								AllowOverride: true,
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						Body:        nil,
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedS3ClientPutCalled: true,
+			expectedHTTPWriter: &respWriterTest{
+				Status: http.StatusNoContent,
+			},
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/file",
+				ContentType: "content-type",
+				Metadata: map[string]string{
+					"testkey": "testvalue",
+				},
+				StorageClass: "storage-class",
+			},
+		},
+		{
+			name: "should be ok with metadata and tags templates",
+			fields: fields{
+				s3Context: &s3clientTest{},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						PUT: &config.PutActionConfig{
+							Config: &config.PutActionConfigConfig{
+								Metadata: map[string]string{
+									"uploader": "{{ .User.Identifier }}",
+									"filename": "{{ .Filename }}",
+								},
+								Tags: map[string]string{
+									"ip":   "{{ .ClientIP }}",
+									"type": "{{ .User.Type }}",
+								},
+								AllowOverride: true,
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						Body:        nil,
+						ContentType: "content-type",
+					}},
+					UploadContext: &UploadContext{
+						User:     &models.BasicAuthUser{Username: "user1"},
+						ClientIP: "1.2.3.4",
+					},
+				},
+			},
+			expectedS3ClientPutCalled: true,
+			expectedHTTPWriter: &respWriterTest{
+				Status: http.StatusNoContent,
+			},
+			expectedS3ClientPutInput: &s3client.PutInput{
+				Key:         "/test/file",
+				ContentType: "content-type",
+				Metadata: map[string]string{
+					"uploader": "user1",
+					"filename": "file",
+				},
+				Tags: map[string]string{
+					"ip":   "1.2.3.4",
+					"type": models.BasicAuthUserType,
+				},
+			},
+		},
+		{
+			name: "should be failed when head object failed",
+			fields: fields{
+				s3Context: &s3clientTest{
+					HeadErr: errors.New("test"),
+				},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						PUT: &config.PutActionConfig{
+							Config: &config.PutActionConfigConfig{
+								AllowOverride: false,
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						Body:        nil,
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedS3ClientHeadCalled:              true,
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientHeadInput:               "/test/file",
+			expectedHTTPWriter:                      &respWriterTest{},
+		},
+		{
+			name: "should be failed when head object result that file exists",
+			fields: fields{
+				s3Context: &s3clientTest{
+					HeadResult: &s3client.HeadOutput{Key: "/test/file"},
+				},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						PUT: &config.PutActionConfig{
+							Config: &config.PutActionConfigConfig{
+								AllowOverride: false,
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						Body:        nil,
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedS3ClientHeadCalled:    true,
+			expectedHandleForbiddenCalled: true,
+			expectedS3ClientHeadInput:     "/test/file",
+			expectedHTTPWriter:            &respWriterTest{},
+		},
+		{
+			name: "should be failed when head object result that file doesn't exist",
+			fields: fields{
+				s3Context: &s3clientTest{
+					HeadResult: nil,
+				},
+				targetCfg: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Prefix: "/"},
+					Actions: &config.ActionsConfig{
+						PUT: &config.PutActionConfig{
+							Config: &config.PutActionConfigConfig{
+								AllowOverride: false,
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				inp: &PutInput{
+					RequestPath: "/test",
+					Files: []*PutFileInput{{
+						Filename:    "file",
+						Body:        nil,
+						ContentType: "content-type",
+					}},
+				},
+			},
+			expectedS3ClientHeadCalled: true,
+			expectedS3ClientPutCalled:  true,
 			expectedS3ClientHeadInput:  "/test/file",
 			expectedS3ClientPutInput: &s3client.PutInput{
 				Key:         "/test/file",
 				ContentType: "content-type",
 			},
 			expectedHTTPWriter: &respWriterTest{
-				Status: http.StatusNoContent,
+				Status: http.StatusNoContent,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handleForbiddenCalled = false
+			handleInternalServerErrorCalled = false
+			handleNotFoundCalled = false
+			handleBadRequestCalled = false
+			handleRequestEntityTooLargeCalled = false
+			handleBadGatewayCalled = false
+			rctx := &requestContext{
+				s3Context:      tt.fields.s3Context,
+				logger:         log.NewLogger(),
+				targetCfg:      tt.fields.targetCfg,
+				tplConfig:      tt.fields.tplConfig,
+				mountPath:      tt.fields.mountPath,
+				httpRW:         tt.fields.httpRW,
+				errorsHandlers: tt.fields.errorHandlers,
+			}
+			rctx.Put(tt.args.inp)
+			if handleNotFoundCalled != tt.expectedHandleNotFoundCalled {
+				t.Errorf("requestContext.Put() => handleNotFoundCalled = %+v, want %+v", handleNotFoundCalled, tt.expectedHandleNotFoundCalled)
+			}
+			if handleInternalServerErrorCalled != tt.expectedHandleInternalServerErrorCalled {
+				t.Errorf("requestContext.Put() => handleInternalServerErrorCalled = %+v, want %+v", handleInternalServerErrorCalled, tt.expectedHandleInternalServerErrorCalled)
+			}
+			if handleForbiddenCalled != tt.expectedHandleForbiddenCalled {
+				t.Errorf("requestContext.Put() => handleForbiddenCalled = %+v, want %+v", handleForbiddenCalled, tt.expectedHandleForbiddenCalled)
+			}
+			if handleBadRequestCalled != tt.expectedHandleBadRequestCalled {
+				t.Errorf("requestContext.Put() => handleBadRequestCalled = %+v, want %+v", handleBadRequestCalled, tt.expectedHandleBadRequestCalled)
+			}
+			if handleBadGatewayCalled != tt.expectedHandleBadGatewayCalled {
+				t.Errorf("requestContext.Put() => handleBadGatewayCalled = %+v, want %+v", handleBadGatewayCalled, tt.expectedHandleBadGatewayCalled)
+			}
+			if handleRequestEntityTooLargeCalled != tt.expectedHandleRequestEntityTooLargeCalled {
+				t.Errorf("requestContext.Put() => handleRequestEntityTooLargeCalled = %+v, want %+v", handleRequestEntityTooLargeCalled, tt.expectedHandleRequestEntityTooLargeCalled)
+			}
+			if tt.expectedS3ClientPutCalled != tt.fields.s3Context.(*s3clientTest).PutCalled {
+				t.Errorf("requestContext.Put() => s3client.PutCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).PutCalled, tt.expectedS3ClientPutCalled)
+			}
+			if !reflect.DeepEqual(tt.expectedS3ClientPutInput, tt.fields.s3Context.(*s3clientTest).PutInput) {
+				t.Errorf("requestContext.Put() => s3client.PutInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).PutInput, tt.expectedS3ClientPutInput)
+			}
+			if tt.expectedS3ClientHeadCalled != tt.fields.s3Context.(*s3clientTest).HeadCalled {
+				t.Errorf("requestContext.Put() => s3client.HeadCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).HeadCalled, tt.expectedS3ClientHeadCalled)
+			}
+			if !reflect.DeepEqual(tt.expectedS3ClientHeadInput, tt.fields.s3Context.(*s3clientTest).HeadInput) {
+				t.Errorf("requestContext.Put() => s3client.HeadInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).HeadInput, tt.expectedS3ClientHeadInput)
+			}
+			if !reflect.DeepEqual(tt.expectedHTTPWriter, tt.fields.httpRW) {
+				t.Errorf("requestContext.Put() => httpWriter = %+v, want %+v", tt.fields.httpRW, tt.expectedHTTPWriter)
+			}
+		})
+	}
+}
+
+func Test_requestContext_PresignUpload(t *testing.T) {
+	handleInternalServerErrorCalled := false
+	handleForbiddenCalled := false
+	handleBadRequestCalled := false
+	handleInternalServerErrorWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+		handleInternalServerErrorCalled = true
+	}
+	handleForbiddenWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
+		handleForbiddenCalled = true
+	}
+	handleBadRequestWithTemplate := func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+		handleBadRequestCalled = true
+	}
+	errorHandlers := &ErrorHandlers{
+		HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+		HandleBadRequestWithTemplate:          handleBadRequestWithTemplate,
+		HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+	}
+	hJSON := http.Header{}
+	hJSON.Set("Content-Type", "application/json; charset=utf-8")
+	enabledTarget := func(putCfg *config.PutActionConfigConfig) *config.TargetConfig {
+		putCfg.PresignedUpload = &config.PresignedUploadConfig{Enabled: true, Expiry: time.Hour}
+		return &config.TargetConfig{
+			Bucket:  &config.BucketConfig{Prefix: "/"},
+			Actions: &config.ActionsConfig{PUT: &config.PutActionConfig{Enabled: true, Config: putCfg}},
+		}
+	}
+	tests := []struct {
+		name                                    string
+		s3Context                               *s3clientTest
+		targetCfg                               *config.TargetConfig
+		input                                   *PresignUploadInput
+		expectedHandleInternalServerErrorCalled bool
+		expectedHandleForbiddenCalled           bool
+		expectedHandleBadRequestCalled          bool
+		expectedHTTPWriter                      *respWriterTest
+		expectedS3ClientHeadCalled              bool
+		expectedS3ClientPresignPutInput         *s3client.PresignPutInput
+		expectedS3ClientPresignPostInput        *s3client.PresignPutInput
+	}{
+		{
+			name:      "should fail when presigned upload isn't enabled",
+			s3Context: &s3clientTest{},
+			targetCfg: &config.TargetConfig{
+				Bucket:  &config.BucketConfig{Prefix: "/"},
+				Actions: &config.ActionsConfig{PUT: &config.PutActionConfig{Enabled: true}},
+			},
+			input:                          &PresignUploadInput{RequestPath: "/test/file", Method: "put", Size: -1},
+			expectedHandleBadRequestCalled: true,
+			expectedHTTPWriter:             &respWriterTest{},
+		},
+		{
+			name:                           "should fail when method isn't supported",
+			s3Context:                      &s3clientTest{},
+			targetCfg:                      enabledTarget(&config.PutActionConfigConfig{AllowOverride: true}),
+			input:                          &PresignUploadInput{RequestPath: "/test/file", Method: "get", Size: -1},
+			expectedHandleBadRequestCalled: true,
+			expectedHTTPWriter:             &respWriterTest{},
+		},
+		{
+			name:                           "should fail when request path is a folder",
+			s3Context:                      &s3clientTest{},
+			targetCfg:                      enabledTarget(&config.PutActionConfigConfig{AllowOverride: true}),
+			input:                          &PresignUploadInput{RequestPath: "/test/", Method: "put", Size: -1},
+			expectedHandleBadRequestCalled: true,
+			expectedHTTPWriter:             &respWriterTest{},
+		},
+		{
+			name:      "should fail when file name isn't allowed",
+			s3Context: &s3clientTest{},
+			targetCfg: enabledTarget(&config.PutActionConfigConfig{
+				AllowOverride:         true,
+				AllowedFilenames:      []string{"*.pdf"},
+				AllowedFilenamesGlobs: []glob.Glob{glob.MustCompile("*.pdf")},
+			}),
+			input:                          &PresignUploadInput{RequestPath: "/test/file.exe", Method: "put", Size: -1},
+			expectedHandleBadRequestCalled: true,
+			expectedHTTPWriter:             &respWriterTest{},
+		},
+		{
+			name:                           "should fail when size isn't declared and upload policy has a maximum size",
+			s3Context:                      &s3clientTest{},
+			targetCfg:                      enabledTarget(&config.PutActionConfigConfig{AllowOverride: true, MaxSize: 10}),
+			input:                          &PresignUploadInput{RequestPath: "/test/file", Method: "put", Size: -1},
+			expectedHandleBadRequestCalled: true,
+			expectedHTTPWriter:             &respWriterTest{},
+		},
+		{
+			name:                           "should fail when content type isn't declared and upload policy checks content types",
+			s3Context:                      &s3clientTest{},
+			targetCfg:                      enabledTarget(&config.PutActionConfigConfig{AllowOverride: true, AllowedContentTypes: []string{"image/*"}, AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("image/*")}}),
+			input:                          &PresignUploadInput{RequestPath: "/test/file", Method: "post", Size: -1},
+			expectedHandleBadRequestCalled: true,
+			expectedHTTPWriter:             &respWriterTest{},
+		},
+		{
+			name:                           "should fail when declared content type isn't allowed",
+			s3Context:                      &s3clientTest{},
+			targetCfg:                      enabledTarget(&config.PutActionConfigConfig{AllowOverride: true, DeniedContentTypes: []string{"text/*"}, DeniedContentTypesGlobs: []glob.Glob{glob.MustCompile("text/*")}}),
+			input:                          &PresignUploadInput{RequestPath: "/test/file", Method: "put", ContentType: "text/html", Size: -1},
+			expectedHandleBadRequestCalled: true,
+			expectedHTTPWriter:             &respWriterTest{},
+		},
+		{
+			name: "should answer with a presigned PUT URL signing declared content type and size",
+			s3Context: &s3clientTest{PresignPutResult: &s3client.PresignPutOutput{
+				URL:     "https://s3/bucket/test/file",
+				Headers: map[string]string{"Content-Type": "image/png"},
+			}},
+			targetCfg: enabledTarget(&config.PutActionConfigConfig{
+				AllowOverride:            true,
+				MaxSize:                  10,
+				AllowedContentTypes:      []string{"image/*"},
+				AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("image/*")},
+			}),
+			input: &PresignUploadInput{RequestPath: "/test/file", Method: "put", ContentType: "image/png", Size: 10},
+			expectedS3ClientPresignPutInput: &s3client.PresignPutInput{
+				Key:         "/test/file",
+				Expiry:      time.Hour,
+				ContentType: "image/png",
+				Size:        10,
+				MaxSize:     10,
+			},
+			expectedHTTPWriter: &respWriterTest{
+				Status:  http.StatusOK,
+				Headers: hJSON,
+				Resp:    []byte(`{"method":"PUT","url":"https://s3/bucket/test/file","headers":{"Content-Type":"image/png"}}`),
+			},
+		},
+		{
+			name:                          "should fail when file exists and override isn't allowed",
+			s3Context:                     &s3clientTest{HeadResult: &s3client.HeadOutput{Key: "/test/file"}},
+			targetCfg:                     enabledTarget(&config.PutActionConfigConfig{AllowOverride: false}),
+			input:                         &PresignUploadInput{RequestPath: "/test/file", Method: "put", Size: -1},
+			expectedHandleForbiddenCalled: true,
+			expectedS3ClientHeadCalled:    true,
+			expectedHTTPWriter:            &respWriterTest{},
+		},
+		{
+			name:                                    "should fail when presign fails",
+			s3Context:                               &s3clientTest{PresignPutErr: errors.New("test")},
+			targetCfg:                               enabledTarget(&config.PutActionConfigConfig{AllowOverride: true}),
+			input:                                   &PresignUploadInput{RequestPath: "/test/file", Method: "put", Size: -1},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientPresignPutInput:         &s3client.PresignPutInput{Key: "/test/file", Expiry: time.Hour, Size: -1},
+			expectedHTTPWriter:                      &respWriterTest{},
+		},
+		{
+			name: "should answer with a presigned PUT URL",
+			s3Context: &s3clientTest{PresignPutResult: &s3client.PresignPutOutput{
+				URL:     "https://s3/bucket/test/file",
+				Headers: map[string]string{"X-Amz-Storage-Class": "GLACIER"},
+			}},
+			targetCfg: enabledTarget(&config.PutActionConfigConfig{
+				StorageClass: "GLACIER",
+				Metadata:     map[string]string{"meta": "value"},
+			}),
+			input:                      &PresignUploadInput{RequestPath: "/test/file", Method: "PUT", Size: -1},
+			expectedS3ClientHeadCalled: true,
+			expectedS3ClientPresignPutInput: &s3client.PresignPutInput{
+				Key:          "/test/file",
+				Expiry:       time.Hour,
+				Metadata:     map[string]string{"meta": "value"},
+				StorageClass: "GLACIER",
+				Size:         -1,
+			},
+			expectedHTTPWriter: &respWriterTest{
+				Status:  http.StatusOK,
+				Headers: hJSON,
+				Resp:    []byte(`{"method":"PUT","url":"https://s3/bucket/test/file","headers":{"X-Amz-Storage-Class":"GLACIER"}}`),
+			},
+		},
+		{
+			name: "should answer with a presigned POST form",
+			s3Context: &s3clientTest{PresignPostResult: &s3client.PresignPostOutput{
+				URL:    "https://s3/bucket",
+				Fields: map[string]string{"key": "test/file"},
+			}},
+			targetCfg: enabledTarget(&config.PutActionConfigConfig{AllowOverride: true, MaxSize: 10}),
+			input:     &PresignUploadInput{RequestPath: "/test/file", Method: "post", Size: 5},
+			expectedS3ClientPresignPostInput: &s3client.PresignPutInput{
+				Key:     "/test/file",
+				Expiry:  time.Hour,
+				Size:    5,
+				MaxSize: 10,
+			},
+			expectedHTTPWriter: &respWriterTest{
+				Status:  http.StatusOK,
+				Headers: hJSON,
+				Resp:    []byte(`{"method":"POST","url":"https://s3/bucket","fields":{"key":"test/file"}}`),
 			},
 		},
 	}
@@ -531,40 +1539,41 @@ func Test_requestContext_Put(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			handleForbiddenCalled = false
 			handleInternalServerErrorCalled = false
-			handleNotFoundCalled = false
+			handleBadRequestCalled = false
+			httpRW := &respWriterTest{}
+			if tt.expectedHTTPWriter.Headers != nil {
+				httpRW.Headers = http.Header{}
+			}
 			rctx := &requestContext{
-				s3Context:      tt.fields.s3Context,
+				s3Context:      tt.s3Context,
 				logger:         log.NewLogger(),
-				targetCfg:      tt.fields.targetCfg,
-				tplConfig:      tt.fields.tplConfig,
-				mountPath:      tt.fields.mountPath,
-				httpRW:         tt.fields.httpRW,
-				errorsHandlers: tt.fields.errorHandlers,
-			}
-			rctx.Put(tt.args.inp)
-			if handleNotFoundCalled != tt.expectedHandleNotFoundCalled {
-				t.Errorf("requestContext.Put() => handleNotFoundCalled = %+v, want %+v", handleNotFoundCalled, tt.expectedHandleNotFoundCalled)
+				targetCfg:      tt.targetCfg,
+				tplConfig:      &config.TemplateConfig{},
+				mountPath:      "/mount",
+				httpRW:         httpRW,
+				errorsHandlers: errorHandlers,
 			}
+			rctx.PresignUpload(tt.input)
 			if handleInternalServerErrorCalled != tt.expectedHandleInternalServerErrorCalled {
-				t.Errorf("requestContext.Put() => handleInternalServerErrorCalled = %+v, want %+v", handleInternalServerErrorCalled, tt.expectedHandleInternalServerErrorCalled)
+				t.Errorf("requestContext.PresignUpload() => handleInternalServerErrorCalled = %+v, want %+v", handleInternalServerErrorCalled, tt.expectedHandleInternalServerErrorCalled)
 			}
 			if handleForbiddenCalled != tt.expectedHandleForbiddenCalled {
-				t.Errorf("requestContext.Put() => handleForbiddenCalled = %+v, want %+v", handleForbiddenCalled, tt.expectedHandleForbiddenCalled)
+				t.Errorf("requestContext.PresignUpload() => handleForbiddenCalled = %+v, want %+v", handleForbiddenCalled, tt.expectedHandleForbiddenCalled)
 			}
-			if tt.expectedS3ClientPutCalled != tt.fields.s3Context.(*s3clientTest).PutCalled {
-				t.Errorf("requestContext.Put() => s3client.PutCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).PutCalled, tt.expectedS3ClientPutCalled)
+			if handleBadRequestCalled != tt.expectedHandleBadRequestCalled {
+				t.Errorf("requestContext.PresignUpload() => handleBadRequestCalled = %+v, want %+v", handleBadRequestCalled, tt.expectedHandleBadRequestCalled)
 			}
-			if !reflect.DeepEqual(tt.expectedS3ClientPutInput, tt.fields.s3Context.(*s3clientTest).PutInput) {
-				t.Errorf("requestContext.Put() => s3client.PutInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).PutInput, tt.expectedS3ClientPutInput)
+			if tt.expectedS3ClientHeadCalled != tt.s3Context.HeadCalled {
+				t.Errorf("requestContext.PresignUpload() => s3client.HeadCalled = %+v, want %+v", tt.s3Context.HeadCalled, tt.expectedS3ClientHeadCalled)
 			}
-			if tt.expectedS3ClientHeadCalled != tt.fields.s3Context.(*s3clientTest).HeadCalled {
-				t.Errorf("requestContext.Put() => s3client.HeadCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).HeadCalled, tt.expectedS3ClientHeadCalled)
+			if !reflect.DeepEqual(tt.expectedS3ClientPresignPutInput, tt.s3Context.PresignPutInput) {
+				t.Errorf("requestContext.PresignUpload() => s3client.PresignPutInput = %+v, want %+v", tt.s3Context.PresignPutInput, tt.expectedS3ClientPresignPutInput)
 			}
-			if !reflect.DeepEqual(tt.expectedS3ClientHeadInput, tt.fields.s3Context.(*s3clientTest).HeadInput) {
-				t.Errorf("requestContext.Put() => s3client.HeadInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).HeadInput, tt.expectedS3ClientHeadInput)
+			if !reflect.DeepEqual(tt.expectedS3ClientPresignPostInput, tt.s3Context.PresignPostInput) {
+				t.Errorf("requestContext.PresignUpload() => s3client.PresignPostInput = %+v, want %+v", tt.s3Context.PresignPostInput, tt.expectedS3ClientPresignPostInput)
 			}
-			if !reflect.DeepEqual(tt.expectedHTTPWriter, tt.fields.httpRW) {
-				t.Errorf("requestContext.Put() => httpWriter = %+v, want %+v", tt.fields.httpRW, tt.expectedHTTPWriter)
+			if !reflect.DeepEqual(tt.expectedHTTPWriter, httpRW) {
+				t.Errorf("requestContext.PresignUpload() => httpWriter = %+v, want %+v", httpRW, tt.expectedHTTPWriter)
 			}
 		})
 	}
@@ -586,8 +1595,21 @@ func Test_requestContext_Get(t *testing.T) {
 	}
 	h := http.Header{}
 	h.Set("Content-Type", "text/html; charset=utf-8")
+	hFile := http.Header{}
+	hFile.Set("Content-Type", "text/html; charset=utf-8")
+	hFile.Set("Accept-Ranges", "bytes")
+	hLocation := http.Header{}
+	hLocation.Set("Location", "https://bucket1.s3.amazonaws.com/folder/file1?X-Amz-Signature=fake")
+	hJSON := http.Header{}
+	hJSON.Set("Content-Type", "application/json; charset=utf-8")
+	hRange := http.Header{}
+	hRange.Set("Content-Type", "text/html; charset=utf-8")
+	hRange.Set("Accept-Ranges", "bytes")
+	hRange.Set("Content-Length", "4")
+	hRange.Set("Content-Range", "bytes 0-3/23")
 	fakeIndexIoReadCloser := ioutil.NopCloser(strings.NewReader("fake-index.html-content"))
 	fakeIndexIoReadCloser2 := ioutil.NopCloser(strings.NewReader("fake-index.html-content"))
+	fakeRangeIoReadCloser := ioutil.NopCloser(strings.NewReader("fake"))
 	type fields struct {
 		s3Context     s3client.Client
 		targetCfg     *config.TargetConfig
@@ -597,7 +1619,7 @@ func Test_requestContext_Get(t *testing.T) {
 		errorHandlers *ErrorHandlers
 	}
 	type args struct {
-		requestPath string
+		input *GetInput
 	}
 	tests := []struct {
 		name                                    string
@@ -608,12 +1630,344 @@ func Test_requestContext_Get(t *testing.T) {
 		expectedHandleForbiddenCalled           bool
 		expectedHTTPWriter                      *respWriterTest
 		expectedS3ClientListCalled              bool
-		expectedS3ClientListInput               string
+		expectedS3ClientListInput               *s3client.ListInput
 		expectedS3ClientGetCalled               bool
-		expectedS3ClientGetInput                string
+		expectedS3ClientGetInput                *s3client.GetInput
+		expectedS3ClientPresignGetCalled        bool
+		expectedS3ClientPresignGetInput         *s3client.PresignGetInput
 	}{
 		{
-			name: "should fail if list files and directories failed",
+			name: "should redirect to a presigned url when enabled",
+			fields: fields{
+				s3Context: &s3clientTest{
+					PresignGetResult: "https://bucket1.s3.amazonaws.com/folder/file1?X-Amz-Signature=fake",
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+					Actions: &config.ActionsConfig{
+						GET: &config.GetActionConfig{
+							Enabled: true,
+							Config: &config.GetActionConfigConfig{
+								PresignedURLRedirect: &config.PresignedURLRedirectConfig{
+									Enabled: true,
+									Expiry:  time.Hour,
+									ResponseHeaders: &config.PresignedURLResponseHeadersConfig{
+										ContentDisposition: "attachment",
+										ContentType:        "application/octet-stream",
+									},
+								},
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{Headers: http.Header{}},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/file1"},
+			},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hLocation,
+				Status:  http.StatusFound,
+			},
+			expectedS3ClientPresignGetCalled: true,
+			expectedS3ClientPresignGetInput: &s3client.PresignGetInput{
+				Key:                        "/folder/file1",
+				Expiry:                     time.Hour,
+				ResponseContentDisposition: "attachment",
+				ResponseContentType:        "application/octet-stream",
+			},
+		},
+		{
+			name: "should fail when presigned url generation failed",
+			fields: fields{
+				s3Context: &s3clientTest{
+					PresignGetErr: errors.New("test"),
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+					Actions: &config.ActionsConfig{
+						GET: &config.GetActionConfig{
+							Enabled: true,
+							Config: &config.GetActionConfigConfig{
+								PresignedURLRedirect: &config.PresignedURLRedirectConfig{
+									Enabled: true,
+								},
+							},
+						},
+					},
+				},
+				tplConfig: &config.TemplateConfig{},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/file1"},
+			},
+			expectedHTTPWriter:                      &respWriterTest{},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientPresignGetCalled:        true,
+			expectedS3ClientPresignGetInput: &s3client.PresignGetInput{
+				Key:    "/folder/file1",
+				Expiry: config.DefaultPresignedURLExpiry,
+			},
+		},
+		{
+			name: "should fail if list files and directories failed",
+			fields: fields{
+				s3Context: &s3clientTest{
+					ListErr: errors.New("test"),
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+				},
+				tplConfig: &config.TemplateConfig{
+					FolderList: "../../../templates/folder-list.tpl",
+				},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/"},
+			},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientListCalled:              true,
+			expectedS3ClientListInput:               &s3client.ListInput{Key: "/folder/", MaxKeys: 1000},
+			expectedHTTPWriter:                      &respWriterTest{},
+		},
+		{
+			name: "should fail if list files and directories template failed because template not found",
+			fields: fields{
+				s3Context: &s3clientTest{
+					ListResult: &s3client.ListOutput{
+						Entries: []*s3client.ListElementOutput{
+							{
+								Name:         "file1",
+								Type:         "FILE",
+								ETag:         "etag",
+								LastModified: fakeDate,
+								Size:         300,
+								Key:          "/folder/file1",
+							},
+						},
+					},
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+				},
+				tplConfig: &config.TemplateConfig{
+					FolderList: "fake/path",
+				},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/"},
+			},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientListCalled:              true,
+			expectedS3ClientListInput:               &s3client.ListInput{Key: "/folder/", MaxKeys: 1000},
+			expectedHTTPWriter:                      &respWriterTest{},
+		},
+		{
+			name: "should be ok to list files and directories",
+			fields: fields{
+				s3Context: &s3clientTest{
+					ListResult: &s3client.ListOutput{
+						Entries: []*s3client.ListElementOutput{
+							{
+								Name:         "file1",
+								Type:         "FILE",
+								ETag:         "etag",
+								LastModified: fakeDate,
+								Size:         300,
+								Key:          "/folder/file1",
+							},
+						},
+					},
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+				},
+				tplConfig: &config.TemplateConfig{
+					FolderList: "../../../templates/folder-list.tpl",
+				},
+				mountPath: "/mount",
+				httpRW: &respWriterTest{
+					Headers: http.Header{},
+				},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/"},
+			},
+			expectedS3ClientListCalled: true,
+			expectedS3ClientListInput:  &s3client.ListInput{Key: "/folder/", MaxKeys: 1000},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: h,
+				Status:  http.StatusOK,
+				Resp: []byte(`<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Index of /mount/folder/</h1>
+    <table style="width:100%">
+        <thead>
+            <tr>
+                <th style="border-right:1px solid black;text-align:start">Entry</th>
+                <th style="border-right:1px solid black;text-align:start">Size</th>
+                <th style="border-right:1px solid black;text-align:start">Last modified</th>
+            </tr>
+        </thead>
+        <tbody style="border-top:1px solid black">
+          <tr>
+            <td style="border-right:1px solid black;padding: 0 5px"><a href="..">..</a></td>
+            <td style="border-right:1px solid black;padding: 0 5px"> - </td>
+            <td style="padding: 0 5px"> - </td>
+          </tr>
+          <tr>
+              <td style="border-right:1px solid black;padding: 0 5px"><a href="/mountfolder/file1">file1</a></td>
+              <td style="border-right:1px solid black;padding: 0 5px">300 B</td>
+              <td style="padding: 0 5px">1990-12-25 01:01:01.000000001 &#43;0000 UTC</td>
+          </tr>
+        </tbody>
+    </table>
+  </body>
+</html>
+`),
+			},
+		},
+		{
+			name: "should be ok to list files and directories with pagination links",
+			fields: fields{
+				s3Context: &s3clientTest{
+					ListResult: &s3client.ListOutput{
+						Entries: []*s3client.ListElementOutput{
+							{
+								Name:         "file1",
+								Type:         "FILE",
+								ETag:         "etag",
+								LastModified: fakeDate,
+								Size:         300,
+								Key:          "/folder/file1",
+							},
+						},
+						NextContinuationToken: "token3",
+					},
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+				},
+				tplConfig: &config.TemplateConfig{
+					FolderList: "../../../templates/folder-list.tpl",
+				},
+				mountPath: "/mount",
+				httpRW: &respWriterTest{
+					Headers: http.Header{},
+				},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{
+					RequestPath:                "/folder/",
+					ContinuationToken:          "token2",
+					MaxKeys:                    1,
+					PreviousContinuationTokens: []string{""},
+				},
+			},
+			expectedS3ClientListCalled: true,
+			expectedS3ClientListInput:  &s3client.ListInput{Key: "/folder/", ContinuationToken: "token2", MaxKeys: 1},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: h,
+				Status:  http.StatusOK,
+				Resp: []byte(`<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Index of /mount/folder/</h1>
+    <table style="width:100%">
+        <thead>
+            <tr>
+                <th style="border-right:1px solid black;text-align:start">Entry</th>
+                <th style="border-right:1px solid black;text-align:start">Size</th>
+                <th style="border-right:1px solid black;text-align:start">Last modified</th>
+            </tr>
+        </thead>
+        <tbody style="border-top:1px solid black">
+          <tr>
+            <td style="border-right:1px solid black;padding: 0 5px"><a href="..">..</a></td>
+            <td style="border-right:1px solid black;padding: 0 5px"> - </td>
+            <td style="padding: 0 5px"> - </td>
+          </tr>
+          <tr>
+              <td style="border-right:1px solid black;padding: 0 5px"><a href="/mountfolder/file1">file1</a></td>
+              <td style="border-right:1px solid black;padding: 0 5px">300 B</td>
+              <td style="padding: 0 5px">1990-12-25 01:01:01.000000001 &#43;0000 UTC</td>
+          </tr>
+        </tbody>
+    </table>
+    <p>
+      <a href="/mount/folder/?max-keys=1">Previous page</a>
+      <a href="/mount/folder/?continuation-token=token3&amp;max-keys=1&amp;previous-token=&amp;previous-token=token2">Next page</a>
+    </p>
+  </body>
+</html>
+`),
+			},
+		},
+		{
+			name: "should limit listing to maximum number of entries allowed by target",
 			fields: fields{
 				s3Context: &s3clientTest{
 					ListErr: errors.New("test"),
@@ -624,6 +1978,12 @@ func Test_requestContext_Get(t *testing.T) {
 						Name:   "bucket1",
 						Prefix: "/",
 					},
+					Actions: &config.ActionsConfig{
+						GET: &config.GetActionConfig{
+							Enabled: true,
+							Config:  &config.GetActionConfigConfig{ListMaxKeys: 5},
+						},
+					},
 				},
 				tplConfig: &config.TemplateConfig{
 					FolderList: "../../../templates/folder-list.tpl",
@@ -637,26 +1997,34 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/",
+				input: &GetInput{RequestPath: "/folder/", MaxKeys: 10},
 			},
 			expectedHandleInternalServerErrorCalled: true,
 			expectedS3ClientListCalled:              true,
-			expectedS3ClientListInput:               "/folder/",
+			expectedS3ClientListInput:               &s3client.ListInput{Key: "/folder/", MaxKeys: 5},
 			expectedHTTPWriter:                      &respWriterTest{},
 		},
 		{
-			name: "should fail if list files and directories template failed because template not found",
+			name: "should be ok to list files and directories as JSON and ignore index document",
 			fields: fields{
 				s3Context: &s3clientTest{
-					ListResult: []*s3client.ListElementOutput{
-						{
-							Name:         "file1",
-							Type:         "FILE",
-							ETag:         "etag",
-							LastModified: fakeDate,
-							Size:         300,
-							Key:          "/folder/file1",
+					ListResult: &s3client.ListOutput{
+						Entries: []*s3client.ListElementOutput{
+							{
+								Name: "folder2/",
+								Type: "FOLDER",
+								Key:  "/folder/folder2/",
+							},
+							{
+								Name:         "file1",
+								Type:         "FILE",
+								ETag:         "etag",
+								LastModified: fakeDate,
+								Size:         300,
+								Key:          "/folder/file1",
+							},
 						},
+						NextContinuationToken: "token2",
 					},
 				},
 				targetCfg: &config.TargetConfig{
@@ -665,12 +2033,15 @@ func Test_requestContext_Get(t *testing.T) {
 						Name:   "bucket1",
 						Prefix: "/",
 					},
+					IndexDocument: "index.html",
 				},
 				tplConfig: &config.TemplateConfig{
-					FolderList: "fake/path",
+					FolderList: "../../../templates/folder-list.tpl",
 				},
 				mountPath: "/mount",
-				httpRW:    &respWriterTest{},
+				httpRW: &respWriterTest{
+					Headers: http.Header{},
+				},
 				errorHandlers: &ErrorHandlers{
 					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
 					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
@@ -678,27 +2049,78 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/",
+				input: &GetInput{RequestPath: "/folder/", JSONListing: true},
+			},
+			expectedS3ClientListCalled: true,
+			expectedS3ClientListInput:  &s3client.ListInput{Key: "/folder/", MaxKeys: 1000},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hJSON,
+				Status:  http.StatusOK,
+				Resp: []byte(`{"entries":[` +
+					`{"type":"FOLDER","etag":"","name":"folder2/","lastModified":"0001-01-01T00:00:00Z","size":0,"path":"/mountfolder/folder2/"},` +
+					`{"type":"FILE","etag":"etag","name":"file1","lastModified":"1990-12-25T01:01:01.000000001Z","size":300,"path":"/mountfolder/file1"}],` +
+					`"bucketName":"bucket1","name":"target","path":"/mount/folder/",` +
+					`"nextPageLink":"/mount/folder/?continuation-token=token2\u0026previous-token=","previousPageLink":""}`),
 			},
-			expectedHandleInternalServerErrorCalled: true,
-			expectedS3ClientListCalled:              true,
-			expectedS3ClientListInput:               "/folder/",
-			expectedHTTPWriter:                      &respWriterTest{},
 		},
 		{
-			name: "should be ok to list files and directories",
+			name: "should be ok to find and load index document",
+			fields: fields{
+				s3Context: &s3clientTest{
+					GetResult: &s3client.GetOutput{
+						Body:        &fakeIndexIoReadCloser,
+						ContentType: "text/html; charset=utf-8",
+					},
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+					IndexDocument: "index.html",
+				},
+				tplConfig: &config.TemplateConfig{
+					FolderList: "../../../templates/folder-list.tpl",
+				},
+				mountPath: "/mount",
+				httpRW: &respWriterTest{
+					Headers: http.Header{},
+				},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/"},
+			},
+			expectedS3ClientGetCalled: true,
+			expectedS3ClientGetInput:  &s3client.GetInput{Key: "/folder/index.html"},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: hFile,
+				Status:  http.StatusOK,
+				Resp:    []byte("fake-index.html-content"),
+			},
+		},
+		{
+			name: "should list files and directories when index document isn't found",
 			fields: fields{
 				s3Context: &s3clientTest{
-					ListResult: []*s3client.ListElementOutput{
-						{
-							Name:         "file1",
-							Type:         "FILE",
-							ETag:         "etag",
-							LastModified: fakeDate,
-							Size:         300,
-							Key:          "/folder/file1",
+					ListResult: &s3client.ListOutput{
+						Entries: []*s3client.ListElementOutput{
+							{
+								Name:         "file1",
+								Type:         "FILE",
+								ETag:         "etag",
+								LastModified: fakeDate,
+								Size:         300,
+								Key:          "/folder/file1",
+							},
 						},
 					},
+					GetErr: s3client.ErrNotFound,
 				},
 				targetCfg: &config.TargetConfig{
 					Name: "target",
@@ -706,6 +2128,7 @@ func Test_requestContext_Get(t *testing.T) {
 						Name:   "bucket1",
 						Prefix: "/",
 					},
+					IndexDocument: "index.html",
 				},
 				tplConfig: &config.TemplateConfig{
 					FolderList: "../../../templates/folder-list.tpl",
@@ -721,10 +2144,12 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/",
+				input: &GetInput{RequestPath: "/folder/"},
 			},
 			expectedS3ClientListCalled: true,
-			expectedS3ClientListInput:  "/folder/",
+			expectedS3ClientListInput:  &s3client.ListInput{Key: "/folder/", MaxKeys: 1000},
+			expectedS3ClientGetCalled:  true,
+			expectedS3ClientGetInput:   &s3client.GetInput{Key: "/folder/index.html"},
 			expectedHTTPWriter: &respWriterTest{
 				Headers: h,
 				Status:  http.StatusOK,
@@ -759,21 +2184,44 @@ func Test_requestContext_Get(t *testing.T) {
 			},
 		},
 		{
-			name: "should be ok to find and load index document",
+			name: "should fail to find and load index document with error",
 			fields: fields{
 				s3Context: &s3clientTest{
-					ListResult: []*s3client.ListElementOutput{
-						{
-							Name:         "index.html",
-							Type:         "FILE",
-							ETag:         "etag",
-							LastModified: fakeDate,
-							Size:         300,
-							Key:          "/folder/index.html",
-						},
+					GetErr: errors.New("test-error"),
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
 					},
+					IndexDocument: "index.html",
+				},
+				tplConfig: &config.TemplateConfig{
+					FolderList: "../../../templates/folder-list.tpl",
+				},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/"},
+			},
+			expectedS3ClientGetCalled:               true,
+			expectedS3ClientGetInput:                &s3client.GetInput{Key: "/folder/index.html"},
+			expectedHTTPWriter:                      &respWriterTest{},
+			expectedHandleInternalServerErrorCalled: true,
+		},
+		{
+			name: "should be ok to get file",
+			fields: fields{
+				s3Context: &s3clientTest{
 					GetResult: &s3client.GetOutput{
-						Body:        &fakeIndexIoReadCloser,
+						Body:        &fakeIndexIoReadCloser2,
 						ContentType: "text/html; charset=utf-8",
 					},
 				},
@@ -783,7 +2231,6 @@ func Test_requestContext_Get(t *testing.T) {
 						Name:   "bucket1",
 						Prefix: "/",
 					},
-					IndexDocument: "index.html",
 				},
 				tplConfig: &config.TemplateConfig{
 					FolderList: "../../../templates/folder-list.tpl",
@@ -799,32 +2246,20 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/",
+				input: &GetInput{RequestPath: "/folder/index.html"},
 			},
-			expectedS3ClientListCalled: true,
-			expectedS3ClientListInput:  "/folder/",
-			expectedS3ClientGetCalled:  true,
-			expectedS3ClientGetInput:   "/folder/index.html",
+			expectedS3ClientGetCalled: true,
+			expectedS3ClientGetInput:  &s3client.GetInput{Key: "/folder/index.html"},
 			expectedHTTPWriter: &respWriterTest{
-				Headers: h,
+				Headers: hFile,
 				Status:  http.StatusOK,
 				Resp:    []byte("fake-index.html-content"),
 			},
 		},
 		{
-			name: "should fail to find and load index document with not found error",
+			name: "should fail to get file when not found error is raised",
 			fields: fields{
 				s3Context: &s3clientTest{
-					ListResult: []*s3client.ListElementOutput{
-						{
-							Name:         "index.html",
-							Type:         "FILE",
-							ETag:         "etag",
-							LastModified: fakeDate,
-							Size:         300,
-							Key:          "/folder/index.html",
-						},
-					},
 					GetErr: s3client.ErrNotFound,
 				},
 				targetCfg: &config.TargetConfig{
@@ -833,7 +2268,6 @@ func Test_requestContext_Get(t *testing.T) {
 						Name:   "bucket1",
 						Prefix: "/",
 					},
-					IndexDocument: "index.html",
 				},
 				tplConfig: &config.TemplateConfig{
 					FolderList: "../../../templates/folder-list.tpl",
@@ -847,29 +2281,17 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/",
+				input: &GetInput{RequestPath: "/folder/index.html"},
 			},
-			expectedS3ClientListCalled:   true,
-			expectedS3ClientListInput:    "/folder/",
 			expectedS3ClientGetCalled:    true,
-			expectedS3ClientGetInput:     "/folder/index.html",
+			expectedS3ClientGetInput:     &s3client.GetInput{Key: "/folder/index.html"},
 			expectedHTTPWriter:           &respWriterTest{},
 			expectedHandleNotFoundCalled: true,
 		},
 		{
-			name: "should fail to find and load index document with error",
+			name: "should fail to get file when error is raised",
 			fields: fields{
 				s3Context: &s3clientTest{
-					ListResult: []*s3client.ListElementOutput{
-						{
-							Name:         "index.html",
-							Type:         "FILE",
-							ETag:         "etag",
-							LastModified: fakeDate,
-							Size:         300,
-							Key:          "/folder/index.html",
-						},
-					},
 					GetErr: errors.New("test-error"),
 				},
 				targetCfg: &config.TargetConfig{
@@ -878,7 +2300,6 @@ func Test_requestContext_Get(t *testing.T) {
 						Name:   "bucket1",
 						Prefix: "/",
 					},
-					IndexDocument: "index.html",
 				},
 				tplConfig: &config.TemplateConfig{
 					FolderList: "../../../templates/folder-list.tpl",
@@ -892,22 +2313,22 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/",
+				input: &GetInput{RequestPath: "/folder/index.html"},
 			},
-			expectedS3ClientListCalled:              true,
-			expectedS3ClientListInput:               "/folder/",
 			expectedS3ClientGetCalled:               true,
-			expectedS3ClientGetInput:                "/folder/index.html",
+			expectedS3ClientGetInput:                &s3client.GetInput{Key: "/folder/index.html"},
 			expectedHTTPWriter:                      &respWriterTest{},
 			expectedHandleInternalServerErrorCalled: true,
 		},
 		{
-			name: "should be ok to get file",
+			name: "should be ok to get a file range",
 			fields: fields{
 				s3Context: &s3clientTest{
 					GetResult: &s3client.GetOutput{
-						Body:        &fakeIndexIoReadCloser2,
-						ContentType: "text/html; charset=utf-8",
+						Body:          &fakeRangeIoReadCloser,
+						ContentType:   "text/html; charset=utf-8",
+						ContentLength: 4,
+						ContentRange:  "bytes 0-3/23",
 					},
 				},
 				targetCfg: &config.TargetConfig{
@@ -931,21 +2352,57 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/index.html",
+				input: &GetInput{RequestPath: "/folder/index.html", Range: "bytes=0-3", IfRange: "etag"},
 			},
 			expectedS3ClientGetCalled: true,
-			expectedS3ClientGetInput:  "/folder/index.html",
+			expectedS3ClientGetInput:  &s3client.GetInput{Key: "/folder/index.html", Range: "bytes=0-3", IfRange: "etag"},
 			expectedHTTPWriter: &respWriterTest{
-				Headers: h,
-				Status:  http.StatusOK,
-				Resp:    []byte("fake-index.html-content"),
+				Headers: hRange,
+				Status:  http.StatusPartialContent,
+				Resp:    []byte("fake"),
 			},
 		},
 		{
-			name: "should fail to get file when not found error is raised",
+			name: "should fail to get file when range is not satisfiable",
 			fields: fields{
 				s3Context: &s3clientTest{
-					GetErr: s3client.ErrNotFound,
+					GetErr:            s3client.ErrRangeNotSatisfiable,
+					GetHeadOnlyResult: &s3client.GetOutput{ContentLength: 14},
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+				},
+				tplConfig: &config.TemplateConfig{
+					FolderList: "../../../templates/folder-list.tpl",
+				},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{Headers: http.Header{}},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/index.html", Range: "bytes=100-200"},
+			},
+			expectedS3ClientGetCalled: true,
+			// Last request gets object size with a head request
+			expectedS3ClientGetInput: &s3client.GetInput{Key: "/folder/index.html", HeadOnly: true},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: http.Header{"Content-Range": []string{"bytes */14"}},
+				Status:  http.StatusRequestedRangeNotSatisfiable,
+			},
+		},
+		{
+			name: "should answer not modified when index document hasn't changed",
+			fields: fields{
+				s3Context: &s3clientTest{
+					GetErr: s3client.ErrNotModified,
 				},
 				targetCfg: &config.TargetConfig{
 					Name: "target",
@@ -953,6 +2410,7 @@ func Test_requestContext_Get(t *testing.T) {
 						Name:   "bucket1",
 						Prefix: "/",
 					},
+					IndexDocument: "index.html",
 				},
 				tplConfig: &config.TemplateConfig{
 					FolderList: "../../../templates/folder-list.tpl",
@@ -966,18 +2424,17 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/index.html",
+				input: &GetInput{RequestPath: "/folder/", IfNoneMatch: "etag"},
 			},
-			expectedS3ClientGetCalled:    true,
-			expectedS3ClientGetInput:     "/folder/index.html",
-			expectedHTTPWriter:           &respWriterTest{},
-			expectedHandleNotFoundCalled: true,
+			expectedS3ClientGetCalled: true,
+			expectedS3ClientGetInput:  &s3client.GetInput{Key: "/folder/index.html", IfNoneMatch: "etag"},
+			expectedHTTPWriter:        &respWriterTest{Status: http.StatusNotModified},
 		},
 		{
-			name: "should fail to get file when error is raised",
+			name: "should answer not modified when file hasn't changed",
 			fields: fields{
 				s3Context: &s3clientTest{
-					GetErr: errors.New("test-error"),
+					GetErr: s3client.ErrNotModified,
 				},
 				targetCfg: &config.TargetConfig{
 					Name: "target",
@@ -998,12 +2455,42 @@ func Test_requestContext_Get(t *testing.T) {
 				},
 			},
 			args: args{
-				requestPath: "/folder/index.html",
+				input: &GetInput{RequestPath: "/folder/index.html", IfNoneMatch: "etag", IfModifiedSince: &fakeDate},
 			},
-			expectedS3ClientGetCalled:               true,
-			expectedS3ClientGetInput:                "/folder/index.html",
-			expectedHTTPWriter:                      &respWriterTest{},
-			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientGetCalled: true,
+			expectedS3ClientGetInput:  &s3client.GetInput{Key: "/folder/index.html", IfNoneMatch: "etag", IfModifiedSince: &fakeDate},
+			expectedHTTPWriter:        &respWriterTest{Status: http.StatusNotModified},
+		},
+		{
+			name: "should answer precondition failed when file doesn't match conditions",
+			fields: fields{
+				s3Context: &s3clientTest{
+					GetErr: s3client.ErrPreconditionFailed,
+				},
+				targetCfg: &config.TargetConfig{
+					Name: "target",
+					Bucket: &config.BucketConfig{
+						Name:   "bucket1",
+						Prefix: "/",
+					},
+				},
+				tplConfig: &config.TemplateConfig{
+					FolderList: "../../../templates/folder-list.tpl",
+				},
+				mountPath: "/mount",
+				httpRW:    &respWriterTest{},
+				errorHandlers: &ErrorHandlers{
+					HandleForbiddenWithTemplate:           handleForbiddenWithTemplate,
+					HandleNotFoundWithTemplate:            handleNotFoundWithTemplate,
+					HandleInternalServerErrorWithTemplate: handleInternalServerErrorWithTemplate,
+				},
+			},
+			args: args{
+				input: &GetInput{RequestPath: "/folder/index.html", IfMatch: "etag", IfUnmodifiedSince: &fakeDate},
+			},
+			expectedS3ClientGetCalled: true,
+			expectedS3ClientGetInput:  &s3client.GetInput{Key: "/folder/index.html", IfMatch: "etag", IfUnmodifiedSince: &fakeDate},
+			expectedHTTPWriter:        &respWriterTest{Status: http.StatusPreconditionFailed},
 		},
 	}
 	for _, tt := range tests {
@@ -1020,7 +2507,7 @@ func Test_requestContext_Get(t *testing.T) {
 				httpRW:         tt.fields.httpRW,
 				errorsHandlers: tt.fields.errorHandlers,
 			}
-			rctx.Get(tt.args.requestPath)
+			rctx.Get(tt.args.input)
 			if handleNotFoundCalled != tt.expectedHandleNotFoundCalled {
 				t.Errorf("requestContext.Get() => handleNotFoundCalled = %+v, want %+v", handleNotFoundCalled, tt.expectedHandleNotFoundCalled)
 			}
@@ -1042,6 +2529,12 @@ func Test_requestContext_Get(t *testing.T) {
 			if !reflect.DeepEqual(tt.expectedS3ClientGetInput, tt.fields.s3Context.(*s3clientTest).GetInput) {
 				t.Errorf("requestContext.Get() => s3client.GetInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).GetInput, tt.expectedS3ClientGetInput)
 			}
+			if tt.expectedS3ClientPresignGetCalled != tt.fields.s3Context.(*s3clientTest).PresignGetCalled {
+				t.Errorf("requestContext.Get() => s3client.PresignGetCalled = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).PresignGetCalled, tt.expectedS3ClientPresignGetCalled)
+			}
+			if !reflect.DeepEqual(tt.expectedS3ClientPresignGetInput, tt.fields.s3Context.(*s3clientTest).PresignGetInput) {
+				t.Errorf("requestContext.Get() => s3client.PresignGetInput = %+v, want %+v", tt.fields.s3Context.(*s3clientTest).PresignGetInput, tt.expectedS3ClientPresignGetInput)
+			}
 			if !reflect.DeepEqual(tt.expectedHTTPWriter, tt.fields.httpRW) {
 				t.Errorf("requestContext.Get() => httpWriter = %+v, want %+v", tt.fields.httpRW, tt.expectedHTTPWriter)
 			}
@@ -1049,6 +2542,121 @@ func Test_requestContext_Get(t *testing.T) {
 	}
 }
 
+func Test_requestContext_Get_HeadOnly(t *testing.T) {
+	newObject := func() *s3client.GetOutput {
+		// Head only objects have an empty body
+		body := ioutil.NopCloser(strings.NewReader(""))
+		return &s3client.GetOutput{Body: &body, ContentType: "text/html", ContentLength: 23, ETag: "etag"}
+	}
+	newTarget := func(getCfg *config.GetActionConfigConfig, indexDocument string) *config.TargetConfig {
+		return &config.TargetConfig{
+			Name:          "target",
+			Bucket:        &config.BucketConfig{Prefix: "/"},
+			IndexDocument: indexDocument,
+			Actions:       &config.ActionsConfig{GET: &config.GetActionConfig{Enabled: true, Config: getCfg}},
+		}
+	}
+	tests := []struct {
+		name            string
+		targetCfg       *config.TargetConfig
+		objects         map[string]*s3client.GetOutput
+		listAllResult   *s3client.ListAllObjectsOutput
+		input           *GetInput
+		expectedStatus  int
+		expectedHeaders map[string]string
+		expectedGetKeys []string
+	}{
+		{
+			name:            "should answer with file headers",
+			targetCfg:       newTarget(nil, ""),
+			objects:         map[string]*s3client.GetOutput{"/folder/file.html": newObject()},
+			input:           &GetInput{RequestPath: "/folder/file.html", HeadOnly: true},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Content-Type": "text/html", "Content-Length": "23", "ETag": "etag"},
+			expectedGetKeys: []string{"/folder/file.html"},
+		},
+		{
+			name:            "should answer with index document headers on folder",
+			targetCfg:       newTarget(nil, "index.html"),
+			objects:         map[string]*s3client.GetOutput{"/folder/index.html": newObject()},
+			input:           &GetInput{RequestPath: "/folder/", HeadOnly: true},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Content-Type": "text/html", "Content-Length": "23", "ETag": "etag"},
+			expectedGetKeys: []string{"/folder/index.html"},
+		},
+		{
+			name: "should answer with website fallback document headers",
+			targetCfg: newTarget(&config.GetActionConfigConfig{
+				Website: &config.WebsiteConfig{Enabled: true, FallbackDocument: "index.html"},
+			}, ""),
+			objects:         map[string]*s3client.GetOutput{"/index.html": newObject()},
+			input:           &GetInput{RequestPath: "/app/route", HeadOnly: true},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Content-Type": "text/html", "Content-Length": "23"},
+			expectedGetKeys: []string{"/app/route", "/index.html"},
+		},
+		{
+			name: "should answer with precompressed variant headers",
+			targetCfg: newTarget(&config.GetActionConfigConfig{
+				Precompressed: &config.PrecompressedConfig{Enabled: true, Encodings: []string{"gzip"}},
+			}, ""),
+			objects:         map[string]*s3client.GetOutput{"/file.html": newObject(), "/file.html.gz": newObject()},
+			input:           &GetInput{RequestPath: "/file.html", AcceptEncoding: "gzip", HeadOnly: true},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Content-Encoding": "gzip", "Vary": "Accept-Encoding"},
+			expectedGetKeys: []string{"/file.html.gz"},
+		},
+		{
+			name: "should answer with archive headers without getting objects",
+			targetCfg: newTarget(&config.GetActionConfigConfig{
+				Archive: &config.ArchiveConfig{Enabled: true},
+			}, ""),
+			listAllResult: &s3client.ListAllObjectsOutput{Entries: []*s3client.ListElementOutput{
+				{Type: s3client.FileType, Name: "file.txt", Key: "/folder/file.txt", Size: 7},
+			}},
+			input:           &GetInput{RequestPath: "/folder/", Archive: ArchiveZipFormat, HeadOnly: true},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Content-Type": "application/zip", "Content-Disposition": "attachment; filename=\"folder.zip\""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3ctx := &s3clientTest{GetResultsByKey: tt.objects, ListAllResult: tt.listAllResult}
+			if s3ctx.GetResultsByKey == nil {
+				s3ctx.GetResultsByKey = map[string]*s3client.GetOutput{}
+			}
+			recorder := httptest.NewRecorder()
+			rctx := &requestContext{
+				s3Context:      s3ctx,
+				logger:         log.NewLogger(),
+				targetCfg:      tt.targetCfg,
+				tplConfig:      &config.TemplateConfig{},
+				mountPath:      "/mount/",
+				httpRW:         recorder,
+				errorsHandlers: &ErrorHandlers{},
+			}
+			rctx.Get(tt.input)
+			if !reflect.DeepEqual(s3ctx.GetKeys, tt.expectedGetKeys) {
+				t.Errorf("requestContext.Get() => s3client.GetKeys = %+v, want %+v", s3ctx.GetKeys, tt.expectedGetKeys)
+			}
+			if s3ctx.GetCalled && !s3ctx.GetInput.HeadOnly {
+				t.Errorf("requestContext.Get() => s3client.GetInput.HeadOnly = false, want true")
+			}
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("requestContext.Get() => status = %d, want %d", recorder.Code, tt.expectedStatus)
+			}
+			if recorder.Body.Len() != 0 {
+				t.Errorf("requestContext.Get() => body = %s, want empty", recorder.Body.String())
+			}
+			for k, v := range tt.expectedHeaders {
+				if got := recorder.Header().Get(k); got != v {
+					t.Errorf("requestContext.Get() => header %s = %s, want %s", k, got, v)
+				}
+			}
+		})
+	}
+}
+
 func Test_requestContext_HandleInternalServerError(t *testing.T) {
 	err := errors.New("fake")
 	thrownErr := errors.New("fake err")
@@ -2289,3 +3897,27 @@ func Test_requestContext_HandleUnauthorized(t *testing.T) {
 		})
 	}
 }
+
+func Test_mergePutErrorStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		status  int
+		want    int
+	}{
+		{name: "First failure", current: 0, status: http.StatusForbidden, want: http.StatusForbidden},
+		{name: "Same client errors", current: http.StatusForbidden, status: http.StatusForbidden, want: http.StatusForbidden},
+		{name: "Different client errors", current: http.StatusForbidden, status: http.StatusRequestEntityTooLarge, want: http.StatusBadRequest},
+		{name: "Backend error after client error", current: http.StatusBadRequest, status: http.StatusInternalServerError, want: http.StatusInternalServerError},
+		{name: "Client error after backend error", current: http.StatusInternalServerError, status: http.StatusBadRequest, want: http.StatusInternalServerError},
+		{name: "Same mirror bucket errors", current: http.StatusBadGateway, status: http.StatusBadGateway, want: http.StatusBadGateway},
+		{name: "Mirror bucket error after client error", current: http.StatusBadRequest, status: http.StatusBadGateway, want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergePutErrorStatus(tt.current, tt.status); got != tt.want {
+				t.Errorf("mergePutErrorStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
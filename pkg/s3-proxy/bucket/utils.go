@@ -2,17 +2,33 @@ package bucket
 
 import (
 	"html/template"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"path"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"github.com/gobwas/glob"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
 )
 
 func setHeadersFromObjectOutput(w http.ResponseWriter, obj *s3client.GetOutput) {
+	setObjectHeaders(w, obj)
+
+	httpStatus := determineHTTPStatus(obj)
+	w.WriteHeader(httpStatus)
+}
+
+// setObjectHeaders will set object headers without writing status code
+func setObjectHeaders(w http.ResponseWriter, obj *s3client.GetOutput) {
+	// Range requests are supported on objects
+	w.Header().Set("Accept-Ranges", "bytes")
 	setStrHeader(w, "Cache-Control", obj.CacheControl)
 	setStrHeader(w, "Expires", obj.Expires)
 	setStrHeader(w, "Content-Disposition", obj.ContentDisposition)
@@ -23,9 +39,6 @@ func setHeadersFromObjectOutput(w http.ResponseWriter, obj *s3client.GetOutput)
 	setStrHeader(w, "Content-Type", obj.ContentType)
 	setStrHeader(w, "ETag", obj.ETag)
 	setTimeHeader(w, "Last-Modified", obj.LastModified)
-
-	httpStatus := determineHTTPStatus(obj)
-	w.WriteHeader(httpStatus)
 }
 
 func determineHTTPStatus(obj *s3client.GetOutput) int {
@@ -93,3 +106,127 @@ func s3ProxyFuncMap() template.FuncMap {
 	// Return result
 	return template.FuncMap(funcMap)
 }
+
+// generatePageLinks will generate next and previous folder listing page links
+func generatePageLinks(basePath string, input *GetInput, nextContinuationToken string) (nextLink, previousLink string) {
+	// Check if a next page exists
+	if nextContinuationToken != "" {
+		qs := url.Values{}
+		qs.Set(ContinuationTokenQueryParam, nextContinuationToken)
+		// Keep asked number of entries
+		if input.MaxKeys > 0 {
+			qs.Set(MaxKeysQueryParam, strconv.FormatInt(input.MaxKeys, 10))
+		}
+		// Add current page token at the end of previous tokens
+		previousTokens := make([]string, 0, len(input.PreviousContinuationTokens)+1)
+		previousTokens = append(previousTokens, input.PreviousContinuationTokens...)
+		qs[PreviousContinuationTokenQueryParam] = append(previousTokens, input.ContinuationToken)
+		// Build link
+		nextLink = basePath + "?" + qs.Encode()
+	}
+
+	// Check if a previous page is known
+	if len(input.PreviousContinuationTokens) > 0 {
+		qs := url.Values{}
+		// Get previous page token (empty for first page)
+		lastIndex := len(input.PreviousContinuationTokens) - 1
+		if input.PreviousContinuationTokens[lastIndex] != "" {
+			qs.Set(ContinuationTokenQueryParam, input.PreviousContinuationTokens[lastIndex])
+		}
+		// Keep asked number of entries
+		if input.MaxKeys > 0 {
+			qs.Set(MaxKeysQueryParam, strconv.FormatInt(input.MaxKeys, 10))
+		}
+		// Keep older tokens
+		if lastIndex > 0 {
+			qs[PreviousContinuationTokenQueryParam] = input.PreviousContinuationTokens[:lastIndex]
+		}
+		// Build link
+		previousLink = basePath
+		if len(qs) > 0 {
+			previousLink += "?" + qs.Encode()
+		}
+	}
+
+	return nextLink, previousLink
+}
+
+// defaultContentType Content type used for upload policy when none is given
+const defaultContentType = "application/octet-stream"
+
+// checkPutPolicy will check that file respects upload policy from configuration
+func checkPutPolicy(putCfg *config.PutActionConfigConfig, key string, file *PutFileInput) error {
+	// Check size if known
+	if putCfg.MaxSize > 0 && file.Size > putCfg.MaxSize {
+		return ErrPutTooLarge
+	}
+	// Get media type without parameters
+	contentType, _, err := mime.ParseMediaType(file.ContentType)
+	if err != nil {
+		contentType = defaultContentType
+	}
+	// Check denied content types
+	if matchOneGlob(putCfg.DeniedContentTypesGlobs, contentType) {
+		return ErrPutContentTypeNotAllowed
+	}
+	// Check allowed content types
+	if len(putCfg.AllowedContentTypesGlobs) > 0 && !matchOneGlob(putCfg.AllowedContentTypesGlobs, contentType) {
+		return ErrPutContentTypeNotAllowed
+	}
+	// Check allowed filenames
+	return checkFilenamePolicy(putCfg, key)
+}
+
+// checkFilenamePolicy will check that key file name respects allowed file names of PUT action configuration
+func checkFilenamePolicy(putCfg *config.PutActionConfigConfig, key string) error {
+	// Check if allowed filenames are configured
+	if len(putCfg.AllowedFilenamesGlobs) == 0 {
+		return nil
+	}
+
+	if !matchOneGlob(putCfg.AllowedFilenamesGlobs, path.Base(key)) {
+		return ErrPutFilenameNotAllowed
+	}
+
+	return nil
+}
+
+// matchOneGlob will check if value matches one of glob patterns compiled at configuration load
+func matchOneGlob(globs []glob.Glob, value string) bool {
+	for _, g := range globs {
+		// Check if value match glob pattern
+		if g.Match(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maxSizeReader is a reader that will fail when more than remaining bytes are read
+type maxSizeReader struct {
+	reader    io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (r *maxSizeReader) Read(p []byte) (int, error) {
+	// Read one more byte than remaining to detect too large content
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.reader.Read(p)
+	// Check if content is too large
+	if int64(n) > r.remaining {
+		n = int(r.remaining)
+		r.remaining = 0
+		r.exceeded = true
+
+		return n, ErrPutTooLarge
+	}
+
+	r.remaining -= int64(n)
+
+	return n, err
+}
@@ -3,11 +3,15 @@
 package bucket
 
 import (
+	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gobwas/glob"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
 )
 
@@ -15,6 +19,7 @@ func Test_setHeadersFromObjectOutput(t *testing.T) {
 	// Tests data
 	now := time.Now()
 	headerFullInput := http.Header{}
+	headerFullInput.Add("Accept-Ranges", "bytes")
 	headerFullInput.Add("Cache-Control", "cachecontrol")
 	headerFullInput.Add("Expires", "expires")
 	headerFullInput.Add("Content-Disposition", "contentdisposition")
@@ -26,6 +31,7 @@ func Test_setHeadersFromObjectOutput(t *testing.T) {
 	headerFullInput.Add("ETag", "etag")
 	headerFullInput.Add("Last-Modified", now.UTC().Format(http.TimeFormat))
 	headerPartialInput := http.Header{}
+	headerPartialInput.Add("Accept-Ranges", "bytes")
 	headerPartialInput.Add("Cache-Control", "cachecontrol")
 	headerPartialInput.Add("Expires", "expires")
 	headerPartialInput.Add("Content-Disposition", "contentdisposition")
@@ -56,7 +62,7 @@ func Test_setHeadersFromObjectOutput(t *testing.T) {
 				obj: &s3client.GetOutput{},
 			},
 			expected: respWriterTest{
-				Headers: http.Header{},
+				Headers: http.Header{"Accept-Ranges": []string{"bytes"}},
 				Status:  200,
 			},
 		},
@@ -121,3 +127,200 @@ func Test_setHeadersFromObjectOutput(t *testing.T) {
 		})
 	}
 }
+
+func Test_generatePageLinks(t *testing.T) {
+	type args struct {
+		basePath              string
+		input                 *GetInput
+		nextContinuationToken string
+	}
+	tests := []struct {
+		name             string
+		args             args
+		wantNextLink     string
+		wantPreviousLink string
+	}{
+		{
+			name: "No links when only one page exists",
+			args: args{
+				basePath: "/mount/folder/",
+				input:    &GetInput{},
+			},
+			wantNextLink:     "",
+			wantPreviousLink: "",
+		},
+		{
+			name: "Only next link on first page",
+			args: args{
+				basePath:              "/mount/folder/",
+				input:                 &GetInput{},
+				nextContinuationToken: "token2",
+			},
+			wantNextLink:     "/mount/folder/?continuation-token=token2&previous-token=",
+			wantPreviousLink: "",
+		},
+		{
+			name: "Next and previous links on second page with max keys",
+			args: args{
+				basePath: "/mount/folder/",
+				input: &GetInput{
+					ContinuationToken:          "token2",
+					MaxKeys:                    10,
+					PreviousContinuationTokens: []string{""},
+				},
+				nextContinuationToken: "token3",
+			},
+			wantNextLink:     "/mount/folder/?continuation-token=token3&max-keys=10&previous-token=&previous-token=token2",
+			wantPreviousLink: "/mount/folder/?max-keys=10",
+		},
+		{
+			name: "Only previous link on last page",
+			args: args{
+				basePath: "/mount/folder/",
+				input: &GetInput{
+					ContinuationToken:          "token3",
+					PreviousContinuationTokens: []string{"", "token2"},
+				},
+			},
+			wantNextLink:     "",
+			wantPreviousLink: "/mount/folder/?continuation-token=token2&previous-token=",
+		},
+		{
+			name: "Previous link to first page",
+			args: args{
+				basePath: "/mount/folder/",
+				input: &GetInput{
+					ContinuationToken:          "token2",
+					PreviousContinuationTokens: []string{""},
+				},
+			},
+			wantNextLink:     "",
+			wantPreviousLink: "/mount/folder/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotNextLink, gotPreviousLink := generatePageLinks(tt.args.basePath, tt.args.input, tt.args.nextContinuationToken)
+			if gotNextLink != tt.wantNextLink {
+				t.Errorf("generatePageLinks() gotNextLink = %v, want %v", gotNextLink, tt.wantNextLink)
+			}
+			if gotPreviousLink != tt.wantPreviousLink {
+				t.Errorf("generatePageLinks() gotPreviousLink = %v, want %v", gotPreviousLink, tt.wantPreviousLink)
+			}
+		})
+	}
+}
+
+func Test_checkPutPolicy(t *testing.T) {
+	type args struct {
+		putCfg *config.PutActionConfigConfig
+		key    string
+		file   *PutFileInput
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{
+			name: "empty policy",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{},
+				key:    "/dir/file.exe",
+				file:   &PutFileInput{Size: 1000},
+			},
+		},
+		{
+			name: "file too large",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{MaxSize: 10},
+				key:    "/dir/file.pdf",
+				file:   &PutFileInput{Size: 11},
+			},
+			wantErr: ErrPutTooLarge,
+		},
+		{
+			name: "file with max size",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{MaxSize: 10},
+				key:    "/dir/file.pdf",
+				file:   &PutFileInput{Size: 10},
+			},
+		},
+		{
+			name: "denied content type",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{DeniedContentTypes: []string{"application/x-msdownload"}, DeniedContentTypesGlobs: []glob.Glob{glob.MustCompile("application/x-msdownload")}},
+				key:    "/dir/file.exe",
+				file:   &PutFileInput{ContentType: "application/x-msdownload"},
+			},
+			wantErr: ErrPutContentTypeNotAllowed,
+		},
+		{
+			name: "allowed content type with glob and parameters",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{AllowedContentTypes: []string{"text/*"}, AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("text/*")}},
+				key:    "/dir/file.txt",
+				file:   &PutFileInput{ContentType: "text/plain; charset=utf-8"},
+			},
+		},
+		{
+			name: "content type not in allowed list",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{AllowedContentTypes: []string{"application/pdf"}, AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("application/pdf")}},
+				key:    "/dir/file.txt",
+				file:   &PutFileInput{ContentType: "text/plain"},
+			},
+			wantErr: ErrPutContentTypeNotAllowed,
+		},
+		{
+			name: "missing content type is considered as binary",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{DeniedContentTypes: []string{"application/octet-stream"}, DeniedContentTypesGlobs: []glob.Glob{glob.MustCompile("application/octet-stream")}},
+				key:    "/dir/file",
+				file:   &PutFileInput{},
+			},
+			wantErr: ErrPutContentTypeNotAllowed,
+		},
+		{
+			name: "allowed filename",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{AllowedFilenames: []string{"*.pdf", "*.txt"}, AllowedFilenamesGlobs: []glob.Glob{glob.MustCompile("*.pdf"), glob.MustCompile("*.txt")}},
+				key:    "/dir/file.txt",
+				file:   &PutFileInput{},
+			},
+		},
+		{
+			name: "filename not allowed",
+			args: args{
+				putCfg: &config.PutActionConfigConfig{AllowedFilenames: []string{"*.pdf"}, AllowedFilenamesGlobs: []glob.Glob{glob.MustCompile("*.pdf")}},
+				key:    "/dir.pdf/file.exe",
+				file:   &PutFileInput{},
+			},
+			wantErr: ErrPutFilenameNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPutPolicy(tt.args.putCfg, tt.args.key, tt.args.file)
+			if err != tt.wantErr {
+				t.Errorf("checkPutPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_maxSizeReader(t *testing.T) {
+	// Read content under limit
+	r := &maxSizeReader{reader: strings.NewReader("0123456789"), remaining: 10}
+	content, err := ioutil.ReadAll(r)
+	if err != nil || string(content) != "0123456789" || r.exceeded {
+		t.Errorf("maxSizeReader.Read() content = %s, err = %v, exceeded = %v", content, err, r.exceeded)
+	}
+	// Read content over limit
+	r = &maxSizeReader{reader: strings.NewReader("0123456789"), remaining: 5}
+	content, err = ioutil.ReadAll(r)
+	if err != ErrPutTooLarge || string(content) != "01234" || !r.exceeded {
+		t.Errorf("maxSizeReader.Read() content = %s, err = %v, exceeded = %v", content, err, r.exceeded)
+	}
+}
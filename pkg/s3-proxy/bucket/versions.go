@@ -0,0 +1,117 @@
+package bucket
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
+)
+
+// ErrVersionNotFile will be raised when end user is asking versions or a version on a folder path
+var ErrVersionNotFile = errors.New("versions can only be asked on files")
+
+// VersionEntry File version for internal use (template and JSON)
+type VersionEntry struct {
+	VersionID    string    `json:"versionId"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+	Size         int64     `json:"size"`
+	IsLatest     bool      `json:"isLatest"`
+	DeleteMarker bool      `json:"deleteMarker"`
+	// Path is the link to get this version (empty for delete markers)
+	Path string `json:"path"`
+}
+
+// fileVersionsData File versions data for templating and JSON
+type fileVersionsData struct {
+	Versions   []*VersionEntry `json:"versions"`
+	BucketName string          `json:"bucketName"`
+	Name       string          `json:"name"`
+	Path       string          `json:"path"`
+}
+
+// manageGetVersions will answer with all versions of file key
+func (rctx *requestContext) manageGetVersions(key string, input *GetInput) {
+	requestPath := input.RequestPath
+	// Check that request path is a file
+	if strings.HasSuffix(requestPath, "/") || requestPath == "" {
+		rctx.logger.Error(ErrVersionNotFile)
+		rctx.HandleBadRequest(ErrVersionNotFile, requestPath)
+		// Stop
+		return
+	}
+	// List versions
+	versions, err := rctx.s3Context.ListObjectVersions(key)
+	if err != nil {
+		rctx.logger.Error(err)
+		rctx.HandleInternalServerError(err, requestPath)
+		// Stop
+		return
+	}
+	// Check if file exists or have existed
+	if len(versions) == 0 {
+		rctx.HandleNotFound(requestPath)
+		// Stop
+		return
+	}
+
+	// Create file versions data
+	data := &fileVersionsData{
+		Versions:   make([]*VersionEntry, 0, len(versions)),
+		BucketName: rctx.targetCfg.Bucket.Name,
+		Name:       rctx.targetCfg.Name,
+		Path:       rctx.mountPath + requestPath,
+	}
+	for _, version := range versions {
+		entry := &VersionEntry{
+			VersionID:    version.VersionID,
+			ETag:         version.ETag,
+			LastModified: version.LastModified,
+			Size:         version.Size,
+			IsLatest:     version.IsLatest,
+			DeleteMarker: version.DeleteMarker,
+		}
+		// Delete markers cannot be downloaded
+		if !version.DeleteMarker {
+			entry.Path = data.Path + "?" + VersionIDQueryParam + "=" + url.QueryEscape(version.VersionID)
+		}
+
+		data.Versions = append(data.Versions, entry)
+	}
+
+	// Check if JSON is asked
+	if input.JSONListing {
+		rctx.writeJSON(data, http.StatusOK, requestPath)
+		// Stop
+		return
+	}
+
+	// Get per target template if declared
+	var targetTpl *config.TargetTemplateConfigItem
+	if rctx.targetCfg.Templates != nil {
+		targetTpl = rctx.targetCfg.Templates.FileVersions
+	}
+	// Answer with template
+	rctx.writeTemplate(targetTpl, rctx.tplConfig.FileVersions, data, requestPath)
+}
+
+// checkVersionExists will check that file version exists
+func (rctx *requestContext) checkVersionExists(key, versionID string) error {
+	// List versions
+	versions, err := rctx.s3Context.ListObjectVersions(key)
+	if err != nil {
+		return err
+	}
+	// Search version
+	for _, version := range versions {
+		if version.VersionID == versionID {
+			return nil
+		}
+	}
+
+	return s3client.ErrNotFound
+}
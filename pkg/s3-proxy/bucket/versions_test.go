@@ -0,0 +1,121 @@
+// +build unit
+
+package bucket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
+)
+
+func Test_requestContext_manageGetVersions(t *testing.T) {
+	handleNotFoundCalled := false
+	handleInternalServerErrorCalled := false
+	handleBadRequestCalled := false
+	errorHandlers := &ErrorHandlers{
+		HandleNotFoundWithTemplate: func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
+			handleNotFoundCalled = true
+		},
+		HandleInternalServerErrorWithTemplate: func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+			handleInternalServerErrorCalled = true
+		},
+		HandleBadRequestWithTemplate: func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+			handleBadRequestCalled = true
+		},
+	}
+	lastModified := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	versions := []*s3client.ObjectVersionOutput{
+		{VersionID: "v3", LastModified: lastModified, IsLatest: true, DeleteMarker: true},
+		{VersionID: "v2+", ETag: "etag", Size: 7, LastModified: lastModified},
+	}
+	tests := []struct {
+		name                                    string
+		s3Context                               *s3clientTest
+		input                                   *GetInput
+		expectedHandleNotFoundCalled            bool
+		expectedHandleInternalServerErrorCalled bool
+		expectedHandleBadRequestCalled          bool
+		expectedS3ClientListVersionsInput       string
+		expectedBody                            string
+	}{
+		{
+			name:                           "should fail when request path is a folder",
+			s3Context:                      &s3clientTest{},
+			input:                          &GetInput{RequestPath: "/folder/", Versions: true},
+			expectedHandleBadRequestCalled: true,
+		},
+		{
+			name:                                    "should fail when list fails",
+			s3Context:                               &s3clientTest{ListVersionsErr: errors.New("test")},
+			input:                                   &GetInput{RequestPath: "/folder/file", Versions: true},
+			expectedHandleInternalServerErrorCalled: true,
+			expectedS3ClientListVersionsInput:       "/folder/file",
+		},
+		{
+			name:                              "should answer not found when file doesn't have any version",
+			s3Context:                         &s3clientTest{ListVersionsResult: []*s3client.ObjectVersionOutput{}},
+			input:                             &GetInput{RequestPath: "/folder/file", Versions: true},
+			expectedHandleNotFoundCalled:      true,
+			expectedS3ClientListVersionsInput: "/folder/file",
+		},
+		{
+			name:                              "should answer versions in JSON",
+			s3Context:                         &s3clientTest{ListVersionsResult: versions},
+			input:                             &GetInput{RequestPath: "/folder/file", Versions: true, JSONListing: true},
+			expectedS3ClientListVersionsInput: "/folder/file",
+			expectedBody: `{"versions":[` +
+				`{"versionId":"v3","etag":"","lastModified":"2020-06-01T10:00:00Z","size":0,"isLatest":true,"deleteMarker":true,"path":""},` +
+				`{"versionId":"v2+","etag":"etag","lastModified":"2020-06-01T10:00:00Z","size":7,"isLatest":false,"deleteMarker":false,"path":"/mount/folder/file?versionId=v2%2B"}` +
+				`],"bucketName":"bucket","name":"target","path":"/mount/folder/file"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handleNotFoundCalled = false
+			handleInternalServerErrorCalled = false
+			handleBadRequestCalled = false
+			recorder := httptest.NewRecorder()
+			rctx := &requestContext{
+				s3Context: tt.s3Context,
+				logger:    log.NewLogger(),
+				targetCfg: &config.TargetConfig{
+					Name:   "target",
+					Bucket: &config.BucketConfig{Name: "bucket", Prefix: "/"},
+				},
+				tplConfig:      &config.TemplateConfig{},
+				mountPath:      "/mount",
+				httpRW:         recorder,
+				errorsHandlers: errorHandlers,
+			}
+			rctx.Get(tt.input)
+			if handleNotFoundCalled != tt.expectedHandleNotFoundCalled {
+				t.Errorf("requestContext.Get() => handleNotFoundCalled = %+v, want %+v", handleNotFoundCalled, tt.expectedHandleNotFoundCalled)
+			}
+			if handleInternalServerErrorCalled != tt.expectedHandleInternalServerErrorCalled {
+				t.Errorf("requestContext.Get() => handleInternalServerErrorCalled = %+v, want %+v", handleInternalServerErrorCalled, tt.expectedHandleInternalServerErrorCalled)
+			}
+			if handleBadRequestCalled != tt.expectedHandleBadRequestCalled {
+				t.Errorf("requestContext.Get() => handleBadRequestCalled = %+v, want %+v", handleBadRequestCalled, tt.expectedHandleBadRequestCalled)
+			}
+			if tt.expectedS3ClientListVersionsInput != tt.s3Context.ListVersionsInput {
+				t.Errorf("requestContext.Get() => s3client.ListVersionsInput = %+v, want %+v", tt.s3Context.ListVersionsInput, tt.expectedS3ClientListVersionsInput)
+			}
+			// Check body when one is expected
+			if tt.expectedBody == "" {
+				return
+			}
+			if recorder.Code != http.StatusOK {
+				t.Errorf("requestContext.Get() => status = %d, want %d", recorder.Code, http.StatusOK)
+			}
+			if got := recorder.Body.String(); got != tt.expectedBody {
+				t.Errorf("requestContext.Get() => body = %s, want %s", got, tt.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package bucket
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
+)
+
+// manageGetWebsite will answer with file in static website mode
+func (rctx *requestContext) manageGetWebsite(key string, input *GetInput, websiteCfg *config.WebsiteConfig) {
+	requestPath := input.RequestPath
+	// Check if request path is a folder
+	if strings.HasSuffix(requestPath, "/") || requestPath == "" {
+		// Folder listing isn't available in website mode
+		if rctx.targetCfg.IndexDocument == "" {
+			rctx.manageWebsiteNotFound(input, websiteCfg)
+			// Stop
+			return
+		}
+		// Answer with index document
+		key += rctx.targetCfg.IndexDocument
+	}
+
+	// Get object case
+	err := rctx.streamWebsiteFile(&s3client.GetInput{
+		Key:               key,
+		Range:             input.Range,
+		IfRange:           input.IfRange,
+		IfMatch:           input.IfMatch,
+		IfNoneMatch:       input.IfNoneMatch,
+		IfModifiedSince:   input.IfModifiedSince,
+		IfUnmodifiedSince: input.IfUnmodifiedSince,
+		VersionID:         input.VersionID,
+		HeadOnly:          input.HeadOnly,
+	}, input.AcceptEncoding, 0)
+	// Check if object exists
+	if err == s3client.ErrNotFound {
+		rctx.manageWebsiteNotFound(input, websiteCfg)
+		// Stop
+		return
+	}
+
+	if err != nil {
+		rctx.manageStreamFileError(err, requestPath)
+		// Stop
+		return
+	}
+}
+
+// manageWebsiteNotFound will answer with fallback document, nearest error document or not found template
+func (rctx *requestContext) manageWebsiteNotFound(input *GetInput, websiteCfg *config.WebsiteConfig) {
+	requestPath := input.RequestPath
+	// Check if fallback document is configured (single page applications)
+	if websiteCfg.FallbackDocument != "" {
+		err := rctx.streamWebsiteFile(&s3client.GetInput{
+			Key:      rctx.generateStartKey(websiteCfg.FallbackDocument),
+			HeadOnly: input.HeadOnly,
+		}, input.AcceptEncoding, http.StatusOK)
+		// Check if fallback document exists
+		if err == nil {
+			// Stop
+			return
+		}
+
+		if err != s3client.ErrNotFound {
+			rctx.manageStreamFileError(err, requestPath)
+			// Stop
+			return
+		}
+	}
+
+	// Check if error document is configured
+	if websiteCfg.ErrorDocument != "" {
+		// Search error document from request path folder to root folder
+		folder := "/" + strings.TrimPrefix(requestPath, "/")
+		if strings.HasSuffix(folder, "/") {
+			folder = path.Clean(folder)
+		} else {
+			folder = path.Dir(folder)
+		}
+
+		for {
+			err := rctx.streamWebsiteFile(&s3client.GetInput{
+				Key:      rctx.generateStartKey(path.Join(folder, websiteCfg.ErrorDocument)),
+				HeadOnly: input.HeadOnly,
+			}, input.AcceptEncoding, http.StatusNotFound)
+			// Check if error document exists
+			if err == nil {
+				// Stop
+				return
+			}
+
+			if err != s3client.ErrNotFound {
+				rctx.manageStreamFileError(err, requestPath)
+				// Stop
+				return
+			}
+			// Check if root folder is reached
+			if folder == "/" {
+				break
+			}
+			// Go to parent folder
+			folder = path.Dir(folder)
+		}
+	}
+
+	// Default not found answer
+	rctx.HandleNotFound(requestPath)
+}
+
+// streamWebsiteFile will answer with object or with a redirect when website redirect location is set on it
+// Status code is forced when not 0
+func (rctx *requestContext) streamWebsiteFile(input *s3client.GetInput, acceptEncoding string, status int) error {
+	// Get object from s3
+	objOutput, err := rctx.getObject(input, acceptEncoding)
+	if err != nil {
+		return err
+	}
+	// Close body at the end
+	defer (*objOutput.Body).Close()
+	// Check if object is a redirect
+	if objOutput.WebsiteRedirectLocation != "" {
+		rctx.httpRW.Header().Set("Location", rctx.getWebsiteRedirectLocation(objOutput.WebsiteRedirectLocation))
+		rctx.httpRW.WriteHeader(http.StatusMovedPermanently)
+		// Stop
+		return nil
+	}
+	// Set headers from object
+	if status == 0 {
+		setHeadersFromObjectOutput(rctx.httpRW, objOutput)
+	} else {
+		setObjectHeaders(rctx.httpRW, objOutput)
+		rctx.httpRW.WriteHeader(status)
+	}
+	// Copy data stream to output stream
+	_, err = io.Copy(rctx.httpRW, *objOutput.Body)
+	// Return potential error
+	return err
+}
+
+// getWebsiteRedirectLocation will transform website redirect location in an url
+// Absolute paths are relative to mount path
+func (rctx *requestContext) getWebsiteRedirectLocation(location string) string {
+	// Check if location is a path
+	if strings.HasPrefix(location, "/") {
+		return rctx.mountPath + strings.TrimPrefix(location, "/")
+	}
+
+	return location
+}
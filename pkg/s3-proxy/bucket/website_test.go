@@ -0,0 +1,171 @@
+// +build unit
+
+package bucket
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
+)
+
+func Test_requestContext_manageGetWebsite(t *testing.T) {
+	handleNotFoundCalled := false
+	errorHandlers := &ErrorHandlers{
+		HandleNotFoundWithTemplate: func(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
+			handleNotFoundCalled = true
+		},
+	}
+	newObject := func(content string) *s3client.GetOutput {
+		body := ioutil.NopCloser(strings.NewReader(content))
+		return &s3client.GetOutput{Body: &body, ContentType: "text/html"}
+	}
+	newRedirect := func(location string) *s3client.GetOutput {
+		obj := newObject("")
+		obj.WebsiteRedirectLocation = location
+		return obj
+	}
+	tests := []struct {
+		name                         string
+		websiteCfg                   *config.WebsiteConfig
+		indexDocument                string
+		objects                      map[string]*s3client.GetOutput
+		requestPath                  string
+		expectedHandleNotFoundCalled bool
+		expectedStatus               int
+		expectedBody                 string
+		expectedLocation             string
+		expectedGetKeys              []string
+	}{
+		{
+			name:            "should answer with file",
+			websiteCfg:      &config.WebsiteConfig{Enabled: true},
+			objects:         map[string]*s3client.GetOutput{"prefix/dir/file.html": newObject("file")},
+			requestPath:     "/dir/file.html",
+			expectedStatus:  http.StatusOK,
+			expectedBody:    "file",
+			expectedGetKeys: []string{"prefix/dir/file.html"},
+		},
+		{
+			name:            "should answer with index document on folder",
+			websiteCfg:      &config.WebsiteConfig{Enabled: true},
+			indexDocument:   "index.html",
+			objects:         map[string]*s3client.GetOutput{"prefix/dir/index.html": newObject("index")},
+			requestPath:     "/dir/",
+			expectedStatus:  http.StatusOK,
+			expectedBody:    "index",
+			expectedGetKeys: []string{"prefix/dir/index.html"},
+		},
+		{
+			name:                         "should answer not found on folder without index document",
+			websiteCfg:                   &config.WebsiteConfig{Enabled: true},
+			requestPath:                  "/dir/",
+			expectedHandleNotFoundCalled: true,
+		},
+		{
+			name:             "should redirect with path relative to mount path",
+			websiteCfg:       &config.WebsiteConfig{Enabled: true},
+			objects:          map[string]*s3client.GetOutput{"prefix/old.html": newRedirect("/new.html")},
+			requestPath:      "/old.html",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "/mount/new.html",
+			expectedGetKeys:  []string{"prefix/old.html"},
+		},
+		{
+			name:             "should redirect with url",
+			websiteCfg:       &config.WebsiteConfig{Enabled: true},
+			objects:          map[string]*s3client.GetOutput{"prefix/old.html": newRedirect("https://example.com/new.html")},
+			requestPath:      "/old.html",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://example.com/new.html",
+			expectedGetKeys:  []string{"prefix/old.html"},
+		},
+		{
+			name:       "should answer with fallback document on unknown path",
+			websiteCfg: &config.WebsiteConfig{Enabled: true, FallbackDocument: "/index.html", ErrorDocument: "404.html"},
+			objects: map[string]*s3client.GetOutput{
+				"prefix/index.html": newObject("app"),
+				"prefix/404.html":   newObject("error"),
+			},
+			requestPath:     "/app/route",
+			expectedStatus:  http.StatusOK,
+			expectedBody:    "app",
+			expectedGetKeys: []string{"prefix/app/route", "prefix/index.html"},
+		},
+		{
+			name:       "should answer with nearest error document",
+			websiteCfg: &config.WebsiteConfig{Enabled: true, FallbackDocument: "/index.html", ErrorDocument: "404.html"},
+			objects: map[string]*s3client.GetOutput{
+				"prefix/dir/404.html": newObject("dir error"),
+				"prefix/404.html":     newObject("error"),
+			},
+			requestPath:     "/dir/sub/file.html",
+			expectedStatus:  http.StatusNotFound,
+			expectedBody:    "dir error",
+			expectedGetKeys: []string{"prefix/dir/sub/file.html", "prefix/index.html", "prefix/dir/sub/404.html", "prefix/dir/404.html"},
+		},
+		{
+			name:                         "should answer not found when error document isn't found",
+			websiteCfg:                   &config.WebsiteConfig{Enabled: true, ErrorDocument: "404.html"},
+			objects:                      map[string]*s3client.GetOutput{},
+			requestPath:                  "/dir/",
+			indexDocument:                "index.html",
+			expectedHandleNotFoundCalled: true,
+			expectedGetKeys:              []string{"prefix/dir/index.html", "prefix/dir/404.html", "prefix/404.html"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handleNotFoundCalled = false
+			s3ctx := &s3clientTest{GetResultsByKey: tt.objects}
+			if s3ctx.GetResultsByKey == nil {
+				s3ctx.GetResultsByKey = map[string]*s3client.GetOutput{}
+			}
+			recorder := httptest.NewRecorder()
+			rctx := &requestContext{
+				s3Context: s3ctx,
+				logger:    log.NewLogger(),
+				targetCfg: &config.TargetConfig{
+					Bucket:        &config.BucketConfig{Prefix: "prefix/"},
+					IndexDocument: tt.indexDocument,
+					Actions: &config.ActionsConfig{
+						GET: &config.GetActionConfig{
+							Enabled: true,
+							Config:  &config.GetActionConfigConfig{Website: tt.websiteCfg},
+						},
+					},
+				},
+				tplConfig:      &config.TemplateConfig{},
+				mountPath:      "/mount/",
+				httpRW:         recorder,
+				errorsHandlers: errorHandlers,
+			}
+			rctx.Get(&GetInput{RequestPath: tt.requestPath})
+			if handleNotFoundCalled != tt.expectedHandleNotFoundCalled {
+				t.Errorf("requestContext.Get() => handleNotFoundCalled = %+v, want %+v", handleNotFoundCalled, tt.expectedHandleNotFoundCalled)
+			}
+			if tt.expectedGetKeys != nil && !reflect.DeepEqual(s3ctx.GetKeys, tt.expectedGetKeys) {
+				t.Errorf("requestContext.Get() => s3client.GetKeys = %+v, want %+v", s3ctx.GetKeys, tt.expectedGetKeys)
+			}
+			// Check answer when one is expected
+			if tt.expectedStatus == 0 {
+				return
+			}
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("requestContext.Get() => status = %d, want %d", recorder.Code, tt.expectedStatus)
+			}
+			if got := recorder.Body.String(); got != tt.expectedBody {
+				t.Errorf("requestContext.Get() => body = %s, want %s", got, tt.expectedBody)
+			}
+			if got := recorder.Header().Get("Location"); got != tt.expectedLocation {
+				t.Errorf("requestContext.Get() => location = %s, want %s", got, tt.expectedLocation)
+			}
+		})
+	}
+}
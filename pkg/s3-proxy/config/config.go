@@ -1,9 +1,13 @@
 package config
 
 import (
+	"encoding/base64"
 	"errors"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
 )
 
 // DefaultPort Default port
@@ -24,6 +28,9 @@ const DefaultBucketRegion = "us-east-1"
 // DefaultTemplateFolderListPath Default template folder list path
 const DefaultTemplateFolderListPath = "templates/folder-list.tpl"
 
+// DefaultTemplateFileVersionsPath Default template file versions path
+const DefaultTemplateFileVersionsPath = "templates/file-versions.tpl"
+
 // DefaultTemplateTargetListPath Default template target list path
 const DefaultTemplateTargetListPath = "templates/target-list.tpl"
 
@@ -42,6 +49,81 @@ const DefaultTemplateInternalServerErrorPath = "templates/internal-server-error.
 // DefaultTemplateUnauthorizedErrorPath Default template unauthorized error path
 const DefaultTemplateUnauthorizedErrorPath = "templates/unauthorized.tpl"
 
+// DefaultListMaxKeys Default maximum number of entries in a folder listing page
+const DefaultListMaxKeys = 1000
+
+// DefaultPresignedURLExpiry Default expiry of presigned URLs used for redirections
+const DefaultPresignedURLExpiry = 15 * time.Minute
+
+// MaxPresignedURLExpiry Maximum expiry of presigned URLs allowed by S3
+const MaxPresignedURLExpiry = 7 * 24 * time.Hour
+
+// DefaultArchiveMaxObjects Default maximum number of objects in a folder archive
+const DefaultArchiveMaxObjects = 1000
+
+// DefaultArchiveMaxTotalSize Default maximum total size in bytes of objects in a folder archive (1 GiB)
+const DefaultArchiveMaxTotalSize = 1024 * 1024 * 1024
+
+// DefaultPrecompressedEncodings Default encodings of precompressed object variants in preference order
+var DefaultPrecompressedEncodings = []string{"br", "gzip"}
+
+// DefaultObjectCacheMaxSize Default maximum total size in bytes of objects in a target disk cache (1 GiB)
+const DefaultObjectCacheMaxSize = 1024 * 1024 * 1024
+
+// DefaultObjectCacheMaxObjectSize Default maximum size in bytes of an object stored in a target disk cache (100 MiB)
+const DefaultObjectCacheMaxObjectSize = 100 * 1024 * 1024
+
+// DefaultObjectCacheTTL Default duration before cached objects are revalidated
+const DefaultObjectCacheTTL = time.Minute
+
+// DefaultListingCacheTTL Default duration of cached folder listings
+const DefaultListingCacheTTL = 30 * time.Second
+
+// DefaultListingCacheMaxEntries Default maximum number of cached folder listing pages in a target
+const DefaultListingCacheMaxEntries = 1000
+
+// DefaultReadFailoverFailureThreshold Default number of consecutive failures before a bucket is ejected
+const DefaultReadFailoverFailureThreshold = 3
+
+// DefaultReadFailoverEjectionDuration Default duration of a bucket ejection
+const DefaultReadFailoverEjectionDuration = 30 * time.Second
+
+// WriteMirrorPolicyAll Write mirror policy waiting for all buckets writes
+const WriteMirrorPolicyAll = "all"
+
+// WriteMirrorPolicyAsync Write mirror policy waiting for target bucket write only
+const WriteMirrorPolicyAsync = "async"
+
+// DefaultWriteMirrorRetryDelay Default duration between retries of a failed mirror bucket write
+const DefaultWriteMirrorRetryDelay = time.Second
+
+// DefaultWriteMirrorQueueSize Default maximum number of pending asynchronous writes in a target
+const DefaultWriteMirrorQueueSize = 100
+
+// DefaultWriteMirrorWorkers Default number of asynchronous writes done in parallel in a target
+const DefaultWriteMirrorWorkers = 4
+
+// DefaultWriteMirrorMaxSpoolSize Default maximum total size in bytes of uploaded bodies stored for mirror buckets in a target (1 GiB)
+const DefaultWriteMirrorMaxSpoolSize = 1024 * 1024 * 1024
+
+// SSEAlgorithmKMS Server side encryption algorithm with AWS KMS keys
+const SSEAlgorithmKMS = "aws:kms"
+
+// SSECustomerAlgorithm Server side encryption algorithm used with customer keys
+const SSECustomerAlgorithm = "AES256"
+
+// sseCustomerKeyLength Length in bytes of server side encryption customer keys
+const sseCustomerKeyLength = 32
+
+// DefaultAssumeRoleSessionName Default session name used for STS assume role
+const DefaultAssumeRoleSessionName = "s3-proxy"
+
+// MinAssumeRoleDuration Minimum duration of STS assume role sessions
+const MinAssumeRoleDuration = 15 * time.Minute
+
+// MaxAssumeRoleDuration Maximum duration of STS assume role sessions
+const MaxAssumeRoleDuration = 12 * time.Hour
+
 // DefaultOIDCScopes Default OIDC Scopes
 var DefaultOIDCScopes = []string{"openid", "profile", "email"}
 
@@ -54,6 +136,9 @@ const DefaultOIDCCookieName = "oidc"
 // ErrMainBucketPathSupportNotValid Error thrown when main bucket path support option isn't valid
 var ErrMainBucketPathSupportNotValid = errors.New("main bucket path support option can be enabled only when only one bucket is configured")
 
+// ErrSSECustomerKeyInvalid Error raised when server side encryption customer key isn't a base64 encoded 256 bits key
+var ErrSSECustomerKeyInvalid = errors.New("encryption customer key must be a base64 encoded 256 bits key")
+
 // TemplateErrLoadingEnvCredentialEmpty Template Error when Loading Environment variable Credentials
 var TemplateErrLoadingEnvCredentialEmpty = "error loading credentials, environment variable %s is empty"
 
@@ -140,6 +225,7 @@ type BasicAuthUserConfig struct {
 // TemplateConfig Templates configuration
 type TemplateConfig struct {
 	FolderList          string `mapstructure:"folderList" validate:"required"`
+	FileVersions        string `mapstructure:"fileVersions" validate:"required"`
 	TargetList          string `mapstructure:"targetList" validate:"required"`
 	NotFound            string `mapstructure:"notFound" validate:"required"`
 	InternalServerError string `mapstructure:"internalServerError" validate:"required"`
@@ -156,18 +242,83 @@ type ServerConfig struct {
 
 // TargetConfig Bucket instance configuration
 type TargetConfig struct {
-	Name          string                `mapstructure:"name" validate:"required"`
-	Bucket        *BucketConfig         `mapstructure:"bucket" validate:"required"`
-	Resources     []*Resource           `mapstructure:"resources" validate:"dive"`
-	Mount         *MountConfig          `mapstructure:"mount" validate:"required"`
-	IndexDocument string                `mapstructure:"indexDocument"`
-	Actions       *ActionsConfig        `mapstructure:"actions"`
-	Templates     *TargetTemplateConfig `mapstructure:"templates"`
+	Name            string                   `mapstructure:"name" validate:"required"`
+	Bucket          *BucketConfig            `mapstructure:"bucket" validate:"required"`
+	Resources       []*Resource              `mapstructure:"resources" validate:"dive"`
+	Mount           *MountConfig             `mapstructure:"mount" validate:"required"`
+	IndexDocument   string                   `mapstructure:"indexDocument"`
+	Actions         *ActionsConfig           `mapstructure:"actions"`
+	Templates       *TargetTemplateConfig    `mapstructure:"templates"`
+	KeyRewriteList  []*KeyRewriteConfig      `mapstructure:"keyRewriteList" validate:"dive"`
+	ResponseHeaders []*ResponseHeadersConfig `mapstructure:"responseHeaders" validate:"dive"`
+	ObjectCache     *ObjectCacheConfig       `mapstructure:"objectCache"`
+	ListingCache    *ListingCacheConfig      `mapstructure:"listingCache"`
+	ReadFailover    *ReadFailoverConfig      `mapstructure:"readFailover"`
+	WriteMirror     *WriteMirrorConfig       `mapstructure:"writeMirror"`
+}
+
+// WriteMirrorConfig Write mirror on secondary buckets configuration
+type WriteMirrorConfig struct {
+	Enabled        bool            `mapstructure:"enabled"`
+	Buckets        []*BucketConfig `mapstructure:"buckets" validate:"dive"`
+	Policy         string          `mapstructure:"policy" validate:"omitempty,oneof=all async"`
+	Retries        int             `mapstructure:"retries" validate:"gte=0"`
+	RetryDelay     time.Duration   `mapstructure:"retryDelay"`
+	QueueSize      int             `mapstructure:"queueSize" validate:"gte=0"`
+	Workers        int             `mapstructure:"workers" validate:"gte=0"`
+	SpoolDirectory string          `mapstructure:"spoolDirectory"`
+	MaxSpoolSize   int64           `mapstructure:"maxSpoolSize" validate:"gte=0"`
+}
+
+// ReadFailoverConfig Read failover on secondary buckets configuration
+type ReadFailoverConfig struct {
+	Enabled          bool            `mapstructure:"enabled"`
+	Buckets          []*BucketConfig `mapstructure:"buckets" validate:"dive"`
+	OnNotFound       bool            `mapstructure:"onNotFound"`
+	Timeout          time.Duration   `mapstructure:"timeout"`
+	FailureThreshold int             `mapstructure:"failureThreshold" validate:"gte=0"`
+	EjectionDuration time.Duration   `mapstructure:"ejectionDuration"`
+}
+
+// ListingCacheConfig In-memory folder listing cache configuration
+type ListingCacheConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	TTL        time.Duration `mapstructure:"ttl"`
+	MaxEntries int           `mapstructure:"maxEntries" validate:"gte=0"`
+}
+
+// ObjectCacheConfig Local disk object cache configuration
+type ObjectCacheConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Directory     string        `mapstructure:"directory"`
+	MaxSize       int64         `mapstructure:"maxSize" validate:"gte=0"`
+	MaxObjectSize int64         `mapstructure:"maxObjectSize" validate:"gte=0"`
+	TTL           time.Duration `mapstructure:"ttl"`
+}
+
+// ResponseHeadersConfig Response headers rule configuration
+type ResponseHeadersConfig struct {
+	Path           string            `mapstructure:"path" validate:"required"`
+	Headers        map[string]string `mapstructure:"headers"`
+	RemoveHeaders  []string          `mapstructure:"removeHeaders"`
+	DisableNoCache bool              `mapstructure:"disableNoCache"`
+	PathGlob       glob.Glob
+}
+
+// KeyRewriteConfig Request path to S3 key rewrite rule configuration
+type KeyRewriteConfig struct {
+	Source              string `mapstructure:"source" validate:"required"`
+	Target              string `mapstructure:"target"`
+	ReverseSource       string `mapstructure:"reverseSource" validate:"required_with=ReverseTarget"`
+	ReverseTarget       string `mapstructure:"reverseTarget"`
+	SourceRegexp        *regexp.Regexp
+	ReverseSourceRegexp *regexp.Regexp
 }
 
 // TargetTemplateConfig Target templates configuration to override default ones
 type TargetTemplateConfig struct {
 	FolderList          *TargetTemplateConfigItem `mapstructure:"folderList"`
+	FileVersions        *TargetTemplateConfigItem `mapstructure:"fileVersions"`
 	NotFound            *TargetTemplateConfigItem `mapstructure:"notFound"`
 	InternalServerError *TargetTemplateConfigItem `mapstructure:"internalServerError"`
 	Forbidden           *TargetTemplateConfigItem `mapstructure:"forbidden"`
@@ -190,7 +341,13 @@ type ActionsConfig struct {
 
 // DeleteActionConfig Delete action configuration
 type DeleteActionConfig struct {
-	Enabled bool `mapstructure:"enabled"`
+	Enabled bool                      `mapstructure:"enabled"`
+	Config  *DeleteActionConfigConfig `mapstructure:"config"`
+}
+
+// DeleteActionConfigConfig Delete action configuration object configuration
+type DeleteActionConfigConfig struct {
+	Recursive bool `mapstructure:"recursive"`
 }
 
 // PutActionConfig Post action configuration
@@ -201,14 +358,76 @@ type PutActionConfig struct {
 
 // PutActionConfigConfig Post action configuration object configuration
 type PutActionConfigConfig struct {
-	Metadata      map[string]string `mapstructure:"metadata"`
-	StorageClass  string            `mapstructure:"storageClass"`
-	AllowOverride bool              `mapstructure:"allowOverride"`
+	Metadata            map[string]string      `mapstructure:"metadata"`
+	Tags                map[string]string      `mapstructure:"tags"`
+	StorageClass        string                 `mapstructure:"storageClass"`
+	AllowOverride       bool                   `mapstructure:"allowOverride"`
+	MaxSize             int64                  `mapstructure:"maxSize" validate:"gte=0"`
+	AllowedContentTypes []string               `mapstructure:"allowedContentTypes"`
+	DeniedContentTypes  []string               `mapstructure:"deniedContentTypes"`
+	AllowedFilenames    []string               `mapstructure:"allowedFilenames"`
+	PresignedUpload     *PresignedUploadConfig `mapstructure:"presignedUpload"`
+	// Compiled glob patterns
+	AllowedContentTypesGlobs []glob.Glob
+	DeniedContentTypesGlobs  []glob.Glob
+	AllowedFilenamesGlobs    []glob.Glob
+}
+
+// PresignedUploadConfig Presigned upload configuration
+type PresignedUploadConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Expiry  time.Duration `mapstructure:"expiry"`
 }
 
 // GetActionConfig Get action configuration
 type GetActionConfig struct {
-	Enabled bool `mapstructure:"enabled"`
+	Enabled bool                   `mapstructure:"enabled"`
+	Config  *GetActionConfigConfig `mapstructure:"config"`
+}
+
+// GetActionConfigConfig Get action configuration object configuration
+type GetActionConfigConfig struct {
+	ListMaxKeys          int64                       `mapstructure:"listMaxKeys" validate:"gte=0"`
+	PresignedURLRedirect *PresignedURLRedirectConfig `mapstructure:"presignedURLRedirect"`
+	Archive              *ArchiveConfig              `mapstructure:"archive"`
+	Website              *WebsiteConfig              `mapstructure:"website"`
+	Precompressed        *PrecompressedConfig        `mapstructure:"precompressed"`
+}
+
+// PrecompressedConfig Precompressed object variants configuration
+type PrecompressedConfig struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	Encodings []string `mapstructure:"encodings" validate:"dive,oneof=br gzip"`
+}
+
+// WebsiteConfig Static website mode configuration
+type WebsiteConfig struct {
+	Enabled          bool   `mapstructure:"enabled"`
+	FallbackDocument string `mapstructure:"fallbackDocument"`
+	ErrorDocument    string `mapstructure:"errorDocument"`
+}
+
+// ArchiveConfig Folder archive download configuration
+type ArchiveConfig struct {
+	Enabled      bool  `mapstructure:"enabled"`
+	MaxObjects   int64 `mapstructure:"maxObjects" validate:"gte=0"`
+	MaxTotalSize int64 `mapstructure:"maxTotalSize" validate:"gte=0"`
+}
+
+// PresignedURLRedirectConfig Presigned URL redirect configuration
+type PresignedURLRedirectConfig struct {
+	Enabled         bool                               `mapstructure:"enabled"`
+	Expiry          time.Duration                      `mapstructure:"expiry"`
+	ResponseHeaders *PresignedURLResponseHeadersConfig `mapstructure:"responseHeaders"`
+}
+
+// PresignedURLResponseHeadersConfig Response headers overrides for presigned URLs
+type PresignedURLResponseHeadersConfig struct {
+	CacheControl       string `mapstructure:"cacheControl"`
+	ContentDisposition string `mapstructure:"contentDisposition"`
+	ContentEncoding    string `mapstructure:"contentEncoding"`
+	ContentLanguage    string `mapstructure:"contentLanguage"`
+	ContentType        string `mapstructure:"contentType"`
 }
 
 // Resource Resource
@@ -245,13 +464,32 @@ type BucketConfig struct {
 	Region      string                  `mapstructure:"region"`
 	S3Endpoint  string                  `mapstructure:"s3Endpoint"`
 	Credentials *BucketCredentialConfig `mapstructure:"credentials" validate:"omitempty,dive"`
+	STSEndpoint string                  `mapstructure:"stsEndpoint"`
 	DisableSSL  bool                    `mapstructure:"disableSSL"`
+	Encryption  *BucketEncryptionConfig `mapstructure:"encryption" validate:"omitempty"`
+}
+
+// BucketEncryptionConfig Bucket server side encryption configuration
+type BucketEncryptionConfig struct {
+	ServerSideEncryption string            `mapstructure:"serverSideEncryption" validate:"omitempty,oneof=AES256 aws:kms"`
+	KMSKeyID             string            `mapstructure:"kmsKeyId"`
+	CustomerKey          *CredentialConfig `mapstructure:"customerKey" validate:"omitempty,dive"`
 }
 
 // BucketCredentialConfig Bucket Credentials configurations
 type BucketCredentialConfig struct {
-	AccessKey *CredentialConfig `mapstructure:"accessKey" validate:"omitempty,dive"`
-	SecretKey *CredentialConfig `mapstructure:"secretKey" validate:"omitempty,dive"`
+	AccessKey  *CredentialConfig `mapstructure:"accessKey" validate:"omitempty,dive"`
+	SecretKey  *CredentialConfig `mapstructure:"secretKey" validate:"omitempty,dive"`
+	AssumeRole *AssumeRoleConfig `mapstructure:"assumeRole" validate:"omitempty"`
+}
+
+// AssumeRoleConfig STS assume role configuration
+type AssumeRoleConfig struct {
+	RoleARN              string        `mapstructure:"roleArn" validate:"required"`
+	ExternalID           string        `mapstructure:"externalId"`
+	SessionName          string        `mapstructure:"sessionName"`
+	Duration             time.Duration `mapstructure:"duration"`
+	WebIdentityTokenFile string        `mapstructure:"webIdentityTokenFile"`
 }
 
 // CredentialConfig Credential Configurations
@@ -278,3 +516,328 @@ func (bcfg *BucketConfig) GetRootPrefix() string {
 	// Return result
 	return key
 }
+
+// GetListMaxKeys Get maximum number of entries in a folder listing page
+func (tgt *TargetConfig) GetListMaxKeys() int64 {
+	// Check if a limit is configured in GET action
+	if tgt.Actions != nil && tgt.Actions.GET != nil &&
+		tgt.Actions.GET.Config != nil && tgt.Actions.GET.Config.ListMaxKeys > 0 {
+		return tgt.Actions.GET.Config.ListMaxKeys
+	}
+	// Return default value
+	return DefaultListMaxKeys
+}
+
+// GetPresignedURLRedirect Get presigned URL redirect configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetPresignedURLRedirect() *PresignedURLRedirectConfig {
+	// Check if redirect is configured and enabled in GET action
+	if tgt.Actions != nil && tgt.Actions.GET != nil && tgt.Actions.GET.Config != nil &&
+		tgt.Actions.GET.Config.PresignedURLRedirect != nil && tgt.Actions.GET.Config.PresignedURLRedirect.Enabled {
+		return tgt.Actions.GET.Config.PresignedURLRedirect
+	}
+
+	return nil
+}
+
+// GetWebsite Get static website configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetWebsite() *WebsiteConfig {
+	// Check if website is configured and enabled in GET action
+	if tgt.Actions != nil && tgt.Actions.GET != nil && tgt.Actions.GET.Config != nil &&
+		tgt.Actions.GET.Config.Website != nil && tgt.Actions.GET.Config.Website.Enabled {
+		return tgt.Actions.GET.Config.Website
+	}
+
+	return nil
+}
+
+// GetPrecompressed Get precompressed object variants configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetPrecompressed() *PrecompressedConfig {
+	// Check if precompressed is configured and enabled in GET action
+	if tgt.Actions != nil && tgt.Actions.GET != nil && tgt.Actions.GET.Config != nil &&
+		tgt.Actions.GET.Config.Precompressed != nil && tgt.Actions.GET.Config.Precompressed.Enabled {
+		return tgt.Actions.GET.Config.Precompressed
+	}
+
+	return nil
+}
+
+// GetObjectCache Get object disk cache configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetObjectCache() *ObjectCacheConfig {
+	// Check if object cache is configured and enabled
+	if tgt.ObjectCache != nil && tgt.ObjectCache.Enabled {
+		return tgt.ObjectCache
+	}
+
+	return nil
+}
+
+// GetListingCache Get folder listing cache configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetListingCache() *ListingCacheConfig {
+	// Check if listing cache is configured and enabled
+	if tgt.ListingCache != nil && tgt.ListingCache.Enabled {
+		return tgt.ListingCache
+	}
+
+	return nil
+}
+
+// GetReadFailover Get read failover configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetReadFailover() *ReadFailoverConfig {
+	// Check if read failover is configured and enabled
+	if tgt.ReadFailover != nil && tgt.ReadFailover.Enabled {
+		return tgt.ReadFailover
+	}
+
+	return nil
+}
+
+// GetWriteMirror Get write mirror configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetWriteMirror() *WriteMirrorConfig {
+	// Check if write mirror is configured and enabled
+	if tgt.WriteMirror != nil && tgt.WriteMirror.Enabled {
+		return tgt.WriteMirror
+	}
+
+	return nil
+}
+
+// getSecondaryBuckets Get read failover and write mirror buckets even if they aren't enabled
+func (tgt *TargetConfig) getSecondaryBuckets() []*BucketConfig {
+	buckets := make([]*BucketConfig, 0)
+	if tgt.ReadFailover != nil {
+		buckets = append(buckets, tgt.ReadFailover.Buckets...)
+	}
+
+	if tgt.WriteMirror != nil {
+		buckets = append(buckets, tgt.WriteMirror.Buckets...)
+	}
+
+	return buckets
+}
+
+// GetArchive Get folder archive configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetArchive() *ArchiveConfig {
+	// Check if archive is configured and enabled in GET action
+	if tgt.Actions != nil && tgt.Actions.GET != nil && tgt.Actions.GET.Config != nil &&
+		tgt.Actions.GET.Config.Archive != nil && tgt.Actions.GET.Config.Archive.Enabled {
+		return tgt.Actions.GET.Config.Archive
+	}
+
+	return nil
+}
+
+// GetPresignedUpload Get presigned upload configuration if enabled (nil otherwise)
+func (tgt *TargetConfig) GetPresignedUpload() *PresignedUploadConfig {
+	// Check if presigned upload is configured and enabled in PUT action
+	if tgt.Actions != nil && tgt.Actions.PUT != nil && tgt.Actions.PUT.Config != nil &&
+		tgt.Actions.PUT.Config.PresignedUpload != nil && tgt.Actions.PUT.Config.PresignedUpload.Enabled {
+		return tgt.Actions.PUT.Config.PresignedUpload
+	}
+
+	return nil
+}
+
+// GetExpiry Get presigned URL expiry or default value
+func (cfg *PresignedURLRedirectConfig) GetExpiry() time.Duration {
+	// Check if expiry is configured
+	if cfg.Expiry > 0 {
+		return cfg.Expiry
+	}
+	// Return default value
+	return DefaultPresignedURLExpiry
+}
+
+// GetExpiry Get presigned upload expiry or default value
+func (cfg *PresignedUploadConfig) GetExpiry() time.Duration {
+	// Check if expiry is configured
+	if cfg.Expiry > 0 {
+		return cfg.Expiry
+	}
+	// Return default value
+	return DefaultPresignedURLExpiry
+}
+
+// GetEncodings Get precompressed variants encodings in preference order or default value
+func (cfg *PrecompressedConfig) GetEncodings() []string {
+	// Check if encodings are configured
+	if len(cfg.Encodings) > 0 {
+		return cfg.Encodings
+	}
+	// Return default value
+	return DefaultPrecompressedEncodings
+}
+
+// GetMaxObjects Get maximum number of objects in a folder archive or default value
+func (cfg *ArchiveConfig) GetMaxObjects() int64 {
+	// Check if a limit is configured
+	if cfg.MaxObjects > 0 {
+		return cfg.MaxObjects
+	}
+	// Return default value
+	return DefaultArchiveMaxObjects
+}
+
+// GetMaxTotalSize Get maximum total size of objects in a folder archive or default value
+func (cfg *ArchiveConfig) GetMaxTotalSize() int64 {
+	// Check if a limit is configured
+	if cfg.MaxTotalSize > 0 {
+		return cfg.MaxTotalSize
+	}
+	// Return default value
+	return DefaultArchiveMaxTotalSize
+}
+
+// GetMaxSize Get maximum total size of cached objects or default value
+func (cfg *ObjectCacheConfig) GetMaxSize() int64 {
+	// Check if a limit is configured
+	if cfg.MaxSize > 0 {
+		return cfg.MaxSize
+	}
+	// Return default value
+	return DefaultObjectCacheMaxSize
+}
+
+// GetMaxObjectSize Get maximum size of a cached object or default value
+func (cfg *ObjectCacheConfig) GetMaxObjectSize() int64 {
+	// Check if a limit is configured
+	if cfg.MaxObjectSize > 0 {
+		return cfg.MaxObjectSize
+	}
+	// Return default value
+	return DefaultObjectCacheMaxObjectSize
+}
+
+// GetTTL Get duration before cached objects are revalidated or default value
+func (cfg *ObjectCacheConfig) GetTTL() time.Duration {
+	// Check if a duration is configured
+	if cfg.TTL > 0 {
+		return cfg.TTL
+	}
+	// Return default value
+	return DefaultObjectCacheTTL
+}
+
+// GetTTL Get duration of cached folder listings or default value
+func (cfg *ListingCacheConfig) GetTTL() time.Duration {
+	// Check if a duration is configured
+	if cfg.TTL > 0 {
+		return cfg.TTL
+	}
+	// Return default value
+	return DefaultListingCacheTTL
+}
+
+// GetMaxEntries Get maximum number of cached folder listing pages or default value
+func (cfg *ListingCacheConfig) GetMaxEntries() int {
+	// Check if a limit is configured
+	if cfg.MaxEntries > 0 {
+		return cfg.MaxEntries
+	}
+	// Return default value
+	return DefaultListingCacheMaxEntries
+}
+
+// GetFailureThreshold Get number of consecutive failures before a bucket is ejected or default value
+func (cfg *ReadFailoverConfig) GetFailureThreshold() int {
+	// Check if a threshold is configured
+	if cfg.FailureThreshold > 0 {
+		return cfg.FailureThreshold
+	}
+	// Return default value
+	return DefaultReadFailoverFailureThreshold
+}
+
+// GetEjectionDuration Get duration of a bucket ejection or default value
+func (cfg *ReadFailoverConfig) GetEjectionDuration() time.Duration {
+	// Check if a duration is configured
+	if cfg.EjectionDuration > 0 {
+		return cfg.EjectionDuration
+	}
+	// Return default value
+	return DefaultReadFailoverEjectionDuration
+}
+
+// GetPolicy Get write mirror policy or default value
+func (cfg *WriteMirrorConfig) GetPolicy() string {
+	// Check if a policy is configured
+	if cfg.Policy != "" {
+		return cfg.Policy
+	}
+	// Return default value
+	return WriteMirrorPolicyAll
+}
+
+// GetRetryDelay Get duration between retries of a failed mirror bucket write or default value
+func (cfg *WriteMirrorConfig) GetRetryDelay() time.Duration {
+	// Check if a duration is configured
+	if cfg.RetryDelay > 0 {
+		return cfg.RetryDelay
+	}
+	// Return default value
+	return DefaultWriteMirrorRetryDelay
+}
+
+// GetQueueSize Get maximum number of pending asynchronous writes or default value
+func (cfg *WriteMirrorConfig) GetQueueSize() int {
+	// Check if a limit is configured
+	if cfg.QueueSize > 0 {
+		return cfg.QueueSize
+	}
+	// Return default value
+	return DefaultWriteMirrorQueueSize
+}
+
+// GetWorkers Get number of asynchronous writes done in parallel or default value
+func (cfg *WriteMirrorConfig) GetWorkers() int {
+	// Check if a number is configured
+	if cfg.Workers > 0 {
+		return cfg.Workers
+	}
+	// Return default value
+	return DefaultWriteMirrorWorkers
+}
+
+// GetMaxSpoolSize Get maximum total size of uploaded bodies stored for mirror buckets or default value
+func (cfg *WriteMirrorConfig) GetMaxSpoolSize() int64 {
+	// Check if a limit is configured
+	if cfg.MaxSpoolSize > 0 {
+		return cfg.MaxSpoolSize
+	}
+	// Return default value
+	return DefaultWriteMirrorMaxSpoolSize
+}
+
+// GetSessionName Get assume role session name or default value
+func (cfg *AssumeRoleConfig) GetSessionName() string {
+	// Check if session name is configured
+	if cfg.SessionName != "" {
+		return cfg.SessionName
+	}
+	// Return default value
+	return DefaultAssumeRoleSessionName
+}
+
+// GetDuration Get assume role session duration or default value
+func (cfg *AssumeRoleConfig) GetDuration() time.Duration {
+	// Check if duration is configured
+	if cfg.Duration > 0 {
+		return cfg.Duration
+	}
+	// Return default value
+	return MinAssumeRoleDuration
+}
+
+// GetCustomerKey Get decoded server side encryption customer key (empty if not configured)
+func (cfg *BucketEncryptionConfig) GetCustomerKey() (string, error) {
+	// Check if customer key is configured
+	if cfg.CustomerKey == nil {
+		return "", nil
+	}
+	// Decode key
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(cfg.CustomerKey.Value))
+	if err != nil || len(key) != sseCustomerKeyLength {
+		return "", ErrSSECustomerKeyInvalid
+	}
+
+	return string(key), nil
+}
@@ -4,6 +4,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func TestBucketConfig_GetRootPrefix(t *testing.T) {
@@ -59,3 +60,272 @@ func TestBucketConfig_GetRootPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestTargetConfig_GetListMaxKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions *ActionsConfig
+		want    int64
+	}{
+		{
+			name:    "Must return default value when actions are nil",
+			actions: nil,
+			want:    DefaultListMaxKeys,
+		},
+		{
+			name:    "Must return default value when GET configuration is nil",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true}},
+			want:    DefaultListMaxKeys,
+		},
+		{
+			name:    "Must return default value when limit is 0",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true, Config: &GetActionConfigConfig{}}},
+			want:    DefaultListMaxKeys,
+		},
+		{
+			name:    "Must return configured value",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true, Config: &GetActionConfigConfig{ListMaxKeys: 50}}},
+			want:    50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgt := &TargetConfig{Actions: tt.actions}
+			if got := tgt.GetListMaxKeys(); got != tt.want {
+				t.Errorf("TargetConfig.GetListMaxKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetConfig_GetPresignedURLRedirect(t *testing.T) {
+	redirectCfg := &PresignedURLRedirectConfig{Enabled: true}
+	tests := []struct {
+		name    string
+		actions *ActionsConfig
+		want    *PresignedURLRedirectConfig
+	}{
+		{
+			name:    "Must return nil when actions are nil",
+			actions: nil,
+			want:    nil,
+		},
+		{
+			name:    "Must return nil when GET configuration is nil",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true}},
+			want:    nil,
+		},
+		{
+			name: "Must return nil when redirect is disabled",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true, Config: &GetActionConfigConfig{
+				PresignedURLRedirect: &PresignedURLRedirectConfig{Enabled: false},
+			}}},
+			want: nil,
+		},
+		{
+			name: "Must return configuration when redirect is enabled",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true, Config: &GetActionConfigConfig{
+				PresignedURLRedirect: redirectCfg,
+			}}},
+			want: redirectCfg,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgt := &TargetConfig{Actions: tt.actions}
+			if got := tgt.GetPresignedURLRedirect(); got != tt.want {
+				t.Errorf("TargetConfig.GetPresignedURLRedirect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresignedURLRedirectConfig_GetExpiry(t *testing.T) {
+	if got := (&PresignedURLRedirectConfig{}).GetExpiry(); got != DefaultPresignedURLExpiry {
+		t.Errorf("PresignedURLRedirectConfig.GetExpiry() = %v, want %v", got, DefaultPresignedURLExpiry)
+	}
+
+	if got := (&PresignedURLRedirectConfig{Expiry: time.Hour}).GetExpiry(); got != time.Hour {
+		t.Errorf("PresignedURLRedirectConfig.GetExpiry() = %v, want %v", got, time.Hour)
+	}
+}
+
+func TestAssumeRoleConfig_Defaults(t *testing.T) {
+	cfg := &AssumeRoleConfig{}
+	if got := cfg.GetSessionName(); got != DefaultAssumeRoleSessionName {
+		t.Errorf("AssumeRoleConfig.GetSessionName() = %v, want %v", got, DefaultAssumeRoleSessionName)
+	}
+
+	if got := cfg.GetDuration(); got != MinAssumeRoleDuration {
+		t.Errorf("AssumeRoleConfig.GetDuration() = %v, want %v", got, MinAssumeRoleDuration)
+	}
+
+	cfg = &AssumeRoleConfig{SessionName: "session", Duration: time.Hour}
+	if got := cfg.GetSessionName(); got != "session" {
+		t.Errorf("AssumeRoleConfig.GetSessionName() = %v, want %v", got, "session")
+	}
+
+	if got := cfg.GetDuration(); got != time.Hour {
+		t.Errorf("AssumeRoleConfig.GetDuration() = %v, want %v", got, time.Hour)
+	}
+}
+
+func TestTargetConfig_GetPresignedUpload(t *testing.T) {
+	uploadCfg := &PresignedUploadConfig{Enabled: true}
+	tests := []struct {
+		name    string
+		actions *ActionsConfig
+		want    *PresignedUploadConfig
+	}{
+		{
+			name:    "Must return nil when actions are nil",
+			actions: nil,
+			want:    nil,
+		},
+		{
+			name:    "Must return nil when PUT configuration is nil",
+			actions: &ActionsConfig{PUT: &PutActionConfig{Enabled: true}},
+			want:    nil,
+		},
+		{
+			name: "Must return nil when presigned upload is disabled",
+			actions: &ActionsConfig{PUT: &PutActionConfig{Enabled: true, Config: &PutActionConfigConfig{
+				PresignedUpload: &PresignedUploadConfig{Enabled: false},
+			}}},
+			want: nil,
+		},
+		{
+			name: "Must return configuration when presigned upload is enabled",
+			actions: &ActionsConfig{PUT: &PutActionConfig{Enabled: true, Config: &PutActionConfigConfig{
+				PresignedUpload: uploadCfg,
+			}}},
+			want: uploadCfg,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgt := &TargetConfig{Actions: tt.actions}
+			if got := tgt.GetPresignedUpload(); got != tt.want {
+				t.Errorf("TargetConfig.GetPresignedUpload() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetConfig_GetArchive(t *testing.T) {
+	archiveCfg := &ArchiveConfig{Enabled: true}
+	tests := []struct {
+		name    string
+		actions *ActionsConfig
+		want    *ArchiveConfig
+	}{
+		{
+			name:    "Must return nil when actions are nil",
+			actions: nil,
+			want:    nil,
+		},
+		{
+			name:    "Must return nil when GET configuration is nil",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true}},
+			want:    nil,
+		},
+		{
+			name: "Must return nil when archive is disabled",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true, Config: &GetActionConfigConfig{
+				Archive: &ArchiveConfig{Enabled: false},
+			}}},
+			want: nil,
+		},
+		{
+			name: "Must return configuration when archive is enabled",
+			actions: &ActionsConfig{GET: &GetActionConfig{Enabled: true, Config: &GetActionConfigConfig{
+				Archive: archiveCfg,
+			}}},
+			want: archiveCfg,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tgt := &TargetConfig{Actions: tt.actions}
+			if got := tgt.GetArchive(); got != tt.want {
+				t.Errorf("TargetConfig.GetArchive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchiveConfig_Limits(t *testing.T) {
+	tests := []struct {
+		name             string
+		cfg              *ArchiveConfig
+		wantMaxObjects   int64
+		wantMaxTotalSize int64
+	}{
+		{
+			name:             "Must return default values when limits are 0",
+			cfg:              &ArchiveConfig{},
+			wantMaxObjects:   DefaultArchiveMaxObjects,
+			wantMaxTotalSize: DefaultArchiveMaxTotalSize,
+		},
+		{
+			name:             "Must return configured values",
+			cfg:              &ArchiveConfig{MaxObjects: 10, MaxTotalSize: 2048},
+			wantMaxObjects:   10,
+			wantMaxTotalSize: 2048,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.GetMaxObjects(); got != tt.wantMaxObjects {
+				t.Errorf("ArchiveConfig.GetMaxObjects() = %v, want %v", got, tt.wantMaxObjects)
+			}
+			if got := tt.cfg.GetMaxTotalSize(); got != tt.wantMaxTotalSize {
+				t.Errorf("ArchiveConfig.GetMaxTotalSize() = %v, want %v", got, tt.wantMaxTotalSize)
+			}
+		})
+	}
+}
+
+func TestBucketEncryptionConfig_GetCustomerKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *BucketEncryptionConfig
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Must return empty key when customer key isn't configured",
+			cfg:  &BucketEncryptionConfig{},
+			want: "",
+		},
+		{
+			name: "Must return decoded key",
+			cfg: &BucketEncryptionConfig{
+				CustomerKey: &CredentialConfig{Value: "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=\n"},
+			},
+			want: "01234567890123456789012345678901",
+		},
+		{
+			name:    "Must fail when key isn't base64 encoded",
+			cfg:     &BucketEncryptionConfig{CustomerKey: &CredentialConfig{Value: "not base64"}},
+			wantErr: true,
+		},
+		{
+			name:    "Must fail when key length isn't 256 bits",
+			cfg:     &BucketEncryptionConfig{CustomerKey: &CredentialConfig{Value: "c2hvcnQ="}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.GetCustomerKey()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BucketEncryptionConfig.GetCustomerKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("BucketEncryptionConfig.GetCustomerKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/go-playground/validator/v10"
+	"github.com/gobwas/glob"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
 	"github.com/spf13/viper"
 	"github.com/thoas/go-funk"
@@ -152,6 +153,7 @@ func (ctx *managercontext) loadDefaultConfigurationValues(vip *viper.Viper) {
 	vip.SetDefault("server.port", DefaultPort)
 	vip.SetDefault("internalServer.port", DefaultInternalPort)
 	vip.SetDefault("templates.folderList", DefaultTemplateFolderListPath)
+	vip.SetDefault("templates.fileVersions", DefaultTemplateFileVersionsPath)
 	vip.SetDefault("templates.targetList", DefaultTemplateTargetListPath)
 	vip.SetDefault("templates.notFound", DefaultTemplateNotFoundPath)
 	vip.SetDefault("templates.internalServerError", DefaultTemplateInternalServerErrorPath)
@@ -280,6 +282,38 @@ func (ctx *managercontext) GetConfig() *Config {
 	return ctx.cfg
 }
 
+// loadBucketCredentials will load access key, secret key and customer key of a bucket
+func loadBucketCredentials(bucket *BucketConfig) ([]*CredentialConfig, error) {
+	// Initialize answer
+	result := make([]*CredentialConfig, 0)
+	// Load credentials for access key and secret key
+	if bucket.Credentials != nil && bucket.Credentials.AccessKey != nil && bucket.Credentials.SecretKey != nil {
+		// Manage access key
+		err := loadCredential(bucket.Credentials.AccessKey)
+		if err != nil {
+			return nil, err
+		}
+		// Manage secret key
+		err = loadCredential(bucket.Credentials.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		// Save credential
+		result = append(result, bucket.Credentials.AccessKey, bucket.Credentials.SecretKey)
+	}
+	// Load server side encryption customer key
+	if bucket.Encryption != nil && bucket.Encryption.CustomerKey != nil {
+		err := loadCredential(bucket.Encryption.CustomerKey)
+		if err != nil {
+			return nil, err
+		}
+		// Save credential
+		result = append(result, bucket.Encryption.CustomerKey)
+	}
+
+	return result, nil
+}
+
 func loadAllCredentials(out *Config) ([]*CredentialConfig, error) {
 	// Initialize answer
 	result := make([]*CredentialConfig, 0)
@@ -306,20 +340,21 @@ func loadAllCredentials(out *Config) ([]*CredentialConfig, error) {
 				}
 			}
 		}
-		// Load credentials for access key and secret key
-		if item.Bucket.Credentials != nil && item.Bucket.Credentials.AccessKey != nil && item.Bucket.Credentials.SecretKey != nil {
-			// Manage access key
-			err := loadCredential(item.Bucket.Credentials.AccessKey)
-			if err != nil {
-				return nil, err
-			}
-			// Manage secret key
-			err = loadCredential(item.Bucket.Credentials.SecretKey)
+		// Load bucket credentials
+		creds, err := loadBucketCredentials(item.Bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, creds...)
+		// Load read failover and write mirror buckets credentials
+		for _, bucket := range item.getSecondaryBuckets() {
+			creds, err = loadBucketCredentials(bucket)
 			if err != nil {
 				return nil, err
 			}
-			// Save credential
-			result = append(result, item.Bucket.Credentials.AccessKey, item.Bucket.Credentials.SecretKey)
+
+			result = append(result, creds...)
 		}
 	}
 
@@ -411,11 +446,17 @@ func loadResourceValues(res *Resource) error {
 
 func loadBusinessDefaultValues(out *Config) error {
 	// Manage default values for targets
-	for _, item := range out.Targets {
+	for i, item := range out.Targets {
 		// Manage default configuration for target region
 		if item.Bucket != nil && item.Bucket.Region == "" {
 			item.Bucket.Region = DefaultBucketRegion
 		}
+		// Manage default configuration for read failover and write mirror buckets region
+		for _, bucket := range item.getSecondaryBuckets() {
+			if bucket != nil && bucket.Region == "" {
+				bucket.Region = DefaultBucketRegion
+			}
+		}
 		// Manage default configuration for target actions
 		if item.Actions == nil {
 			item.Actions = &ActionsConfig{GET: &GetActionConfig{Enabled: true}}
@@ -424,6 +465,27 @@ func loadBusinessDefaultValues(out *Config) error {
 		if item.Templates == nil {
 			item.Templates = &TargetTemplateConfig{}
 		}
+		// Compile key rewrite rules
+		for _, rule := range item.KeyRewriteList {
+			err := loadRegexKeyRewrite(rule)
+			if err != nil {
+				return err
+			}
+		}
+		// Compile response headers rules path patterns
+		for j, rule := range item.ResponseHeaders {
+			err := loadGlobResponseHeaders(rule)
+			if err != nil {
+				return fmt.Errorf("response headers path %d in target %d is an invalid glob pattern: %v", j, i, err)
+			}
+		}
+		// Compile upload policy patterns
+		if item.Actions.PUT != nil && item.Actions.PUT.Config != nil {
+			err := loadGlobPutPolicy(i, item.Actions.PUT.Config)
+			if err != nil {
+				return err
+			}
+		}
 		// Manage default value for resources methods
 		if item.Resources != nil {
 			for _, res := range item.Resources {
@@ -518,3 +580,70 @@ func loadRegexOIDCAuthorizationAccess(item *OIDCAuthorizationAccess) error {
 
 	return nil
 }
+
+// Load Regex in key rewrite objects
+func loadRegexKeyRewrite(item *KeyRewriteConfig) error {
+	// Compile source regexp
+	reg, err := regexp.Compile(item.Source)
+	// Check error
+	if err != nil {
+		return err
+	}
+	// Save regexp
+	item.SourceRegexp = reg
+
+	// Reverse case
+	if item.ReverseSource != "" {
+		// Compile regexp
+		reg, err = regexp.Compile(item.ReverseSource)
+		// Check error
+		if err != nil {
+			return err
+		}
+		// Save regexp
+		item.ReverseSourceRegexp = reg
+	}
+
+	return nil
+}
+
+// Load Glob in response headers objects
+func loadGlobResponseHeaders(item *ResponseHeadersConfig) error {
+	// Compile path glob
+	g, err := glob.Compile(item.Path)
+	// Check error
+	if err != nil {
+		return err
+	}
+	// Save glob
+	item.PathGlob = g
+
+	return nil
+}
+
+// Load Globs in upload policy objects
+func loadGlobPutPolicy(targetIndex int, putCfg *PutActionConfigConfig) error {
+	patternLists := []struct {
+		name     string
+		patterns []string
+		globs    *[]glob.Glob
+	}{
+		{name: "allowed content type", patterns: putCfg.AllowedContentTypes, globs: &putCfg.AllowedContentTypesGlobs},
+		{name: "denied content type", patterns: putCfg.DeniedContentTypes, globs: &putCfg.DeniedContentTypesGlobs},
+		{name: "allowed filename", patterns: putCfg.AllowedFilenames, globs: &putCfg.AllowedFilenamesGlobs},
+	}
+	for _, item := range patternLists {
+		for j, pattern := range item.patterns {
+			// Compile pattern
+			g, err := glob.Compile(pattern)
+			// Check error
+			if err != nil {
+				return fmt.Errorf("%s %d in target %d is an invalid glob pattern: %v", item.name, j, targetIndex, err)
+			}
+			// Save glob
+			*item.globs = append(*item.globs, g)
+		}
+	}
+
+	return nil
+}
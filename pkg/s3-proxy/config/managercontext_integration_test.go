@@ -72,6 +72,7 @@ targets:
 				},
 				Templates: &TemplateConfig{
 					FolderList:          "templates/folder-list.tpl",
+					FileVersions:        "templates/file-versions.tpl",
 					TargetList:          "templates/target-list.tpl",
 					NotFound:            "templates/not-found.tpl",
 					InternalServerError: "templates/internal-server-error.tpl",
@@ -136,6 +137,7 @@ targets:
 				},
 				Templates: &TemplateConfig{
 					FolderList:          "templates/folder-list.tpl",
+					FileVersions:        "templates/file-versions.tpl",
 					TargetList:          "templates/target-list.tpl",
 					NotFound:            "templates/not-found.tpl",
 					InternalServerError: "templates/internal-server-error.tpl",
@@ -250,6 +252,7 @@ targets:
 				},
 				Templates: &TemplateConfig{
 					FolderList:          "templates/folder-list.tpl",
+					FileVersions:        "templates/file-versions.tpl",
 					TargetList:          "templates/target-list.tpl",
 					NotFound:            "templates/not-found.tpl",
 					InternalServerError: "templates/internal-server-error.tpl",
@@ -348,6 +351,7 @@ targets:
 				},
 				Templates: &TemplateConfig{
 					FolderList:          "templates/folder-list.tpl",
+					FileVersions:        "templates/file-versions.tpl",
 					TargetList:          "templates/target-list.tpl",
 					NotFound:            "templates/not-found.tpl",
 					InternalServerError: "templates/internal-server-error.tpl",
@@ -429,6 +433,7 @@ targets:
 				},
 				Templates: &TemplateConfig{
 					FolderList:          "templates/folder-list.tpl",
+					FileVersions:        "templates/file-versions.tpl",
 					TargetList:          "templates/target-list.tpl",
 					NotFound:            "templates/not-found.tpl",
 					InternalServerError: "templates/internal-server-error.tpl",
@@ -609,6 +614,7 @@ targets:
 		},
 		Templates: &TemplateConfig{
 			FolderList:          "templates/folder-list.tpl",
+			FileVersions:        "templates/file-versions.tpl",
 			TargetList:          "templates/target-list.tpl",
 			NotFound:            "templates/not-found.tpl",
 			InternalServerError: "templates/internal-server-error.tpl",
@@ -680,6 +686,7 @@ log:
 			},
 			Templates: &TemplateConfig{
 				FolderList:          "templates/folder-list.tpl",
+				FileVersions:        "templates/file-versions.tpl",
 				TargetList:          "templates/target-list.tpl",
 				NotFound:            "templates/not-found.tpl",
 				InternalServerError: "templates/internal-server-error.tpl",
@@ -801,6 +808,7 @@ targets:
 		},
 		Templates: &TemplateConfig{
 			FolderList:          "templates/folder-list.tpl",
+			FileVersions:        "templates/file-versions.tpl",
 			TargetList:          "templates/target-list.tpl",
 			NotFound:            "templates/not-found.tpl",
 			InternalServerError: "templates/internal-server-error.tpl",
@@ -870,6 +878,7 @@ targets:
 			},
 			Templates: &TemplateConfig{
 				FolderList:          "templates/folder-list.tpl",
+				FileVersions:        "templates/file-versions.tpl",
 				TargetList:          "templates/target-list.tpl",
 				NotFound:            "templates/not-found.tpl",
 				InternalServerError: "templates/internal-server-error.tpl",
@@ -991,6 +1000,7 @@ targets:
 		},
 		Templates: &TemplateConfig{
 			FolderList:          "templates/folder-list.tpl",
+			FileVersions:        "templates/file-versions.tpl",
 			TargetList:          "templates/target-list.tpl",
 			NotFound:            "templates/not-found.tpl",
 			InternalServerError: "templates/internal-server-error.tpl",
@@ -1063,6 +1073,7 @@ configuration with error
 			},
 			Templates: &TemplateConfig{
 				FolderList:          "templates/folder-list.tpl",
+				FileVersions:        "templates/file-versions.tpl",
 				TargetList:          "templates/target-list.tpl",
 				NotFound:            "templates/not-found.tpl",
 				InternalServerError: "templates/internal-server-error.tpl",
@@ -1188,6 +1199,7 @@ targets:
 		},
 		Templates: &TemplateConfig{
 			FolderList:          "templates/folder-list.tpl",
+			FileVersions:        "templates/file-versions.tpl",
 			TargetList:          "templates/target-list.tpl",
 			NotFound:            "templates/not-found.tpl",
 			InternalServerError: "templates/internal-server-error.tpl",
@@ -1266,6 +1278,7 @@ authProviders:
 			},
 			Templates: &TemplateConfig{
 				FolderList:          "templates/folder-list.tpl",
+				FileVersions:        "templates/file-versions.tpl",
 				TargetList:          "templates/target-list.tpl",
 				NotFound:            "templates/not-found.tpl",
 				InternalServerError: "templates/internal-server-error.tpl",
@@ -1410,6 +1423,7 @@ targets:
 		Tracing: &TracingConfig{Enabled: false},
 		Templates: &TemplateConfig{
 			FolderList:          "templates/folder-list.tpl",
+			FileVersions:        "templates/file-versions.tpl",
 			TargetList:          "templates/target-list.tpl",
 			NotFound:            "templates/not-found.tpl",
 			InternalServerError: "templates/internal-server-error.tpl",
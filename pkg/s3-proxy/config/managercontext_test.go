@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/gobwas/glob"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -350,6 +351,165 @@ func Test_loadBusinessDefaultValues(t *testing.T) {
 				Tracing:     &TracingConfig{Enabled: false},
 			},
 		},
+		{
+			name: "Load default values for targets (key rewrite)",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Actions: &ActionsConfig{GET: &GetActionConfig{Enabled: false}},
+							Bucket:  &BucketConfig{Region: "test"},
+							KeyRewriteList: []*KeyRewriteConfig{
+								{
+									Source:        "^/v1/(.*)$",
+									Target:        "/v2/$1",
+									ReverseSource: "^/v2/(.*)$",
+									ReverseTarget: "/v1/$1",
+								},
+								{
+									Source: "^/v3/(.*)$",
+									Target: "/v2/$1",
+								},
+							},
+							Templates: &TargetTemplateConfig{},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			result: &Config{
+				Targets: []*TargetConfig{
+					{
+						Actions: &ActionsConfig{GET: &GetActionConfig{Enabled: false}},
+						Bucket:  &BucketConfig{Region: "test"},
+						KeyRewriteList: []*KeyRewriteConfig{
+							{
+								Source:              "^/v1/(.*)$",
+								Target:              "/v2/$1",
+								ReverseSource:       "^/v2/(.*)$",
+								ReverseTarget:       "/v1/$1",
+								SourceRegexp:        regexp.MustCompile("^/v1/(.*)$"),
+								ReverseSourceRegexp: regexp.MustCompile("^/v2/(.*)$"),
+							},
+							{
+								Source:       "^/v3/(.*)$",
+								Target:       "/v2/$1",
+								SourceRegexp: regexp.MustCompile("^/v3/(.*)$"),
+							},
+						},
+						Templates: &TargetTemplateConfig{},
+					},
+				},
+				ListTargets: &ListTargetsConfig{Enabled: false},
+				Tracing:     &TracingConfig{Enabled: false},
+			},
+		},
+		{
+			name: "Fail to load default values for targets (key rewrite invalid regexp)",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							KeyRewriteList: []*KeyRewriteConfig{{Source: "^/v1/(.*$"}},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Load default values for targets (response headers)",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Actions:         &ActionsConfig{GET: &GetActionConfig{Enabled: false}},
+							Bucket:          &BucketConfig{Region: "test"},
+							ResponseHeaders: []*ResponseHeadersConfig{{Path: "/mount1/*"}},
+							Templates:       &TargetTemplateConfig{},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			result: &Config{
+				Targets: []*TargetConfig{
+					{
+						Actions:         &ActionsConfig{GET: &GetActionConfig{Enabled: false}},
+						Bucket:          &BucketConfig{Region: "test"},
+						ResponseHeaders: []*ResponseHeadersConfig{{Path: "/mount1/*", PathGlob: glob.MustCompile("/mount1/*")}},
+						Templates:       &TargetTemplateConfig{},
+					},
+				},
+				ListTargets: &ListTargetsConfig{Enabled: false},
+				Tracing:     &TracingConfig{Enabled: false},
+			},
+		},
+		{
+			name: "Fail to load default values for targets (response headers invalid glob)",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							ResponseHeaders: []*ResponseHeadersConfig{{Path: "/mount1/*"}, {Path: "/mount1/[assets"}},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Load default values for targets (upload policy)",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Actions: &ActionsConfig{PUT: &PutActionConfig{Enabled: true, Config: &PutActionConfigConfig{
+								AllowedContentTypes: []string{"text/*"},
+								DeniedContentTypes:  []string{"text/html"},
+								AllowedFilenames:    []string{"*.txt"},
+							}}},
+							Bucket:    &BucketConfig{Region: "test"},
+							Templates: &TargetTemplateConfig{},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			result: &Config{
+				Targets: []*TargetConfig{
+					{
+						Actions: &ActionsConfig{PUT: &PutActionConfig{Enabled: true, Config: &PutActionConfigConfig{
+							AllowedContentTypes:      []string{"text/*"},
+							DeniedContentTypes:       []string{"text/html"},
+							AllowedFilenames:         []string{"*.txt"},
+							AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("text/*")},
+							DeniedContentTypesGlobs:  []glob.Glob{glob.MustCompile("text/html")},
+							AllowedFilenamesGlobs:    []glob.Glob{glob.MustCompile("*.txt")},
+						}}},
+						Bucket:    &BucketConfig{Region: "test"},
+						Templates: &TargetTemplateConfig{},
+					},
+				},
+				ListTargets: &ListTargetsConfig{Enabled: false},
+				Tracing:     &TracingConfig{Enabled: false},
+			},
+		},
+		{
+			name: "Fail to load default values for targets (upload policy invalid glob)",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Actions: &ActionsConfig{PUT: &PutActionConfig{Enabled: true, Config: &PutActionConfigConfig{
+								AllowedFilenames: []string{"*.pdf", "[a-"},
+							}}},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -529,6 +689,43 @@ func Test_loadAllCredentials(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Load target bucket encryption customer key",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Bucket: &BucketConfig{
+								Encryption: &BucketEncryptionConfig{
+									CustomerKey: &CredentialConfig{
+										Value: "value1",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			cfg: &Config{
+				Targets: []*TargetConfig{
+					{
+						Bucket: &BucketConfig{
+							Encryption: &BucketEncryptionConfig{
+								CustomerKey: &CredentialConfig{
+									Value: "value1",
+								},
+							},
+						},
+					},
+				},
+			},
+			result: []*CredentialConfig{
+				{
+					Value: "value1",
+				},
+			},
+		},
 		{
 			name: "Load list targets resource basic auth credentials",
 			args: args{
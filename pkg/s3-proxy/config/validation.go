@@ -7,7 +7,9 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"text/template"
 
+	"github.com/Masterminds/sprig"
 	"github.com/thoas/go-funk"
 )
 
@@ -59,6 +61,74 @@ func validateBusinessConfig(out *Config) error {
 		if !oneMustBeEnabled {
 			return fmt.Errorf("at least one action must be enabled in target %d", i)
 		}
+		// Check presigned URL expiry
+		if redirectCfg := target.GetPresignedURLRedirect(); redirectCfg != nil &&
+			(redirectCfg.Expiry < 0 || redirectCfg.Expiry > MaxPresignedURLExpiry) {
+			return fmt.Errorf("presigned url expiry in target %d must be between 0 and %s", i, MaxPresignedURLExpiry)
+		}
+		if uploadCfg := target.GetPresignedUpload(); uploadCfg != nil &&
+			(uploadCfg.Expiry < 0 || uploadCfg.Expiry > MaxPresignedURLExpiry) {
+			return fmt.Errorf("presigned upload expiry in target %d must be between 0 and %s", i, MaxPresignedURLExpiry)
+		}
+		// Check object cache configuration
+		if cacheCfg := target.GetObjectCache(); cacheCfg != nil {
+			if cacheCfg.Directory == "" {
+				return fmt.Errorf("object cache directory in target %d must be set", i)
+			}
+
+			if cacheCfg.TTL < 0 {
+				return fmt.Errorf("object cache ttl in target %d must be positive", i)
+			}
+		}
+		// Check listing cache configuration
+		if cacheCfg := target.GetListingCache(); cacheCfg != nil && cacheCfg.TTL < 0 {
+			return fmt.Errorf("listing cache ttl in target %d must be positive", i)
+		}
+		// Check precompressed configuration
+		if target.GetPrecompressed() != nil && target.GetPresignedURLRedirect() != nil {
+			return fmt.Errorf("precompressed and presigned url redirect can't be enabled together in target %d", i)
+		}
+		// Check website configuration
+		if websiteCfg := target.GetWebsite(); websiteCfg != nil {
+			if target.GetPresignedURLRedirect() != nil {
+				return fmt.Errorf("website and presigned url redirect can't be enabled together in target %d", i)
+			}
+
+			if strings.HasSuffix(websiteCfg.FallbackDocument, "/") {
+				return fmt.Errorf("website fallback document in target %d must be a file path", i)
+			}
+
+			if strings.Contains(websiteCfg.ErrorDocument, "/") {
+				return fmt.Errorf("website error document in target %d must be a file name without folder", i)
+			}
+		}
+		// Check assume role duration
+		err := validateBucketAssumeRole(i, target.Bucket)
+		if err != nil {
+			return err
+		}
+		// Check encryption
+		err = validateBucketEncryption(i, target)
+		if err != nil {
+			return err
+		}
+		// Check read failover configuration
+		err = validateReadFailover(i, target)
+		if err != nil {
+			return err
+		}
+		// Check write mirror configuration
+		err = validateWriteMirror(i, target)
+		if err != nil {
+			return err
+		}
+		// Check upload metadata and tags templates
+		if target.Actions.PUT != nil && target.Actions.PUT.Config != nil {
+			err = validatePutTemplates(i, target.Actions.PUT.Config)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Validate list targets object
@@ -119,15 +189,36 @@ func validateBusinessConfig(out *Config) error {
 	return nil
 }
 
+func validatePutTemplates(targetIndex int, putCfg *PutActionConfigConfig) error {
+	templateMaps := []struct {
+		name   string
+		values map[string]string
+	}{
+		{name: "metadata", values: putCfg.Metadata},
+		{name: "tag", values: putCfg.Tags},
+	}
+	for _, item := range templateMaps {
+		for k, v := range item.values {
+			// Check that value is a valid template
+			_, err := template.New(k).Funcs(sprig.TxtFuncMap()).Parse(v)
+			if err != nil {
+				return fmt.Errorf("%s %s in target %d is an invalid template: %v", item.name, k, targetIndex, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func validateResource(beginErrorMessage string, res *Resource, authProviders *AuthProviderConfig, mountPathList []string) error {
 	// Check resource http methods
 	// Filter http methods that are not supported
 	filtered := funk.FilterString(res.Methods, func(s string) bool {
-		return s != http.MethodGet && s != http.MethodPut && s != http.MethodDelete
+		return s != http.MethodGet && s != http.MethodHead && s != http.MethodPut && s != http.MethodDelete
 	})
 	// Check if size is > 0
 	if len(filtered) > 0 {
-		return errors.New(beginErrorMessage + " must have a HTTP method in GET, PUT or DELETE")
+		return errors.New(beginErrorMessage + " must have a HTTP method in GET, HEAD, PUT or DELETE")
 	}
 	// Check resource not valid
 	if res.WhiteList == nil && res.Basic == nil && res.OIDC == nil {
@@ -201,3 +292,123 @@ func validatePath(beginErrorMessage string, path string) error {
 	// Return no error
 	return nil
 }
+
+// validateBucketAssumeRole will check assume role duration of a bucket
+func validateBucketAssumeRole(targetIndex int, bucket *BucketConfig) error {
+	// Check if assume role is configured
+	if bucket.Credentials == nil || bucket.Credentials.AssumeRole == nil {
+		return nil
+	}
+
+	duration := bucket.Credentials.AssumeRole.Duration
+	// Session duration cannot be chosen with web identity
+	if duration != 0 && bucket.Credentials.AssumeRole.WebIdentityTokenFile != "" {
+		return fmt.Errorf("assume role duration in target %d cannot be used with web identity token file", targetIndex)
+	}
+
+	if duration != 0 && (duration < MinAssumeRoleDuration || duration > MaxAssumeRoleDuration) {
+		return fmt.Errorf("assume role duration in target %d must be between %s and %s", targetIndex, MinAssumeRoleDuration, MaxAssumeRoleDuration)
+	}
+
+	return nil
+}
+
+// validateReadFailover will check read failover configuration
+func validateReadFailover(targetIndex int, target *TargetConfig) error {
+	failoverCfg := target.GetReadFailover()
+	// Check if read failover is enabled
+	if failoverCfg == nil {
+		return nil
+	}
+
+	if len(failoverCfg.Buckets) == 0 {
+		return fmt.Errorf("read failover in target %d must have at least one bucket", targetIndex)
+	}
+
+	if failoverCfg.Timeout < 0 || failoverCfg.EjectionDuration < 0 {
+		return fmt.Errorf("read failover durations in target %d must be positive", targetIndex)
+	}
+
+	return validateSecondaryBuckets("read failover", targetIndex, target, failoverCfg.Buckets)
+}
+
+// validateWriteMirror will check write mirror configuration
+func validateWriteMirror(targetIndex int, target *TargetConfig) error {
+	mirrorCfg := target.GetWriteMirror()
+	// Check if write mirror is enabled
+	if mirrorCfg == nil {
+		return nil
+	}
+
+	if len(mirrorCfg.Buckets) == 0 {
+		return fmt.Errorf("write mirror in target %d must have at least one bucket", targetIndex)
+	}
+
+	if mirrorCfg.RetryDelay < 0 {
+		return fmt.Errorf("write mirror retry delay in target %d must be positive", targetIndex)
+	}
+
+	return validateSecondaryBuckets("write mirror", targetIndex, target, mirrorCfg.Buckets)
+}
+
+// validateSecondaryBuckets will check read failover or write mirror buckets
+// Keys are generated with target bucket prefix so all buckets must have the same one.
+func validateSecondaryBuckets(feature string, targetIndex int, target *TargetConfig, buckets []*BucketConfig) error {
+	// Loop over buckets
+	for j, bucket := range buckets {
+		if bucket.Prefix != target.Bucket.Prefix {
+			return fmt.Errorf("%s bucket %d in target %d must have the same prefix as target bucket", feature, j, targetIndex)
+		}
+
+		err := validateBucketAssumeRole(targetIndex, bucket)
+		if err != nil {
+			return err
+		}
+		// Check encryption with the same rules as target bucket
+		secondaryTarget := *target
+		secondaryTarget.Bucket = bucket
+
+		err = validateBucketEncryption(targetIndex, &secondaryTarget)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateBucketEncryption will check that server side encryption options are compatible
+func validateBucketEncryption(targetIndex int, target *TargetConfig) error {
+	encryptionCfg := target.Bucket.Encryption
+	// Check if encryption is configured
+	if encryptionCfg == nil {
+		return nil
+	}
+	// KMS key can only be used with KMS encryption
+	if encryptionCfg.KMSKeyID != "" && encryptionCfg.ServerSideEncryption != SSEAlgorithmKMS {
+		return fmt.Errorf("kms key id in target %d requires %s server side encryption", targetIndex, SSEAlgorithmKMS)
+	}
+	// Check customer key case
+	if encryptionCfg.CustomerKey == nil {
+		return nil
+	}
+
+	if encryptionCfg.ServerSideEncryption != "" {
+		return fmt.Errorf("server side encryption and customer key cannot be used together in target %d", targetIndex)
+	}
+	// Customer key must be sent by client with presigned URLs so it cannot be used
+	if target.GetPresignedURLRedirect() != nil || target.GetPresignedUpload() != nil {
+		return fmt.Errorf("presigned urls cannot be used with customer key in target %d", targetIndex)
+	}
+	// Objects would be stored decrypted on local disk
+	if target.GetObjectCache() != nil {
+		return fmt.Errorf("object cache cannot be used with customer key in target %d", targetIndex)
+	}
+
+	_, err := encryptionCfg.GetCustomerKey()
+	if err != nil {
+		return fmt.Errorf("%v in target %d", err, targetIndex)
+	}
+
+	return nil
+}
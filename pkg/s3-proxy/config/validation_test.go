@@ -4,6 +4,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func Test_validatePath(t *testing.T) {
@@ -76,7 +77,7 @@ func Test_validateResource(t *testing.T) {
 				mountPathList: []string{"/"},
 			},
 			wantErr:     true,
-			errorString: "begin error must have a HTTP method in GET, PUT or DELETE",
+			errorString: "begin error must have a HTTP method in GET, HEAD, PUT or DELETE",
 		},
 		{
 			name: "Resource don't have a valid http method (2)",
@@ -89,7 +90,7 @@ func Test_validateResource(t *testing.T) {
 				mountPathList: []string{"/"},
 			},
 			wantErr:     true,
-			errorString: "begin error must have a HTTP method in GET, PUT or DELETE",
+			errorString: "begin error must have a HTTP method in GET, HEAD, PUT or DELETE",
 		},
 		{
 			name: "Resource don't have any whitelist or authentication settings",
@@ -290,6 +291,27 @@ func Test_validateResource(t *testing.T) {
 			wantErr:     false,
 			errorString: "",
 		},
+		{
+			name: "Resource with all http methods is valid",
+			args: args{
+				beginErrorMessage: "begin error",
+				res: &Resource{
+					Methods:   []string{"GET", "HEAD", "PUT", "DELETE"},
+					WhiteList: &falseValue,
+					Provider:  "test",
+					Basic:     &ResourceBasic{},
+					Path:      "/v1/test/",
+				},
+				authProviders: &AuthProviderConfig{
+					Basic: map[string]*BasicAuthConfig{
+						"test": {},
+					},
+				},
+				mountPathList: []string{"/v1/"},
+			},
+			wantErr:     false,
+			errorString: "",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -314,6 +336,649 @@ func Test_validateBusinessConfig(t *testing.T) {
 		wantErr     bool
 		errorString string
 	}{
+		{
+			name: "Upload tag contains an invalid template",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								PUT: &PutActionConfig{
+									Enabled: true,
+									Config: &PutActionConfigConfig{
+										Metadata: map[string]string{"uploader": "{{ .User.Identifier }}"},
+										Tags:     map[string]string{"uploader": "{{ .User.Identifier"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "tag uploader in target 0 is an invalid template: template: uploader:1: unclosed action",
+		},
+		{
+			name: "Encryption kms key id without kms encryption",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:       "bucket1",
+								Region:     "region1",
+								Encryption: &BucketEncryptionConfig{ServerSideEncryption: "AES256", KMSKeyID: "key"},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "kms key id in target 0 requires aws:kms server side encryption",
+		},
+		{
+			name: "Encryption server side encryption with customer key",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:       "bucket1",
+								Region:     "region1",
+								Encryption: &BucketEncryptionConfig{ServerSideEncryption: "AES256", CustomerKey: &CredentialConfig{Value: "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="}},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "server side encryption and customer key cannot be used together in target 0",
+		},
+		{
+			name: "Encryption customer key with presigned url redirect",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:       "bucket1",
+								Region:     "region1",
+								Encryption: &BucketEncryptionConfig{CustomerKey: &CredentialConfig{Value: "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="}},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true, Config: &GetActionConfigConfig{PresignedURLRedirect: &PresignedURLRedirectConfig{Enabled: true}}},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "presigned urls cannot be used with customer key in target 0",
+		},
+		{
+			name: "Encryption customer key with object cache",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:       "bucket1",
+								Region:     "region1",
+								Encryption: &BucketEncryptionConfig{CustomerKey: &CredentialConfig{Value: "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="}},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							ObjectCache: &ObjectCacheConfig{Enabled: true, Directory: "/tmp/cache"},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "object cache cannot be used with customer key in target 0",
+		},
+		{
+			name: "Encryption customer key isn't a 256 bits key",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:       "bucket1",
+								Region:     "region1",
+								Encryption: &BucketEncryptionConfig{CustomerKey: &CredentialConfig{Value: "c2hvcnQ="}},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "encryption customer key must be a base64 encoded 256 bits key in target 0",
+		},
+		{
+			name: "Encryption kms key id with kms encryption is valid",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:       "bucket1",
+								Region:     "region1",
+								Encryption: &BucketEncryptionConfig{ServerSideEncryption: "aws:kms", KMSKeyID: "key"},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     false,
+			errorString: "",
+		},
+		{
+			name: "Presigned url expiry is too long",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{
+									Enabled: true,
+									Config: &GetActionConfigConfig{
+										PresignedURLRedirect: &PresignedURLRedirectConfig{
+											Enabled: true,
+											Expiry:  8 * 24 * time.Hour,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "presigned url expiry in target 0 must be between 0 and 168h0m0s",
+		},
+		{
+			name: "Object cache without directory",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+							ObjectCache: &ObjectCacheConfig{Enabled: true},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "object cache directory in target 0 must be set",
+		},
+		{
+			name: "Object cache with negative ttl",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+							ObjectCache: &ObjectCacheConfig{Enabled: true, Directory: "/tmp/cache", TTL: -1},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "object cache ttl in target 0 must be positive",
+		},
+		{
+			name: "Listing cache with negative ttl",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+							ListingCache: &ListingCacheConfig{Enabled: true, TTL: -1},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "listing cache ttl in target 0 must be positive",
+		},
+		{
+			name: "Read failover without bucket",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+								Prefix: "prefix/",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+							ReadFailover: &ReadFailoverConfig{Enabled: true},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "read failover in target 0 must have at least one bucket",
+		},
+		{
+			name: "Read failover bucket with another prefix",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+								Prefix: "prefix/",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+							ReadFailover: &ReadFailoverConfig{
+								Enabled: true,
+								Buckets: []*BucketConfig{{Name: "bucket2", Region: "region2"}},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "read failover bucket 0 in target 0 must have the same prefix as target bucket",
+		},
+		{
+			name: "Write mirror bucket with another prefix",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+							WriteMirror: &WriteMirrorConfig{
+								Enabled: true,
+								Buckets: []*BucketConfig{{Name: "bucket2", Region: "region2", Prefix: "prefix/"}},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "write mirror bucket 0 in target 0 must have the same prefix as target bucket",
+		},
+		{
+			name: "Write mirror with a negative retry delay",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+							WriteMirror: &WriteMirrorConfig{
+								Enabled:    true,
+								Buckets:    []*BucketConfig{{Name: "bucket2", Region: "region2"}},
+								RetryDelay: -time.Second,
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "write mirror retry delay in target 0 must be positive",
+		},
+		{
+			name: "Precompressed with presigned url redirect",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{
+									Enabled: true,
+									Config: &GetActionConfigConfig{
+										PresignedURLRedirect: &PresignedURLRedirectConfig{Enabled: true},
+										Precompressed:        &PrecompressedConfig{Enabled: true},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "precompressed and presigned url redirect can't be enabled together in target 0",
+		},
+		{
+			name: "Website with presigned url redirect",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{
+									Enabled: true,
+									Config: &GetActionConfigConfig{
+										PresignedURLRedirect: &PresignedURLRedirectConfig{Enabled: true},
+										Website:              &WebsiteConfig{Enabled: true},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "website and presigned url redirect can't be enabled together in target 0",
+		},
+		{
+			name: "Website fallback document is a folder",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{
+									Enabled: true,
+									Config: &GetActionConfigConfig{
+										Website: &WebsiteConfig{Enabled: true, FallbackDocument: "/app/"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "website fallback document in target 0 must be a file path",
+		},
+		{
+			name: "Website error document contains a folder",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{
+									Enabled: true,
+									Config: &GetActionConfigConfig{
+										Website: &WebsiteConfig{Enabled: true, ErrorDocument: "errors/404.html"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "website error document in target 0 must be a file name without folder",
+		},
+		{
+			name: "Assume role duration is too short",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+								Credentials: &BucketCredentialConfig{
+									AssumeRole: &AssumeRoleConfig{
+										RoleARN:  "arn:aws:iam::123456789012:role/test",
+										Duration: time.Minute,
+									},
+								},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "assume role duration in target 0 must be between 15m0s and 12h0m0s",
+		},
+		{
+			name: "Assume role duration with web identity",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+								Credentials: &BucketCredentialConfig{
+									AssumeRole: &AssumeRoleConfig{
+										RoleARN:              "arn:aws:iam::123456789012:role/test",
+										Duration:             time.Hour,
+										WebIdentityTokenFile: "/var/run/secrets/token",
+									},
+								},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errorString: "assume role duration in target 0 cannot be used with web identity token file",
+		},
+		{
+			name: "Assume role duration is valid",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+								Credentials: &BucketCredentialConfig{
+									AssumeRole: &AssumeRoleConfig{
+										RoleARN:  "arn:aws:iam::123456789012:role/test",
+										Duration: time.Hour,
+									},
+								},
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Target names can be used by several targets",
+			args: args{
+				out: &Config{
+					Targets: []*TargetConfig{
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket1",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount1/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+						{
+							Name: "test1",
+							Bucket: &BucketConfig{
+								Name:   "bucket2",
+								Region: "region1",
+							},
+							Mount: &MountConfig{
+								Path: []string{"/mount2/"},
+							},
+							Actions: &ActionsConfig{
+								GET: &GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "Path is invalid in target",
 			args: args{
@@ -10,6 +10,18 @@ type Client interface {
 	GetExposeHandler() http.Handler
 	// Will increase counter of S3 operations done by service
 	IncS3Operations(targetName, bucketName, operation string)
+	// Will increase counter of object cache hits
+	IncObjectCacheHits(targetName, bucketName string)
+	// Will increase counter of object cache misses
+	IncObjectCacheMisses(targetName, bucketName string)
+	// Will increase counter of listing cache hits
+	IncListingCacheHits(targetName, bucketName string)
+	// Will increase counter of listing cache misses
+	IncListingCacheMisses(targetName, bucketName string)
+	// Will increase counter of read operations served by a bucket of a target with read failover
+	IncReadFailoverServed(targetName, bucketName, operation string)
+	// Will increase counter of failed writes on write mirror buckets
+	IncWriteMirrorFailures(targetName, bucketName, operation string)
 	// Will increase counter of authenticated user
 	IncAuthenticated(providerType, providerName string)
 	// Will increase counter of authorized user
@@ -10,14 +10,20 @@ import (
 )
 
 type prometheusClient struct {
-	reqCnt             *prometheus.CounterVec
-	resSz              *prometheus.SummaryVec
-	reqDur             *prometheus.SummaryVec
-	reqSz              *prometheus.SummaryVec
-	up                 *prometheus.GaugeVec
-	s3OperationsTotal  *prometheus.CounterVec
-	authenticatedTotal *prometheus.CounterVec
-	authorizedTotal    *prometheus.CounterVec
+	reqCnt              *prometheus.CounterVec
+	resSz               *prometheus.SummaryVec
+	reqDur              *prometheus.SummaryVec
+	reqSz               *prometheus.SummaryVec
+	up                  *prometheus.GaugeVec
+	s3OperationsTotal   *prometheus.CounterVec
+	authenticatedTotal  *prometheus.CounterVec
+	authorizedTotal     *prometheus.CounterVec
+	objectCacheHits     *prometheus.CounterVec
+	objectCacheMisses   *prometheus.CounterVec
+	listingCacheHits    *prometheus.CounterVec
+	listingCacheMisses  *prometheus.CounterVec
+	readFailoverServed  *prometheus.CounterVec
+	writeMirrorFailures *prometheus.CounterVec
 }
 
 // Instrument will instrument gin routes
@@ -59,6 +65,36 @@ func (ctx *prometheusClient) IncS3Operations(targetName, bucketName, operation s
 	ctx.s3OperationsTotal.WithLabelValues(targetName, bucketName, operation).Inc()
 }
 
+// IncObjectCacheHits Increment object cache hits counter
+func (ctx *prometheusClient) IncObjectCacheHits(targetName, bucketName string) {
+	ctx.objectCacheHits.WithLabelValues(targetName, bucketName).Inc()
+}
+
+// IncObjectCacheMisses Increment object cache misses counter
+func (ctx *prometheusClient) IncObjectCacheMisses(targetName, bucketName string) {
+	ctx.objectCacheMisses.WithLabelValues(targetName, bucketName).Inc()
+}
+
+// IncListingCacheHits Increment listing cache hits counter
+func (ctx *prometheusClient) IncListingCacheHits(targetName, bucketName string) {
+	ctx.listingCacheHits.WithLabelValues(targetName, bucketName).Inc()
+}
+
+// IncListingCacheMisses Increment listing cache misses counter
+func (ctx *prometheusClient) IncListingCacheMisses(targetName, bucketName string) {
+	ctx.listingCacheMisses.WithLabelValues(targetName, bucketName).Inc()
+}
+
+// IncReadFailoverServed Increment counter of read operations served by a bucket with read failover
+func (ctx *prometheusClient) IncReadFailoverServed(targetName, bucketName, operation string) {
+	ctx.readFailoverServed.WithLabelValues(targetName, bucketName, operation).Inc()
+}
+
+// IncWriteMirrorFailures Increment counter of failed writes on a write mirror bucket
+func (ctx *prometheusClient) IncWriteMirrorFailures(targetName, bucketName, operation string) {
+	ctx.writeMirrorFailures.WithLabelValues(targetName, bucketName, operation).Inc()
+}
+
 // Will increase counter of authenticated user
 func (ctx *prometheusClient) IncAuthenticated(providerType, providerName string) {
 	ctx.authenticatedTotal.WithLabelValues(providerType, providerName).Inc()
@@ -125,6 +161,60 @@ func (ctx *prometheusClient) register() {
 	)
 	prometheus.MustRegister(ctx.s3OperationsTotal)
 
+	ctx.objectCacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "object_cache_hits_total",
+			Help: "How many objects have been answered from local disk cache ?",
+		},
+		[]string{"target_name", "bucket_name"},
+	)
+	prometheus.MustRegister(ctx.objectCacheHits)
+
+	ctx.objectCacheMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "object_cache_misses_total",
+			Help: "How many objects have been downloaded from s3 because they weren't in local disk cache or changed ?",
+		},
+		[]string{"target_name", "bucket_name"},
+	)
+	prometheus.MustRegister(ctx.objectCacheMisses)
+
+	ctx.listingCacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "listing_cache_hits_total",
+			Help: "How many folder listings have been answered from memory cache ?",
+		},
+		[]string{"target_name", "bucket_name"},
+	)
+	prometheus.MustRegister(ctx.listingCacheHits)
+
+	ctx.listingCacheMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "listing_cache_misses_total",
+			Help: "How many folder listings have been requested to s3 because they weren't in memory cache or expired ?",
+		},
+		[]string{"target_name", "bucket_name"},
+	)
+	prometheus.MustRegister(ctx.listingCacheMisses)
+
+	ctx.readFailoverServed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "read_failover_served_total",
+			Help: "How many read operations have been served by each bucket of targets with read failover ?",
+		},
+		[]string{"target_name", "bucket_name", "operation"},
+	)
+	prometheus.MustRegister(ctx.readFailoverServed)
+
+	ctx.writeMirrorFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "write_mirror_failures_total",
+			Help: "How many write operations have failed on each bucket of targets with write mirror ?",
+		},
+		[]string{"target_name", "bucket_name", "operation"},
+	)
+	prometheus.MustRegister(ctx.writeMirrorFailures)
+
 	ctx.authenticatedTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "authenticated_total",
@@ -3,26 +3,33 @@ package s3client
 import (
 	"errors"
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
-	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
-	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/tracing"
 )
 
 // Client S3 Context interface
 type Client interface {
-	ListFilesAndDirectories(key string) ([]*ListElementOutput, error)
+	ListFilesAndDirectories(input *ListInput) (*ListOutput, error)
+	ListAllObjects(input *ListAllObjectsInput) (*ListAllObjectsOutput, error)
 	HeadObject(key string) (*HeadOutput, error)
-	GetObject(key string) (*GetOutput, error)
+	GetObject(input *GetInput) (*GetOutput, error)
+	PresignGetObject(input *PresignGetInput) (string, error)
+	PresignPutObject(input *PresignPutInput) (*PresignPutOutput, error)
+	PresignPostObject(input *PresignPutInput) (*PresignPostOutput, error)
 	PutObject(input *PutInput) error
-	DeleteObject(key string) error
+	DeleteObject(input *DeleteInput) error
+	ListObjectVersions(key string) ([]*ObjectVersionOutput, error)
+	DeleteFolder(input *DeleteFolderInput) (*DeleteFolderOutput, error)
 }
 
 // FileType File type
@@ -31,6 +38,9 @@ const FileType = "FILE"
 // FolderType Folder type
 const FolderType = "FOLDER"
 
+// assumeRoleExpiryWindow Window before expiration in which assumed role credentials are refreshed
+const assumeRoleExpiryWindow = time.Minute
+
 // ListElementOutput Bucket ListElementOutput
 type ListElementOutput struct {
 	Type         string
@@ -41,15 +51,130 @@ type ListElementOutput struct {
 	Key          string
 }
 
+// ListInput represents input of a list request
+type ListInput struct {
+	Key               string
+	ContinuationToken string
+	// MaxKeys is the maximum number of elements returned. It must be > 0.
+	MaxKeys int64
+}
+
+// ListOutput represents output of a list request
+type ListOutput struct {
+	Entries []*ListElementOutput
+	// NextContinuationToken is set when there are more elements to list
+	NextContinuationToken string
+}
+
+// ListAllObjectsInput represents input of a recursive list request
+type ListAllObjectsInput struct {
+	// Prefix is the folder key. All objects under this prefix will be listed.
+	Prefix string
+	// MaxObjects is the maximum number of objects listed. It must be > 0.
+	MaxObjects int64
+}
+
+// ListAllObjectsOutput represents output of a recursive list request
+type ListAllObjectsOutput struct {
+	Entries []*ListElementOutput
+	// Truncated is set when there are more objects than the maximum number asked
+	Truncated bool
+}
+
 // HeadOutput represents output of Head
 type HeadOutput struct {
-	Type string
-	Key  string
+	Type               string
+	Key                string
+	CacheControl       string
+	Expires            string
+	ContentDisposition string
+	ContentEncoding    string
+	ContentLanguage    string
+	ContentLength      int64
+	ContentType        string
+	ETag               string
+	LastModified       time.Time
+}
+
+// DeleteInput represents input of an object deletion
+type DeleteInput struct {
+	Key string
+	// VersionID is the object version to delete (current object if empty)
+	VersionID string
+}
+
+// ObjectVersionOutput represents an object version
+type ObjectVersionOutput struct {
+	VersionID    string
+	ETag         string
+	LastModified time.Time
+	Size         int64
+	IsLatest     bool
+	DeleteMarker bool
+}
+
+// DeleteFolderInput represents input of a folder deletion
+type DeleteFolderInput struct {
+	// Prefix is the folder key. All objects under this prefix will be deleted.
+	Prefix string
+	// DryRun will only list objects that would be deleted
+	DryRun bool
+}
+
+// DeleteFolderOutput represents output of a folder deletion
+type DeleteFolderOutput struct {
+	// DeletedKeys are keys deleted (or that would be deleted in dry run mode)
+	DeletedKeys []string
+	// Errors are errors for keys that cannot be deleted
+	Errors []*DeleteErrorOutput
+}
+
+// DeleteErrorOutput represents an error on a key deletion
+type DeleteErrorOutput struct {
+	Key     string
+	Code    string
+	Message string
 }
 
 // ErrNotFound Error not found
 var ErrNotFound = errors.New("not found")
 
+// ErrRangeNotSatisfiable Error raised when requested range cannot be satisfied
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ErrNotModified Error raised when object hasn't been modified following conditions
+var ErrNotModified = errors.New("not modified")
+
+// ErrPreconditionFailed Error raised when object doesn't match conditions
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// GetInput Input object for GET request
+type GetInput struct {
+	Key               string
+	Range             string
+	IfRange           string
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+	// VersionID is the object version to get (current object if empty)
+	VersionID string
+	// HeadOnly will get object headers with an empty body (range is ignored)
+	HeadOnly bool
+}
+
+// PresignGetInput Input object for presigned GET URL generation
+type PresignGetInput struct {
+	Key                        string
+	VersionID                  string
+	Expiry                     time.Duration
+	ResponseCacheControl       string
+	ResponseContentDisposition string
+	ResponseContentEncoding    string
+	ResponseContentLanguage    string
+	ResponseContentType        string
+}
+
 // GetOutput Object output for S3 get object
 type GetOutput struct {
 	Body               *io.ReadCloser
@@ -63,6 +188,8 @@ type GetOutput struct {
 	ContentType        string
 	ETag               string
 	LastModified       time.Time
+	// WebsiteRedirectLocation is the x-amz-website-redirect-location metadata
+	WebsiteRedirectLocation string
 }
 
 // PutInput Put input object for PUT request
@@ -71,11 +198,39 @@ type PutInput struct {
 	Body         io.Reader
 	ContentType  string
 	Metadata     map[string]string
+	Tags         map[string]string
+	StorageClass string
+}
+
+// PresignPutInput Input object for presigned upload generation
+type PresignPutInput struct {
+	Key          string
+	Expiry       time.Duration
+	Metadata     map[string]string
+	Tags         map[string]string
 	StorageClass string
+	// ContentType is signed when it isn't empty
+	ContentType string
+	// Size is signed when it is declared (-1 otherwise)
+	Size int64
+	// MaxSize is enforced on presigned POST uploads when size isn't declared
+	MaxSize int64
+}
+
+// PresignPutOutput Presigned PUT upload with headers that must be sent
+type PresignPutOutput struct {
+	URL     string
+	Headers map[string]string
 }
 
-// NewS3Context New S3 Context
-func NewS3Context(tgt *config.TargetConfig, logger log.Logger, metricsCtx metrics.Client, parentTrace tracing.Trace) (Client, error) {
+// PresignPostOutput Presigned POST upload with form fields that must be sent
+type PresignPostOutput struct {
+	URL    string
+	Fields map[string]string
+}
+
+// newS3Context will create a new S3 context for a target without any request context
+func newS3Context(tgt *config.TargetConfig, metricsCtx metrics.Client) (*s3Context, error) {
 	sessionConfig := &aws.Config{
 		Region: aws.String(tgt.Bucket.Region),
 	}
@@ -92,11 +247,23 @@ func NewS3Context(tgt *config.TargetConfig, logger log.Logger, metricsCtx metric
 	if tgt.Bucket.DisableSSL {
 		sessionConfig.DisableSSL = aws.Bool(true)
 	}
+	// Set a response timeout to fail over quickly when read failover is enabled
+	if failoverCfg := tgt.GetReadFailover(); failoverCfg != nil && failoverCfg.Timeout > 0 {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = failoverCfg.Timeout
+		sessionConfig.HTTPClient = &http.Client{Transport: transport}
+	}
 	// Create session
 	sess, err := session.NewSession(sessionConfig)
 	if err != nil {
 		return nil, err
 	}
+	// Assume role if it is configured
+	if tgt.Bucket.Credentials != nil && tgt.Bucket.Credentials.AssumeRole != nil {
+		sess = sess.Copy(&aws.Config{
+			Credentials: newAssumeRoleCredentials(sess, tgt.Bucket),
+		})
+	}
 	// Create s3 client
 	svcClient := s3.New(sess)
 
@@ -106,9 +273,46 @@ func NewS3Context(tgt *config.TargetConfig, logger log.Logger, metricsCtx metric
 	return &s3Context{
 		svcClient:   svcClient,
 		uploader:    uploader,
-		logger:      logger,
+		credentials: sess.Config.Credentials,
 		target:      tgt,
 		metricsCtx:  metricsCtx,
-		parentTrace: parentTrace,
 	}, nil
 }
+
+// newAssumeRoleCredentials will create auto refreshed credentials from STS assume role
+// (or assume role with web identity when a token file is configured).
+// Base session credentials are used to call STS.
+func newAssumeRoleCredentials(sess *session.Session, bucketCfg *config.BucketConfig) *credentials.Credentials {
+	assumeRoleCfg := bucketCfg.Credentials.AssumeRole
+	// Create STS client with custom endpoint if it exists
+	stsCfg := &aws.Config{}
+	if bucketCfg.STSEndpoint != "" {
+		stsCfg.Endpoint = aws.String(bucketCfg.STSEndpoint)
+	}
+
+	stsClient := sts.New(sess, stsCfg)
+	// Check if web identity must be used
+	if assumeRoleCfg.WebIdentityTokenFile != "" {
+		provider := stscreds.NewWebIdentityRoleProvider(
+			stsClient,
+			assumeRoleCfg.RoleARN,
+			assumeRoleCfg.GetSessionName(),
+			assumeRoleCfg.WebIdentityTokenFile,
+		)
+		// Refresh credentials before they expire
+		provider.ExpiryWindow = assumeRoleExpiryWindow
+
+		return credentials.NewCredentials(provider)
+	}
+
+	return stscreds.NewCredentialsWithClient(stsClient, assumeRoleCfg.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+		p.RoleSessionName = assumeRoleCfg.GetSessionName()
+		p.Duration = assumeRoleCfg.GetDuration()
+		// Refresh credentials before they expire
+		p.ExpiryWindow = assumeRoleExpiryWindow
+		// Add external id if it exists
+		if assumeRoleCfg.ExternalID != "" {
+			p.ExternalID = aws.String(assumeRoleCfg.ExternalID)
+		}
+	})
+}
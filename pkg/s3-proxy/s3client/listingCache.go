@@ -0,0 +1,149 @@
+package s3client
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+)
+
+// listingCacheKey identifies a folder listing page
+type listingCacheKey struct {
+	key               string
+	continuationToken string
+	maxKeys           int64
+}
+
+// listingCacheEntry represents a cached folder listing page
+type listingCacheEntry struct {
+	cacheKey  listingCacheKey
+	output    *ListOutput
+	expiresAt time.Time
+}
+
+// listingCache is a size bounded in-memory cache of folder listing pages
+// It is shared by all requests of a target.
+type listingCache struct {
+	ttl        time.Duration
+	maxEntries int
+	mutex      sync.Mutex
+	// lru contains entries from the most recently used to the least recently used
+	lru     *list.List
+	entries map[listingCacheKey]*list.Element
+	// generation is increased on each invalidation to ignore listings done before it
+	generation uint64
+}
+
+// newListingCache will create a new listing cache for a target
+func newListingCache(cacheCfg *config.ListingCacheConfig) *listingCache {
+	return &listingCache{
+		ttl:        cacheCfg.GetTTL(),
+		maxEntries: cacheCfg.GetMaxEntries(),
+		lru:        list.New(),
+		entries:    map[listingCacheKey]*list.Element{},
+	}
+}
+
+// newListingCacheKey will create the cache key of a listing request
+func newListingCacheKey(input *ListInput) listingCacheKey {
+	return listingCacheKey{
+		key:               input.Key,
+		continuationToken: input.ContinuationToken,
+		maxKeys:           input.MaxKeys,
+	}
+}
+
+// get will get a copy of a cached listing (nil if not found or expired)
+func (c *listingCache) get(input *ListInput) *ListOutput {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Check if entry exists
+	elem, ok := c.entries[newListingCacheKey(input)]
+	if !ok {
+		return nil
+	}
+	// Check if entry is expired
+	entry := elem.Value.(*listingCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(elem)
+
+		return nil
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return copyListOutput(entry.output)
+}
+
+// getGeneration will get current invalidation generation
+func (c *listingCache) getGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// add will add a listing in cache and will evict least recently used entries if needed
+// Listing is ignored if an invalidation occurred since the listing began.
+func (c *listingCache) add(input *ListInput, output *ListOutput, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Check if an invalidation occurred during listing
+	if generation != c.generation {
+		return
+	}
+
+	cacheKey := newListingCacheKey(input)
+	// Replace previous entry
+	if elem, ok := c.entries[cacheKey]; ok {
+		c.removeElement(elem)
+	}
+
+	c.entries[cacheKey] = c.lru.PushFront(&listingCacheEntry{
+		cacheKey:  cacheKey,
+		output:    copyListOutput(output),
+		expiresAt: time.Now().Add(c.ttl),
+	})
+	// Evict least recently used entries
+	for c.lru.Len() > c.maxEntries {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// invalidate will remove listings of all folders containing key
+// Listings under key are also removed when key is a folder.
+func (c *listingCache) invalidate(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	// Loop over entries
+	for cacheKey, elem := range c.entries {
+		if strings.HasPrefix(key, cacheKey.key) || strings.HasPrefix(cacheKey.key, key) {
+			c.removeElement(elem)
+		}
+	}
+}
+
+// removeElement will remove an entry (mutex must be locked)
+func (c *listingCache) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*listingCacheEntry).cacheKey)
+}
+
+// copyListOutput will copy a listing to avoid modifications of cached entries
+func copyListOutput(output *ListOutput) *ListOutput {
+	entries := make([]*ListElementOutput, 0, len(output.Entries))
+	// Loop over entries
+	for _, item := range output.Entries {
+		entry := *item
+		entries = append(entries, &entry)
+	}
+
+	return &ListOutput{
+		Entries:               entries,
+		NextContinuationToken: output.NextContinuationToken,
+	}
+}
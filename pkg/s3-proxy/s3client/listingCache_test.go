@@ -0,0 +1,85 @@
+// +build unit
+
+package s3client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_listingCache(t *testing.T) {
+	cache := newListingCache(&config.ListingCacheConfig{Enabled: true, TTL: time.Hour, MaxEntries: 3})
+	assert.Equal(t, time.Hour, cache.ttl)
+
+	newOutput := func(name string) *ListOutput {
+		return &ListOutput{Entries: []*ListElementOutput{{Type: FileType, Name: name, Key: name}}}
+	}
+	// add will add a listing in cache with current generation
+	add := func(input *ListInput, name string) {
+		cache.add(input, newOutput(name), cache.getGeneration())
+	}
+	// getName will get name of the first entry of a cached listing
+	getName := func(input *ListInput) string {
+		output := cache.get(input)
+		if output == nil {
+			return ""
+		}
+		return output.Entries[0].Name
+	}
+	root := &ListInput{Key: "", MaxKeys: 10}
+	dir := &ListInput{Key: "dir/", MaxKeys: 10}
+	dirPage2 := &ListInput{Key: "dir/", MaxKeys: 10, ContinuationToken: "token"}
+	subDir := &ListInput{Key: "dir/sub/", MaxKeys: 10}
+	other := &ListInput{Key: "other/", MaxKeys: 10}
+
+	// Pages are cached separately
+	add(dir, "page1")
+	add(dirPage2, "page2")
+	assert.Equal(t, "page1", getName(dir))
+	assert.Equal(t, "page2", getName(dirPage2))
+	assert.Equal(t, "", getName(&ListInput{Key: "dir/", MaxKeys: 5}))
+
+	// Cached listings cannot be modified by callers
+	cache.get(dir).Entries[0].Name = "modified"
+	assert.Equal(t, "page1", getName(dir))
+
+	// Least recently used entries are evicted
+	add(root, "root")
+	add(other, "other")
+	assert.Equal(t, 3, cache.lru.Len())
+	assert.Equal(t, "", getName(dirPage2))
+	assert.Equal(t, "page1", getName(dir))
+
+	// Write invalidates parent folders only
+	add(subDir, "sub")
+	cache.invalidate("dir/sub/file")
+	assert.Equal(t, "", getName(root))
+	assert.Equal(t, "", getName(dir))
+	assert.Equal(t, "", getName(subDir))
+	add(other, "other")
+	assert.Equal(t, "other", getName(other))
+
+	// Folder deletion invalidates sub folders
+	add(dir, "page1")
+	add(subDir, "sub")
+	cache.invalidate("dir/")
+	assert.Equal(t, "", getName(subDir))
+	assert.Equal(t, "", getName(dir))
+	assert.Equal(t, "other", getName(other))
+
+	// Listings done before an invalidation aren't cached
+	generation := cache.getGeneration()
+	cache.invalidate("dir/file")
+	cache.add(dir, newOutput("stale"), generation)
+	assert.Equal(t, "", getName(dir))
+
+	// Expired entries are removed
+	cache.ttl = -time.Second
+	add(dir, "page1")
+	assert.Equal(t, "", getName(dir))
+	_, ok := cache.entries[newListingCacheKey(dir)]
+	assert.False(t, ok)
+}
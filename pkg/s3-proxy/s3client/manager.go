@@ -0,0 +1,227 @@
+package s3client
+
+import (
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/tracing"
+)
+
+// Manager S3 client manager
+// It will keep one S3 session per target to reuse them between requests.
+type Manager interface {
+	// GetClientForTarget will return a S3 client for the target configuration with the request logger and trace.
+	// Result will be nil if target isn't managed.
+	GetClientForTarget(tgt *config.TargetConfig, logger log.Logger, parentTrace tracing.Trace) Client
+	// Close will release resources of S3 clients (like object cache files) and will wait for asynchronous mirror writes.
+	// It must be called when manager is replaced after a configuration reload and when server is stopped.
+	Close() error
+}
+
+// manager keeps S3 contexts by target configuration because target names aren't unique
+type manager struct {
+	targetClients map[*config.TargetConfig]*s3Context
+	// readFailovers contains buckets used for reads of targets with read failover (target bucket first)
+	readFailovers map[*config.TargetConfig][]*readFailoverBucket
+	// writeMirrors contains mirror buckets of targets with write mirror
+	writeMirrors map[*config.TargetConfig]*writeMirror
+}
+
+// readFailoverBucket is a bucket S3 context used for reads with its health state shared between requests
+type readFailoverBucket struct {
+	s3ctx  *s3Context
+	health *bucketHealth
+}
+
+// writeMirror contains mirror buckets of a target with resources shared between requests
+type writeMirror struct {
+	mirrors []*s3Context
+	// queue is used for asynchronous writes (with async policy only)
+	queue *mirrorQueue
+	spool *mirrorSpool
+}
+
+// NewManager will create a new S3 client manager with all targets from configuration
+// Logger is used for background tasks that aren't linked to a request (like asynchronous mirror writes).
+func NewManager(cfg *config.Config, metricsCtx metrics.Client, logger log.Logger) (Manager, error) {
+	targetClients := map[*config.TargetConfig]*s3Context{}
+	readFailovers := map[*config.TargetConfig][]*readFailoverBucket{}
+	writeMirrors := map[*config.TargetConfig]*writeMirror{}
+	// Loop over targets to create S3 contexts
+	for _, tgt := range cfg.Targets {
+		s3ctx, err := newS3Context(tgt, metricsCtx)
+		if err != nil {
+			return nil, err
+		}
+		// Create object cache if enabled
+		if cacheCfg := tgt.GetObjectCache(); cacheCfg != nil {
+			s3ctx.objectCache, err = newObjectCache(tgt, cacheCfg)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// Create listing cache if enabled
+		if cacheCfg := tgt.GetListingCache(); cacheCfg != nil {
+			s3ctx.listingCache = newListingCache(cacheCfg)
+		}
+
+		targetClients[tgt] = s3ctx
+		// Create read failover buckets if enabled
+		if failoverCfg := tgt.GetReadFailover(); failoverCfg != nil {
+			buckets := []*readFailoverBucket{{s3ctx: s3ctx, health: newBucketHealth(failoverCfg)}}
+			// Loop over failover buckets
+			for _, bucket := range failoverCfg.Buckets {
+				// Failover buckets are used with the same target configuration
+				failoverTgt := *tgt
+				failoverTgt.Bucket = bucket
+
+				failoverS3ctx, err := newS3Context(&failoverTgt, metricsCtx)
+				if err != nil {
+					return nil, err
+				}
+
+				buckets = append(buckets, &readFailoverBucket{s3ctx: failoverS3ctx, health: newBucketHealth(failoverCfg)})
+			}
+
+			readFailovers[tgt] = buckets
+		}
+		// Create write mirror buckets if enabled
+		if mirrorCfg := tgt.GetWriteMirror(); mirrorCfg != nil {
+			mirrors := make([]*s3Context, 0, len(mirrorCfg.Buckets))
+			// Loop over mirror buckets
+			for _, bucket := range mirrorCfg.Buckets {
+				// Mirror buckets are used with the same target configuration
+				mirrorTgt := *tgt
+				mirrorTgt.Bucket = bucket
+
+				mirrorS3ctx, err := newS3Context(&mirrorTgt, metricsCtx)
+				if err != nil {
+					return nil, err
+				}
+
+				mirrors = append(mirrors, mirrorS3ctx)
+			}
+
+			spool, err := newMirrorSpool(mirrorCfg)
+			if err != nil {
+				return nil, err
+			}
+
+			wm := &writeMirror{mirrors: mirrors, spool: spool}
+			// Check if writes are done in background
+			if mirrorCfg.GetPolicy() == config.WriteMirrorPolicyAsync {
+				wm.queue = newMirrorQueue(mirrorCfg.GetQueueSize(), mirrorCfg.GetWorkers(), logger)
+			}
+
+			writeMirrors[tgt] = wm
+		}
+	}
+
+	return &manager{targetClients: targetClients, readFailovers: readFailovers, writeMirrors: writeMirrors}, nil
+}
+
+func (m *manager) Close() error {
+	// Loop over write mirrors to wait for asynchronous writes
+	for _, wm := range m.writeMirrors {
+		if wm.queue != nil {
+			wm.queue.close()
+		}
+	}
+
+	var err error
+	// Loop over targets to close object caches
+	for _, s3ctx := range m.targetClients {
+		if s3ctx.objectCache != nil {
+			err2 := s3ctx.objectCache.close()
+			if err2 != nil {
+				err = err2
+			}
+		}
+	}
+
+	return err
+}
+
+func (m *manager) GetClientForTarget(tgt *config.TargetConfig, logger log.Logger, parentTrace tracing.Trace) Client {
+	// Get target S3 context
+	s3ctx, ok := m.targetClients[tgt]
+	if !ok {
+		return nil
+	}
+
+	// Create a request S3 context sharing session clients
+	client := s3ctx.newRequestContext(logger, parentTrace)
+	// Writes are done on target bucket client unless write mirror is enabled
+	var writeClient Client = client
+	// Check if write mirror is enabled
+	if wm, ok := m.writeMirrors[tgt]; ok {
+		mirrorCfg := s3ctx.target.WriteMirror
+		mc := &mirrorClient{
+			Client:      client,
+			mirrors:     make([]*mirrorBucket, 0, len(wm.mirrors)),
+			policy:      mirrorCfg.GetPolicy(),
+			retries:     mirrorCfg.Retries,
+			retryDelay:  mirrorCfg.GetRetryDelay(),
+			targetName:  tgt.Name,
+			metricsCtx:  s3ctx.metricsCtx,
+			logger:      logger,
+			parentTrace: parentTrace,
+			queue:       wm.queue,
+			spool:       wm.spool,
+		}
+		// Loop over mirror buckets
+		for _, mirror := range wm.mirrors {
+			// Clients are created when writes are done because asynchronous writes aren't linked to request
+			mirror := mirror
+			mc.mirrors = append(mc.mirrors, &mirrorBucket{
+				newClient: func(logger log.Logger, parentTrace tracing.Trace) Client {
+					return mirror.newRequestContext(logger, parentTrace)
+				},
+				bucketName: mirror.target.Bucket.Name,
+			})
+		}
+
+		writeClient = mc
+	}
+	// Check if read failover is enabled
+	buckets, ok := m.readFailovers[tgt]
+	if !ok {
+		return writeClient
+	}
+
+	fc := &failoverClient{
+		Client:      writeClient,
+		buckets:     make([]*failoverBucket, 0, len(buckets)),
+		failoverCfg: s3ctx.target.ReadFailover,
+		targetName:  tgt.Name,
+		metricsCtx:  s3ctx.metricsCtx,
+		logger:      logger,
+	}
+	// Target bucket uses the same request S3 context for reads and writes
+	fc.buckets = append(fc.buckets, &failoverBucket{client: client, bucketName: s3ctx.target.Bucket.Name, health: buckets[0].health})
+	// Loop over failover buckets
+	for _, bucket := range buckets[1:] {
+		fc.buckets = append(fc.buckets, &failoverBucket{
+			client:     bucket.s3ctx.newRequestContext(logger, parentTrace),
+			bucketName: bucket.s3ctx.target.Bucket.Name,
+			health:     bucket.health,
+		})
+	}
+
+	return fc
+}
+
+// newRequestContext will create a request S3 context sharing session clients and caches
+func (s3ctx *s3Context) newRequestContext(logger log.Logger, parentTrace tracing.Trace) *s3Context {
+	return &s3Context{
+		svcClient:    s3ctx.svcClient,
+		uploader:     s3ctx.uploader,
+		credentials:  s3ctx.credentials,
+		target:       s3ctx.target,
+		metricsCtx:   s3ctx.metricsCtx,
+		logger:       logger,
+		parentTrace:  parentTrace,
+		objectCache:  s3ctx.objectCache,
+		listingCache: s3ctx.listingCache,
+	}
+}
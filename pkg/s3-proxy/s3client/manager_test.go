@@ -0,0 +1,106 @@
+// +build unit
+
+package s3client
+
+import (
+	"testing"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_manager_GetClientForTarget(t *testing.T) {
+	cfg := &config.Config{
+		Targets: []*config.TargetConfig{
+			{
+				Name:   "target1",
+				Bucket: &config.BucketConfig{Name: "bucket1", Region: "region1"},
+			},
+			{
+				Name:   "target2",
+				Bucket: &config.BucketConfig{Name: "bucket2", Region: "region2", S3Endpoint: "http://localhost:9000"},
+			},
+			{
+				Name:   "target3",
+				Bucket: &config.BucketConfig{Name: "bucket3", Region: "region3"},
+				ReadFailover: &config.ReadFailoverConfig{
+					Enabled: true,
+					Buckets: []*config.BucketConfig{{Name: "bucket4", Region: "region4"}},
+				},
+			},
+			{
+				Name:   "target4",
+				Bucket: &config.BucketConfig{Name: "bucket5", Region: "region5"},
+				WriteMirror: &config.WriteMirrorConfig{
+					Enabled: true,
+					Buckets: []*config.BucketConfig{{Name: "bucket6", Region: "region6"}},
+				},
+			},
+			{
+				Name:   "target1",
+				Bucket: &config.BucketConfig{Name: "bucket7", Region: "region1"},
+			},
+		},
+	}
+	logger := log.NewLogger()
+
+	m, err := NewManager(cfg, metrics.NewClient(), logger)
+	assert.NoError(t, err)
+
+	// Not managed target (even with a managed target name)
+	assert.Nil(t, m.GetClientForTarget(&config.TargetConfig{Name: "target1"}, logger, nil))
+
+	// Same target must reuse same S3 clients between requests
+	cli1, ok := m.GetClientForTarget(cfg.Targets[0], logger, nil).(*s3Context)
+	assert.True(t, ok)
+	cli2, ok := m.GetClientForTarget(cfg.Targets[0], logger, nil).(*s3Context)
+	assert.True(t, ok)
+	assert.False(t, cli1 == cli2)
+	assert.True(t, cli1.svcClient == cli2.svcClient)
+	assert.True(t, cli1.uploader == cli2.uploader)
+	assert.Equal(t, cfg.Targets[0], cli1.target)
+	assert.Equal(t, logger, cli1.logger)
+
+	// Another target must have its own S3 clients
+	cli3, ok := m.GetClientForTarget(cfg.Targets[1], logger, nil).(*s3Context)
+	assert.True(t, ok)
+	assert.False(t, cli1.svcClient == cli3.svcClient)
+	assert.Equal(t, cfg.Targets[1], cli3.target)
+
+	// Target with the same name must have its own S3 clients
+	cli7, ok := m.GetClientForTarget(cfg.Targets[4], logger, nil).(*s3Context)
+	assert.True(t, ok)
+	assert.False(t, cli1.svcClient == cli7.svcClient)
+	assert.Equal(t, "bucket7", cli7.target.Bucket.Name)
+
+	// Target with read failover must read on target bucket first and share health states between requests
+	fc1, ok := m.GetClientForTarget(cfg.Targets[2], logger, nil).(*failoverClient)
+	assert.True(t, ok)
+	fc2, ok := m.GetClientForTarget(cfg.Targets[2], logger, nil).(*failoverClient)
+	assert.True(t, ok)
+	assert.Len(t, fc1.buckets, 2)
+	assert.Equal(t, "bucket3", fc1.buckets[0].bucketName)
+	assert.Equal(t, "bucket4", fc1.buckets[1].bucketName)
+	assert.True(t, fc1.buckets[1].health == fc2.buckets[1].health)
+	assert.Equal(t, fc1.Client, fc1.buckets[0].client)
+	cli4, ok := fc1.buckets[1].client.(*s3Context)
+	assert.True(t, ok)
+	assert.Equal(t, "bucket4", cli4.target.Bucket.Name)
+	assert.Equal(t, "bucket3", cfg.Targets[2].Bucket.Name)
+
+	// Target with write mirror must write on target bucket and on mirror buckets with default policy
+	mc, ok := m.GetClientForTarget(cfg.Targets[3], logger, nil).(*mirrorClient)
+	assert.True(t, ok)
+	assert.Equal(t, config.WriteMirrorPolicyAll, mc.policy)
+	assert.Len(t, mc.mirrors, 1)
+	assert.Equal(t, "bucket6", mc.mirrors[0].bucketName)
+	assert.Nil(t, mc.queue)
+	cli6, ok := mc.mirrors[0].newClient(logger, nil).(*s3Context)
+	assert.True(t, ok)
+	assert.Equal(t, "bucket6", cli6.target.Bucket.Name)
+	cli5, ok := mc.Client.(*s3Context)
+	assert.True(t, ok)
+	assert.Equal(t, "bucket5", cli5.target.Bucket.Name)
+}
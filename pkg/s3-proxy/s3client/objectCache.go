@@ -0,0 +1,318 @@
+package s3client
+
+import (
+	"container/list"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+)
+
+// objectCacheEntry represents an object stored in local disk cache
+type objectCacheEntry struct {
+	key string
+	// path is the cache file path
+	path string
+	// metadata is the object output without body
+	metadata GetOutput
+	// validatedAt is the last time object was validated with S3
+	validatedAt time.Time
+}
+
+// objectCache is a size bounded LRU cache of objects on local disk
+// It is shared by all requests of a target.
+type objectCache struct {
+	directory     string
+	maxSize       int64
+	maxObjectSize int64
+	ttl           time.Duration
+	mutex         sync.Mutex
+	// lru contains entries from the most recently used to the least recently used
+	lru     *list.List
+	entries map[string]*list.Element
+	size    int64
+	// generation is increased on each invalidation to ignore objects downloaded before it
+	generation uint64
+}
+
+// cleanedDirectories contains target cache directories emptied since process start
+var cleanedDirectories = map[string]bool{}
+
+// cleanedDirectoriesMutex protects cleaned directories map
+var cleanedDirectoriesMutex sync.Mutex
+
+// newObjectCache will create a new object cache for a target
+// Each cache (one per configuration load) have its own folder in target cache directory
+// to keep files still used by requests of the previous configuration.
+func newObjectCache(tgt *config.TargetConfig, cacheCfg *config.ObjectCacheConfig) (*objectCache, error) {
+	// Each target have its own folder in cache directory
+	targetDirectory := filepath.Join(cacheCfg.Directory, url.PathEscape(tgt.Name))
+	// Empty target cache folder
+	err := cleanDirectoryOnce(targetDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(targetDirectory, 0700)
+	if err != nil {
+		return nil, err
+	}
+	// Create cache folder
+	directory, err := ioutil.TempDir(targetDirectory, "cache-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectCache{
+		directory:     directory,
+		maxSize:       cacheCfg.GetMaxSize(),
+		maxObjectSize: cacheCfg.GetMaxObjectSize(),
+		ttl:           cacheCfg.GetTTL(),
+		lru:           list.New(),
+		entries:       map[string]*list.Element{},
+	}, nil
+}
+
+// cleanDirectoryOnce will empty a directory only the first time it is used by the process
+// Files from previous runs cannot be trusted but files of the current run can still be used by requests.
+func cleanDirectoryOnce(directory string) error {
+	cleanedDirectoriesMutex.Lock()
+	defer cleanedDirectoriesMutex.Unlock()
+	// Check if directory have already been emptied
+	if cleanedDirectories[directory] {
+		return nil
+	}
+
+	err := os.RemoveAll(directory)
+	if err != nil {
+		return err
+	}
+
+	cleanedDirectories[directory] = true
+
+	return nil
+}
+
+// close will remove cache folder
+// Requests reading cached files can finish because files are only unlinked.
+func (c *objectCache) close() error {
+	return os.RemoveAll(c.directory)
+}
+
+// isObjectCacheable will check if a get request can be answered from cache
+// Only full current objects are cached.
+func isObjectCacheable(input *GetInput) bool {
+	return !input.HeadOnly && input.VersionID == "" && input.Range == "" && input.IfMatch == "" && input.IfUnmodifiedSince == nil
+}
+
+// get will get a copy of a cached entry (nil if not found) and mark it as recently used
+func (c *objectCache) get(key string) *objectCacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Check if entry exists
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	c.lru.MoveToFront(elem)
+	entry := *elem.Value.(*objectCacheEntry)
+
+	return &entry
+}
+
+// isFresh will check if entry doesn't need to be revalidated
+func (c *objectCache) isFresh(entry *objectCacheEntry) bool {
+	return time.Since(entry.validatedAt) < c.ttl
+}
+
+// markValidated will reset entry TTL if it hasn't been replaced
+func (c *objectCache) markValidated(entry *objectCacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Check if entry still exists
+	elem, ok := c.entries[entry.key]
+	if !ok || elem.Value.(*objectCacheEntry).path != entry.path {
+		return
+	}
+
+	elem.Value.(*objectCacheEntry).validatedAt = time.Now()
+}
+
+// getGeneration will get current invalidation generation
+func (c *objectCache) getGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// remove will invalidate a key
+func (c *objectCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	// Check if entry exists
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// removePrefix will invalidate all keys under a prefix
+func (c *objectCache) removePrefix(prefix string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	// Loop over entries
+	for key, elem := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(elem)
+		}
+	}
+}
+
+// removeElement will remove an entry and its file (mutex must be locked)
+// Requests reading the file can finish because file is only unlinked.
+func (c *objectCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*objectCacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+	c.size -= entry.metadata.ContentLength
+	// Ignore error because nothing can be done
+	_ = os.Remove(entry.path)
+}
+
+// add will add a downloaded file in cache and will evict least recently used entries if needed
+// File is removed if an invalidation occurred since the download began.
+func (c *objectCache) add(entry *objectCacheEntry, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Check if key have been invalidated during download
+	if generation != c.generation {
+		_ = os.Remove(entry.path)
+
+		return
+	}
+	// Replace previous entry
+	if elem, ok := c.entries[entry.key]; ok {
+		c.removeElement(elem)
+	}
+
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	c.size += entry.metadata.ContentLength
+	// Evict least recently used entries
+	for c.size > c.maxSize {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// open will answer with cached entry while respecting request conditions
+func (c *objectCache) open(entry *objectCacheEntry, input *GetInput) (*GetOutput, error) {
+	// Check conditions
+	if isNotModified(input, &entry.metadata) {
+		return nil, ErrNotModified
+	}
+	// Open cache file
+	file, err := os.Open(entry.path)
+	if err != nil {
+		return nil, err
+	}
+	// Build output
+	output := entry.metadata
+	body := io.ReadCloser(file)
+	output.Body = &body
+
+	return &output, nil
+}
+
+// isNotModified will check If-None-Match and If-Modified-Since conditions on an object
+func isNotModified(input *GetInput, obj *GetOutput) bool {
+	// If-None-Match has priority over If-Modified-Since
+	if input.IfNoneMatch != "" {
+		for _, etag := range strings.Split(input.IfNoneMatch, ",") {
+			etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+			if etag == "*" || etag == obj.ETag {
+				return true
+			}
+		}
+
+		return false
+	}
+	// Dates have a second precision in headers
+	if input.IfModifiedSince != nil {
+		return !obj.LastModified.Truncate(time.Second).After(*input.IfModifiedSince)
+	}
+
+	return false
+}
+
+// newFillReader will wrap object body to store it in cache while it is read
+func (c *objectCache) newFillReader(key string, obj *GetOutput, generation uint64) (io.ReadCloser, error) {
+	file, err := ioutil.TempFile(c.directory, "object-")
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &objectCacheEntry{
+		key:         key,
+		path:        file.Name(),
+		metadata:    *obj,
+		validatedAt: time.Now(),
+	}
+	entry.metadata.Body = nil
+
+	return &objectCacheFillReader{
+		body:       *obj.Body,
+		file:       file,
+		cache:      c,
+		entry:      entry,
+		generation: generation,
+	}, nil
+}
+
+// objectCacheFillReader will copy object body in a cache file while it is read
+// Cache entry is added on close only if the whole object have been read.
+type objectCacheFillReader struct {
+	body       io.ReadCloser
+	file       *os.File
+	cache      *objectCache
+	entry      *objectCacheEntry
+	generation uint64
+	written    int64
+	failed     bool
+}
+
+func (r *objectCacheFillReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	// Copy data in cache file
+	if n > 0 && !r.failed {
+		_, werr := r.file.Write(p[:n])
+		r.failed = werr != nil
+		r.written += int64(n)
+	}
+
+	return n, err
+}
+
+func (r *objectCacheFillReader) Close() error {
+	err := r.body.Close()
+	ferr := r.file.Close()
+	// Check if cache file is complete
+	if r.failed || ferr != nil || r.written != r.entry.metadata.ContentLength {
+		_ = os.Remove(r.file.Name())
+
+		return err
+	}
+
+	r.cache.add(r.entry, r.generation)
+
+	return err
+}
@@ -0,0 +1,199 @@
+// +build unit
+
+package s3client
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isNotModified(t *testing.T) {
+	lastModified := time.Date(2020, time.January, 1, 10, 0, 0, 500, time.UTC)
+	before := lastModified.Add(-time.Hour)
+	same := lastModified.Truncate(time.Second)
+	obj := &GetOutput{ETag: `"etag1"`, LastModified: lastModified}
+	tests := []struct {
+		name  string
+		input *GetInput
+		want  bool
+	}{
+		{
+			name:  "No condition",
+			input: &GetInput{},
+			want:  false,
+		},
+		{
+			name:  "Matching etag",
+			input: &GetInput{IfNoneMatch: `"etag2", "etag1"`},
+			want:  true,
+		},
+		{
+			name:  "Matching weak etag",
+			input: &GetInput{IfNoneMatch: `W/"etag1"`},
+			want:  true,
+		},
+		{
+			name:  "Wildcard etag",
+			input: &GetInput{IfNoneMatch: "*"},
+			want:  true,
+		},
+		{
+			name:  "Not matching etag has priority over date",
+			input: &GetInput{IfNoneMatch: `"etag2"`, IfModifiedSince: &same},
+			want:  false,
+		},
+		{
+			name:  "Not modified since date",
+			input: &GetInput{IfModifiedSince: &same},
+			want:  true,
+		},
+		{
+			name:  "Modified since date",
+			input: &GetInput{IfModifiedSince: &before},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNotModified(tt.input, obj); got != tt.want {
+				t.Errorf("isNotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isObjectCacheable(t *testing.T) {
+	fakeDate := time.Now()
+	tests := []struct {
+		name  string
+		input *GetInput
+		want  bool
+	}{
+		{name: "Simple key", input: &GetInput{Key: "key"}, want: true},
+		{name: "Cache conditions", input: &GetInput{Key: "key", IfNoneMatch: "etag", IfModifiedSince: &fakeDate}, want: true},
+		{name: "Version", input: &GetInput{Key: "key", VersionID: "version1"}, want: false},
+		{name: "Range", input: &GetInput{Key: "key", Range: "bytes=0-1"}, want: false},
+		{name: "If-Match", input: &GetInput{Key: "key", IfMatch: "etag"}, want: false},
+		{name: "If-Unmodified-Since", input: &GetInput{Key: "key", IfUnmodifiedSince: &fakeDate}, want: false},
+		{name: "Head only", input: &GetInput{Key: "key", HeadOnly: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isObjectCacheable(tt.input); got != tt.want {
+				t.Errorf("isObjectCacheable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_objectCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3-proxy-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	// Add a file from a previous run
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "target%2F1"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "target%2F1", "old"), []byte("old"), 0600))
+
+	cache, err := newObjectCache(
+		&config.TargetConfig{Name: "target/1"},
+		&config.ObjectCacheConfig{Enabled: true, Directory: dir, MaxSize: 10, MaxObjectSize: 5, TTL: time.Hour},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "target%2F1"), filepath.Dir(cache.directory))
+	files, err := ioutil.ReadDir(filepath.Join(dir, "target%2F1"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	files, err = ioutil.ReadDir(cache.directory)
+	assert.NoError(t, err)
+	assert.Len(t, files, 0)
+
+	// fill will read object with a fill reader
+	fill := func(key, content string, generation uint64, readAll bool) {
+		body := ioutil.NopCloser(strings.NewReader(content))
+		r, err := cache.newFillReader(key, &GetOutput{Body: &body, ContentLength: int64(len(content)), ETag: key}, generation)
+		assert.NoError(t, err)
+		if readAll {
+			_, err = io.Copy(ioutil.Discard, r)
+		} else {
+			_, err = r.Read(make([]byte, 1))
+		}
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+	}
+	// read will read cached object content
+	read := func(key string) string {
+		entry := cache.get(key)
+		if entry == nil {
+			return ""
+		}
+		output, err := cache.open(entry, &GetInput{Key: key})
+		assert.NoError(t, err)
+		b, err := ioutil.ReadAll(*output.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, (*output.Body).Close())
+		assert.Equal(t, key, output.ETag)
+		return string(b)
+	}
+
+	// Partially read objects aren't cached
+	fill("key1", "11111", cache.getGeneration(), false)
+	assert.Nil(t, cache.get("key1"))
+	// Fully read objects are cached
+	fill("key1", "11111", cache.getGeneration(), true)
+	assert.Equal(t, "11111", read("key1"))
+	entry := cache.get("key1")
+	assert.True(t, cache.isFresh(entry))
+	// Cached conditions
+	_, err = cache.open(entry, &GetInput{Key: "key1", IfNoneMatch: "key1"})
+	assert.Equal(t, ErrNotModified, err)
+
+	// Least recently used entries are evicted
+	fill("dir/key2", "222", cache.getGeneration(), true)
+	assert.Equal(t, "11111", read("key1"))
+	fill("dir/key3", "333", cache.getGeneration(), true)
+	assert.Equal(t, int64(8), cache.size)
+	assert.Equal(t, "", read("dir/key2"))
+	assert.Equal(t, "333", read("dir/key3"))
+	files, err = ioutil.ReadDir(cache.directory)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// Objects invalidated during download aren't cached
+	generation := cache.getGeneration()
+	cache.remove("key1")
+	fill("key1", "12345", generation, true)
+	assert.Nil(t, cache.get("key1"))
+
+	// Prefix invalidation
+	fill("key1", "12345", cache.getGeneration(), true)
+	cache.removePrefix("dir/")
+	assert.Equal(t, "", read("dir/key3"))
+	assert.Equal(t, "12345", read("key1"))
+	assert.Equal(t, int64(5), cache.size)
+	files, err = ioutil.ReadDir(cache.directory)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// Cache created on configuration reload doesn't remove files of previous cache
+	cache2, err := newObjectCache(
+		&config.TargetConfig{Name: "target/1"},
+		&config.ObjectCacheConfig{Enabled: true, Directory: dir, MaxSize: 10, MaxObjectSize: 5, TTL: time.Hour},
+	)
+	assert.NoError(t, err)
+	assert.NotEqual(t, cache.directory, cache2.directory)
+	assert.Equal(t, "12345", read("key1"))
+	// Closed cache folder is removed
+	assert.NoError(t, cache.close())
+	_, err = os.Stat(cache.directory)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(cache2.directory)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,167 @@
+package s3client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+)
+
+// postPolicyAlgorithm Algorithm used to sign POST policies
+const postPolicyAlgorithm = "AWS4-HMAC-SHA256"
+
+// postPolicy POST policy document
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-HTTPPOSTConstructPolicy.html
+type postPolicy struct {
+	Expiration string        `json:"expiration"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+// postTagging Tagging document sent in POST form
+type postTagging struct {
+	XMLName xml.Name          `xml:"Tagging"`
+	TagSet  []*postTaggingTag `xml:"TagSet>Tag"`
+}
+
+// postTaggingTag Tag in tagging document
+type postTaggingTag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// generatePostFields will generate signed form fields for a presigned POST upload
+// nolint:whitespace
+func generatePostFields(
+	creds credentials.Value, region, bucket string, encryptionCfg *config.BucketEncryptionConfig,
+	input *PresignPutInput, now time.Time,
+) (map[string]string, error) {
+	date := now.UTC()
+	shortDate := date.Format("20060102")
+	// Create fields that must be sent in form
+	fields := map[string]string{
+		"key":              input.Key,
+		"x-amz-algorithm":  postPolicyAlgorithm,
+		"x-amz-credential": fmt.Sprintf("%s/%s/%s/s3/aws4_request", creds.AccessKeyID, shortDate, region),
+		"x-amz-date":       date.Format("20060102T150405Z"),
+	}
+	// Add session token if exists
+	if creds.SessionToken != "" {
+		fields["x-amz-security-token"] = creds.SessionToken
+	}
+	// Add metadata
+	for k, v := range input.Metadata {
+		fields["x-amz-meta-"+k] = v
+	}
+	// Add storage class
+	if input.StorageClass != "" {
+		fields["x-amz-storage-class"] = input.StorageClass
+	}
+	// Add tags
+	if len(input.Tags) != 0 {
+		tagging, err := encodePostTagging(input.Tags)
+		if err != nil {
+			return nil, err
+		}
+
+		fields["tagging"] = tagging
+	}
+
+	// Add server side encryption
+	if encryptionCfg != nil && encryptionCfg.ServerSideEncryption != "" {
+		fields["x-amz-server-side-encryption"] = encryptionCfg.ServerSideEncryption
+		// Add KMS key if exists
+		if encryptionCfg.KMSKeyID != "" {
+			fields["x-amz-server-side-encryption-aws-kms-key-id"] = encryptionCfg.KMSKeyID
+		}
+	}
+
+	// Sort field names to generate the same policy for the same input
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	// Create policy conditions
+	conditions := []interface{}{map[string]string{"bucket": bucket}}
+	for _, k := range names {
+		conditions = append(conditions, map[string]string{k: fields[k]})
+	}
+	// Check if content type is declared
+	if input.ContentType != "" {
+		conditions = append(conditions, []interface{}{"eq", "$Content-Type", input.ContentType})
+	} else {
+		// Allow client to set content type
+		conditions = append(conditions, []interface{}{"starts-with", "$Content-Type", ""})
+	}
+	// Add size limit
+	if input.Size >= 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", input.Size, input.Size})
+	} else if input.MaxSize > 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", 0, input.MaxSize})
+	}
+
+	content, err := json.Marshal(&postPolicy{
+		Expiration: date.Add(input.Expiry).Format("2006-01-02T15:04:05.000Z"),
+		Conditions: conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	policy := base64.StdEncoding.EncodeToString(content)
+	fields["policy"] = policy
+	// Add declared content type that must be sent in form
+	if input.ContentType != "" {
+		fields["Content-Type"] = input.ContentType
+	}
+
+	// Generate signing key
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), shortDate)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	// Sign policy
+	fields["x-amz-signature"] = hex.EncodeToString(hmacSHA256(signingKey, policy))
+
+	return fields, nil
+}
+
+// encodePostTagging will encode tags in a XML tagging document like S3 is expecting them in POST forms
+func encodePostTagging(tags map[string]string) (string, error) {
+	// Sort keys to generate the same document for the same tags
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	tagging := &postTagging{TagSet: make([]*postTaggingTag, 0, len(keys))}
+	for _, k := range keys {
+		tagging.TagSet = append(tagging.TagSet, &postTaggingTag{Key: k, Value: tags[k]})
+	}
+
+	content, err := xml.Marshal(tagging)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
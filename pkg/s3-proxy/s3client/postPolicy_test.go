@@ -0,0 +1,99 @@
+// +build unit
+
+package s3client
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_generatePostFields(t *testing.T) {
+	now := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	creds := credentials.Value{AccessKeyID: "AKID", SecretAccessKey: "SECRET", SessionToken: "TOKEN"}
+	input := &PresignPutInput{
+		Key:          "dir/file.pdf",
+		Expiry:       time.Hour,
+		Metadata:     map[string]string{"meta1": "value1"},
+		StorageClass: "STANDARD",
+		Size:         -1,
+		MaxSize:      100,
+	}
+
+	fields, err := generatePostFields(creds, "eu-west-1", "bucket", nil, input, now)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "dir/file.pdf", fields["key"])
+	assert.Equal(t, "AWS4-HMAC-SHA256", fields["x-amz-algorithm"])
+	assert.Equal(t, "AKID/20200601/eu-west-1/s3/aws4_request", fields["x-amz-credential"])
+	assert.Equal(t, "20200601T100000Z", fields["x-amz-date"])
+	assert.Equal(t, "TOKEN", fields["x-amz-security-token"])
+	assert.Equal(t, "value1", fields["x-amz-meta-meta1"])
+	assert.Equal(t, "STANDARD", fields["x-amz-storage-class"])
+	assert.Len(t, fields["x-amz-signature"], 64)
+
+	policy, err := base64.StdEncoding.DecodeString(fields["policy"])
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"expiration":"2020-06-01T11:00:00.000Z","conditions":[{"bucket":"bucket"},{"key":"dir/file.pdf"},`+
+			`{"x-amz-algorithm":"AWS4-HMAC-SHA256"},{"x-amz-credential":"AKID/20200601/eu-west-1/s3/aws4_request"},`+
+			`{"x-amz-date":"20200601T100000Z"},{"x-amz-meta-meta1":"value1"},{"x-amz-security-token":"TOKEN"},`+
+			`{"x-amz-storage-class":"STANDARD"},["starts-with","$Content-Type",""],["content-length-range",0,100]]}`,
+		string(policy),
+	)
+
+	// Same input must give same signature
+	fields2, err := generatePostFields(creds, "eu-west-1", "bucket", nil, input, now)
+	assert.NoError(t, err)
+	assert.Equal(t, fields["x-amz-signature"], fields2["x-amz-signature"])
+}
+
+func Test_generatePostFields_declared(t *testing.T) {
+	now := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	creds := credentials.Value{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+	input := &PresignPutInput{Key: "file.png", Expiry: time.Hour, ContentType: "image/png", Size: 10, MaxSize: 100}
+
+	fields, err := generatePostFields(creds, "eu-west-1", "bucket", nil, input, now)
+	assert.NoError(t, err)
+
+	// Declared content type must be sent in form
+	assert.Equal(t, "image/png", fields["Content-Type"])
+
+	policy, err := base64.StdEncoding.DecodeString(fields["policy"])
+	assert.NoError(t, err)
+	assert.Contains(t, string(policy), `["eq","$Content-Type","image/png"],["content-length-range",10,10]]`)
+	assert.NotContains(t, string(policy), "starts-with")
+}
+
+func Test_generatePostFields_encryption(t *testing.T) {
+	now := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	creds := credentials.Value{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+	input := &PresignPutInput{Key: "file.pdf", Expiry: time.Hour, Size: -1}
+	encryptionCfg := &config.BucketEncryptionConfig{ServerSideEncryption: "aws:kms", KMSKeyID: "key-id"}
+
+	fields, err := generatePostFields(creds, "eu-west-1", "bucket", encryptionCfg, input, now)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "aws:kms", fields["x-amz-server-side-encryption"])
+	assert.Equal(t, "key-id", fields["x-amz-server-side-encryption-aws-kms-key-id"])
+
+	policy, err := base64.StdEncoding.DecodeString(fields["policy"])
+	assert.NoError(t, err)
+	assert.Contains(t, string(policy), `{"x-amz-server-side-encryption":"aws:kms"},{"x-amz-server-side-encryption-aws-kms-key-id":"key-id"}`)
+}
+
+func Test_generatePostFields_tags(t *testing.T) {
+	now := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	creds := credentials.Value{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+	input := &PresignPutInput{Key: "file.pdf", Expiry: time.Hour, Size: -1, Tags: map[string]string{"user": "john", "env": "<prod>"}}
+
+	fields, err := generatePostFields(creds, "eu-west-1", "bucket", nil, input, now)
+	assert.NoError(t, err)
+
+	expected := "<Tagging><TagSet><Tag><Key>env</Key><Value>&lt;prod&gt;</Value></Tag><Tag><Key>user</Key><Value>john</Value></Tag></TagSet></Tagging>"
+	assert.Equal(t, expected, fields["tagging"])
+}
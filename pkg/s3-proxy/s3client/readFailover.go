@@ -0,0 +1,264 @@
+package s3client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
+)
+
+// Bucket health states
+const (
+	// bucketHealthy Bucket is used for reads
+	bucketHealthy = "healthy"
+	// bucketEjected Bucket is only used when all other buckets failed
+	bucketEjected = "ejected"
+	// bucketProbing Bucket is used by one request to check if it is healthy again
+	bucketProbing = "probing"
+)
+
+// bucketHealth is the health state machine of a bucket used for reads
+// A healthy bucket is ejected after consecutive failures. After ejection duration,
+// one request is allowed to probe it: it becomes healthy on success and is ejected again on failure.
+// It is shared by all requests of a target.
+type bucketHealth struct {
+	failureThreshold int
+	ejectionDuration time.Duration
+	mutex            sync.Mutex
+	state            string
+	failures         int
+	// until is the end of ejection or the end of probe
+	until time.Time
+}
+
+// newBucketHealth will create a new healthy bucket state
+func newBucketHealth(failoverCfg *config.ReadFailoverConfig) *bucketHealth {
+	return &bucketHealth{
+		failureThreshold: failoverCfg.GetFailureThreshold(),
+		ejectionDuration: failoverCfg.GetEjectionDuration(),
+		state:            bucketHealthy,
+	}
+}
+
+// isAvailable will check if bucket can be used without changing its state
+func (h *bucketHealth) isAvailable() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.state == bucketHealthy || !time.Now().Before(h.until)
+}
+
+// acquire will check if bucket can be called now
+// An ejected bucket goes into probing state when ejection is over. It must be called just before bucket is called.
+func (h *bucketHealth) acquire() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	// Check if bucket is healthy
+	if h.state == bucketHealthy {
+		return true
+	}
+	// Check if ejection or probe is over
+	now := time.Now()
+	if now.Before(h.until) {
+		return false
+	}
+	// Allow one request to probe bucket
+	h.state = bucketProbing
+	h.until = now.Add(h.ejectionDuration)
+
+	return true
+}
+
+// recordSuccess will mark bucket as healthy
+func (h *bucketHealth) recordSuccess() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.state = bucketHealthy
+	h.failures = 0
+}
+
+// recordFailure will eject bucket when failure threshold is reached or when probe failed
+func (h *bucketHealth) recordFailure() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.failures++
+	// Check if bucket must be ejected
+	if h.state == bucketProbing || h.failures >= h.failureThreshold {
+		h.state = bucketEjected
+		h.until = time.Now().Add(h.ejectionDuration)
+	}
+}
+
+// getState will get current state
+func (h *bucketHealth) getState() string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.state
+}
+
+// failoverBucket is a bucket client with its health state
+type failoverBucket struct {
+	client     Client
+	bucketName string
+	health     *bucketHealth
+}
+
+// failoverClient will read objects and listings on the first available bucket and will fail over
+// on the next ones on errors. Other operations are done on target bucket client.
+type failoverClient struct {
+	// Client is the target bucket client
+	Client
+	buckets     []*failoverBucket
+	failoverCfg *config.ReadFailoverConfig
+	targetName  string
+	metricsCtx  metrics.Client
+	logger      log.Logger
+}
+
+// isBucketFailure will check if error is a bucket failure (server error, timeout or connection error)
+func isBucketFailure(err error) bool {
+	// Check server errors
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		return reqErr.StatusCode() >= 500
+	}
+	// Check errors without any answer
+	aerr, ok := err.(awserr.Error)
+
+	return ok && (aerr.Code() == request.ErrCodeRequestError || aerr.Code() == request.ErrCodeResponseTimeout)
+}
+
+// getBucketsInOrder will get available buckets in configuration order followed by ejected ones
+// Ejected buckets are used only when all available buckets failed. Health states aren't changed.
+func (fc *failoverClient) getBucketsInOrder() []*failoverBucket {
+	available := make([]*failoverBucket, 0, len(fc.buckets))
+	ejected := make([]*failoverBucket, 0)
+	// Loop over buckets
+	for _, bucket := range fc.buckets {
+		if bucket.health.isAvailable() {
+			available = append(available, bucket)
+		} else {
+			ejected = append(ejected, bucket)
+		}
+	}
+
+	return append(available, ejected...)
+}
+
+// read will call read function on buckets until one of them answers
+// Not found answers will fail over on next bucket if enabled.
+func (fc *failoverClient) read(operation string, notFoundFailover bool, fn func(cli Client) error) error {
+	buckets := fc.getBucketsInOrder()
+	// deferred contains buckets that cannot be called now and that are moved after other ones
+	deferred := map[*failoverBucket]bool{}
+	// notFoundBucket is the first bucket answering with a not found error when not found answers fail over
+	var notFoundBucket *failoverBucket
+
+	var err error
+	// Loop over buckets
+	for i := 0; i < len(buckets); i++ {
+		bucket := buckets[i]
+		// Check if bucket can be called now (ejected bucket or bucket probed by another request)
+		// This will move bucket into probing state only when it is called.
+		if !deferred[bucket] && !bucket.health.acquire() {
+			deferred[bucket] = true
+			buckets = append(buckets, bucket)
+
+			continue
+		}
+
+		err = fn(bucket.client)
+		// Check if bucket failed
+		if isBucketFailure(err) {
+			bucket.health.recordFailure()
+			fc.logger.Warnf("read failover: %s on bucket %s failed (state: %s): %v", operation, bucket.bucketName, bucket.health.getState(), err)
+
+			continue
+		}
+
+		bucket.health.recordSuccess()
+		// Check if not found answer must fail over on next bucket
+		if err == ErrNotFound && notFoundFailover && i < len(buckets)-1 {
+			if notFoundBucket == nil {
+				notFoundBucket = bucket
+			}
+
+			continue
+		}
+		// Metrics
+		fc.metricsCtx.IncReadFailoverServed(fc.targetName, bucket.bucketName, operation)
+
+		return err
+	}
+	// Object doesn't exist when other buckets failed after a not found answer
+	if notFoundBucket != nil {
+		fc.metricsCtx.IncReadFailoverServed(fc.targetName, notFoundBucket.bucketName, operation)
+
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (fc *failoverClient) ListFilesAndDirectories(input *ListInput) (*ListOutput, error) {
+	var output *ListOutput
+
+	err := fc.read(ListObjectsOperation, false, func(cli Client) error {
+		var err error
+		output, err = cli.ListFilesAndDirectories(input)
+
+		return err
+	})
+
+	return output, err
+}
+
+func (fc *failoverClient) ListAllObjects(input *ListAllObjectsInput) (*ListAllObjectsOutput, error) {
+	var output *ListAllObjectsOutput
+
+	err := fc.read(ListObjectsOperation, false, func(cli Client) error {
+		var err error
+		output, err = cli.ListAllObjects(input)
+
+		return err
+	})
+
+	return output, err
+}
+
+func (fc *failoverClient) HeadObject(key string) (*HeadOutput, error) {
+	var output *HeadOutput
+
+	err := fc.read(HeadObjectOperation, fc.failoverCfg.OnNotFound, func(cli Client) error {
+		var err error
+		output, err = cli.HeadObject(key)
+
+		return err
+	})
+
+	return output, err
+}
+
+func (fc *failoverClient) GetObject(input *GetInput) (*GetOutput, error) {
+	// Versions are specific to each bucket so they are only read in target bucket
+	if input.VersionID != "" {
+		return fc.Client.GetObject(input)
+	}
+
+	var output *GetOutput
+
+	err := fc.read(GetObjectOperation, fc.failoverCfg.OnNotFound, func(cli Client) error {
+		var err error
+		output, err = cli.GetObject(input)
+
+		return err
+	})
+
+	return output, err
+}
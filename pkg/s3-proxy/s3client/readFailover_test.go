@@ -0,0 +1,273 @@
+// +build unit
+
+package s3client
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// readFailoverClientTest is a fake bucket client answering reads with an error
+type readFailoverClientTest struct {
+	Client
+	err   error
+	calls int
+}
+
+func (c *readFailoverClientTest) GetObject(input *GetInput) (*GetOutput, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &GetOutput{ETag: input.Key}, nil
+}
+
+// metricsClientTest is a fake metrics client keeping served and failed buckets
+type metricsClientTest struct {
+	mutex      sync.Mutex
+	served     []string
+	failures   []string
+	operations []string
+}
+
+func (m *metricsClientTest) Instrument(serverLabel string) func(next http.Handler) http.Handler {
+	return nil
+}
+func (m *metricsClientTest) GetExposeHandler() http.Handler { return nil }
+func (m *metricsClientTest) IncS3Operations(targetName, bucketName, operation string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.operations = append(m.operations, operation)
+}
+func (m *metricsClientTest) IncAuthenticated(providerType, providerName string)  {}
+func (m *metricsClientTest) IncAuthorized(providerType string)                   {}
+func (m *metricsClientTest) IncObjectCacheHits(targetName, bucketName string)    {}
+func (m *metricsClientTest) IncObjectCacheMisses(targetName, bucketName string)  {}
+func (m *metricsClientTest) IncListingCacheHits(targetName, bucketName string)   {}
+func (m *metricsClientTest) IncListingCacheMisses(targetName, bucketName string) {}
+func (m *metricsClientTest) IncWriteMirrorFailures(targetName, bucketName, operation string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.failures = append(m.failures, bucketName)
+}
+
+func (m *metricsClientTest) getFailures() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.failures
+}
+func (m *metricsClientTest) IncReadFailoverServed(targetName, bucketName, operation string) {
+	m.served = append(m.served, bucketName)
+}
+
+func Test_isBucketFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "No error", err: nil, want: false},
+		{name: "Not found", err: ErrNotFound, want: false},
+		{name: "Other error", err: errors.New("fake"), want: false},
+		{name: "Server error", err: awserr.NewRequestFailure(awserr.New("InternalError", "fake", nil), 503, "id"), want: true},
+		{name: "Client error", err: awserr.NewRequestFailure(awserr.New("AccessDenied", "fake", nil), 403, "id"), want: false},
+		{name: "Connection error", err: awserr.New(request.ErrCodeRequestError, "fake", nil), want: true},
+		{name: "Timeout", err: awserr.New(request.ErrCodeResponseTimeout, "fake", nil), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBucketFailure(tt.err); got != tt.want {
+				t.Errorf("isBucketFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_bucketHealth(t *testing.T) {
+	h := newBucketHealth(&config.ReadFailoverConfig{FailureThreshold: 2, EjectionDuration: time.Hour})
+	assert.Equal(t, bucketHealthy, h.getState())
+
+	// Bucket is ejected after consecutive failures
+	h.recordFailure()
+	assert.True(t, h.isAvailable())
+	h.recordSuccess()
+	h.recordFailure()
+	assert.Equal(t, bucketHealthy, h.getState())
+	h.recordFailure()
+	assert.Equal(t, bucketEjected, h.getState())
+	assert.False(t, h.isAvailable())
+
+	assert.False(t, h.acquire())
+
+	// Only one request probes bucket after ejection
+	h.until = time.Now()
+	assert.True(t, h.isAvailable())
+	assert.Equal(t, bucketEjected, h.getState())
+	assert.True(t, h.acquire())
+	assert.Equal(t, bucketProbing, h.getState())
+	assert.False(t, h.isAvailable())
+	assert.False(t, h.acquire())
+
+	// Failed probe ejects bucket again
+	h.recordFailure()
+	assert.Equal(t, bucketEjected, h.getState())
+	assert.False(t, h.isAvailable())
+
+	// Successful probe marks bucket as healthy
+	h.until = time.Now()
+	assert.True(t, h.acquire())
+	h.recordSuccess()
+	assert.Equal(t, bucketHealthy, h.getState())
+	assert.True(t, h.isAvailable())
+}
+
+func Test_failoverClient_GetObject(t *testing.T) {
+	serverErr := awserr.NewRequestFailure(awserr.New("InternalError", "fake", nil), 500, "id")
+	tests := []struct {
+		name               string
+		onNotFound         bool
+		primaryErr         error
+		secondaryErr       error
+		wantErr            error
+		wantServed         []string
+		wantPrimaryCalls   int
+		wantSecondaryCalls int
+		wantPrimaryState   string
+	}{
+		{
+			name:             "should read on primary bucket",
+			wantServed:       []string{"primary", "primary"},
+			wantPrimaryCalls: 2,
+			wantPrimaryState: bucketHealthy,
+		},
+		{
+			name:               "should fail over on server error and eject primary bucket",
+			primaryErr:         serverErr,
+			wantServed:         []string{"secondary", "secondary"},
+			wantPrimaryCalls:   1,
+			wantSecondaryCalls: 2,
+			wantPrimaryState:   bucketEjected,
+		},
+		{
+			name:             "should not fail over on not found by default",
+			primaryErr:       ErrNotFound,
+			wantErr:          ErrNotFound,
+			wantServed:       []string{"primary", "primary"},
+			wantPrimaryCalls: 2,
+			wantPrimaryState: bucketHealthy,
+		},
+		{
+			name:               "should fail over on not found when enabled",
+			onNotFound:         true,
+			primaryErr:         ErrNotFound,
+			wantServed:         []string{"secondary", "secondary"},
+			wantPrimaryCalls:   2,
+			wantSecondaryCalls: 2,
+			wantPrimaryState:   bucketHealthy,
+		},
+		{
+			name:               "should answer with not found when next buckets failed after a not found",
+			onNotFound:         true,
+			primaryErr:         ErrNotFound,
+			secondaryErr:       serverErr,
+			wantErr:            ErrNotFound,
+			wantServed:         []string{"primary", "primary"},
+			wantPrimaryCalls:   2,
+			wantSecondaryCalls: 2,
+			wantPrimaryState:   bucketHealthy,
+		},
+		{
+			name:               "should answer with last error when all buckets failed",
+			primaryErr:         serverErr,
+			secondaryErr:       serverErr,
+			wantErr:            serverErr,
+			wantPrimaryCalls:   2,
+			wantSecondaryCalls: 2,
+			wantPrimaryState:   bucketEjected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failoverCfg := &config.ReadFailoverConfig{Enabled: true, OnNotFound: tt.onNotFound, FailureThreshold: 1, EjectionDuration: time.Hour}
+			primary := &readFailoverClientTest{err: tt.primaryErr}
+			secondary := &readFailoverClientTest{err: tt.secondaryErr}
+			metricsCtx := &metricsClientTest{}
+			fc := &failoverClient{
+				Client: primary,
+				buckets: []*failoverBucket{
+					{client: primary, bucketName: "primary", health: newBucketHealth(failoverCfg)},
+					{client: secondary, bucketName: "secondary", health: newBucketHealth(failoverCfg)},
+				},
+				failoverCfg: failoverCfg,
+				targetName:  "target",
+				metricsCtx:  metricsCtx,
+				logger:      log.NewLogger(),
+			}
+			// Do 2 requests to check health states
+			for i := 0; i < 2; i++ {
+				got, err := fc.GetObject(&GetInput{Key: "key"})
+				assert.Equal(t, tt.wantErr, err)
+				if tt.wantErr == nil {
+					assert.Equal(t, "key", got.ETag)
+				}
+			}
+			assert.Equal(t, tt.wantServed, metricsCtx.served)
+			assert.Equal(t, tt.wantPrimaryCalls, primary.calls)
+			assert.Equal(t, tt.wantSecondaryCalls, secondary.calls)
+			assert.Equal(t, tt.wantPrimaryState, fc.buckets[0].health.getState())
+		})
+	}
+}
+
+func Test_failoverClient_GetObject_Health(t *testing.T) {
+	failoverCfg := &config.ReadFailoverConfig{Enabled: true, FailureThreshold: 1, EjectionDuration: time.Hour}
+	primary := &readFailoverClientTest{}
+	secondary := &readFailoverClientTest{}
+	fc := &failoverClient{
+		Client: primary,
+		buckets: []*failoverBucket{
+			{client: primary, bucketName: "primary", health: newBucketHealth(failoverCfg)},
+			{client: secondary, bucketName: "secondary", health: newBucketHealth(failoverCfg)},
+		},
+		failoverCfg: failoverCfg,
+		targetName:  "target",
+		metricsCtx:  &metricsClientTest{},
+		logger:      log.NewLogger(),
+	}
+	// Eject secondary bucket with an ejection over
+	fc.buckets[1].health.recordFailure()
+	fc.buckets[1].health.until = time.Now()
+
+	// Secondary bucket isn't probed when it isn't called
+	_, err := fc.GetObject(&GetInput{Key: "key"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 0, secondary.calls)
+	assert.Equal(t, bucketEjected, fc.buckets[1].health.getState())
+
+	// Secondary bucket is probed when it is called
+	primary.err = awserr.NewRequestFailure(awserr.New("InternalError", "fake", nil), 500, "id")
+	_, err = fc.GetObject(&GetInput{Key: "key"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, secondary.calls)
+	assert.Equal(t, bucketHealthy, fc.buckets[1].health.getState())
+	assert.Equal(t, bucketEjected, fc.buckets[0].health.getState())
+
+	// Versions are only read in target bucket even if it is ejected
+	_, err = fc.GetObject(&GetInput{Key: "key", VersionID: "version1"})
+	assert.Equal(t, primary.err, err)
+	assert.Equal(t, 3, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+}
@@ -1,10 +1,16 @@
 package s3client
 
 import (
+	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -16,12 +22,15 @@ import (
 )
 
 type s3Context struct {
-	svcClient   s3iface.S3API
-	uploader    s3manageriface.UploaderAPI
-	target      *config.TargetConfig
-	logger      log.Logger
-	metricsCtx  metrics.Client
-	parentTrace tracing.Trace
+	svcClient    s3iface.S3API
+	uploader     s3manageriface.UploaderAPI
+	credentials  *credentials.Credentials
+	target       *config.TargetConfig
+	logger       log.Logger
+	metricsCtx   metrics.Client
+	parentTrace  tracing.Trace
+	objectCache  *objectCache
+	listingCache *listingCache
 }
 
 // ListObjectsOperation List objects operation
@@ -39,8 +48,23 @@ const PutObjectOperation = "put-object"
 // DeleteObjectOperation Delete object operation
 const DeleteObjectOperation = "delete-object"
 
+// DeleteObjectsOperation Delete objects operation
+const DeleteObjectsOperation = "delete-objects"
+
+// ListObjectVersionsOperation List object versions operation
+const ListObjectVersionsOperation = "list-object-versions"
+
+// errCodeNoSuchVersion No such version error code from S3
+const errCodeNoSuchVersion = "NoSuchVersion"
+
+// maxDeleteObjectsKeys Maximum number of keys allowed in a delete objects request
+const maxDeleteObjectsKeys = 1000
+
+// errCodeInvalidRange Invalid range error code from S3
+const errCodeInvalidRange = "InvalidRange"
+
 // ListFilesAndDirectories List files and directories
-func (s3ctx *s3Context) ListFilesAndDirectories(key string) ([]*ListElementOutput, error) {
+func (s3ctx *s3Context) ListFilesAndDirectories(input *ListInput) (*ListOutput, error) {
 	// Create child trace
 	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.list-objects-request")
 	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
@@ -51,55 +75,171 @@ func (s3ctx *s3Context) ListFilesAndDirectories(key string) ([]*ListElementOutpu
 
 	defer childTrace.Finish()
 
+	// Check if listing can be answered with cache
+	if s3ctx.listingCache != nil {
+		return s3ctx.listFilesAndDirectoriesWithCache(input)
+	}
+
+	return s3ctx.listFilesAndDirectories(input)
+}
+
+// listFilesAndDirectoriesWithCache will get listing from memory cache or from S3 when it isn't cached or expired
+func (s3ctx *s3Context) listFilesAndDirectoriesWithCache(input *ListInput) (*ListOutput, error) {
+	// Get listing from cache
+	output := s3ctx.listingCache.get(input)
+	if output != nil {
+		// Metrics
+		s3ctx.metricsCtx.IncListingCacheHits(s3ctx.target.Name, s3ctx.target.Bucket.Name)
+
+		return output, nil
+	}
+	// Metrics
+	s3ctx.metricsCtx.IncListingCacheMisses(s3ctx.target.Name, s3ctx.target.Bucket.Name)
+	// Keep invalidation generation to ignore listing if an invalidation occurs during it
+	generation := s3ctx.listingCache.getGeneration()
+	// List from S3
+	output, err := s3ctx.listFilesAndDirectories(input)
+	if err != nil {
+		return nil, err
+	}
+	// Store listing in cache
+	s3ctx.listingCache.add(input, output, generation)
+
+	return output, nil
+}
+
+// listFilesAndDirectories will list files and directories from S3 bucket
+func (s3ctx *s3Context) listFilesAndDirectories(input *ListInput) (*ListOutput, error) {
 	// List files on path
 	folders := make([]*ListElementOutput, 0)
 	files := make([]*ListElementOutput, 0)
-	err := s3ctx.svcClient.ListObjectsV2Pages(
-		&s3.ListObjectsV2Input{
+	// Initialize continuation token
+	continuationToken := input.ContinuationToken
+	// Loop until all asked elements are listed or no more elements are available
+	// S3 will return at most 1000 elements per page
+	for count := int64(0); count < input.MaxKeys; {
+		s3Input := &s3.ListObjectsV2Input{
 			Bucket:    aws.String(s3ctx.target.Bucket.Name),
-			Prefix:    aws.String(key),
+			Prefix:    aws.String(input.Key),
 			Delimiter: aws.String("/"),
-		},
-		func(page *s3.ListObjectsV2Output, lastPage bool) bool {
-			// Manage folders
-			for _, item := range page.CommonPrefixes {
-				name := strings.TrimPrefix(*item.Prefix, key)
-				folders = append(folders, &ListElementOutput{
-					Type: FolderType,
-					Key:  *item.Prefix,
-					Name: name,
+			MaxKeys:   aws.Int64(input.MaxKeys - count),
+		}
+		// Add continuation token if it exists
+		if continuationToken != "" {
+			s3Input.ContinuationToken = aws.String(continuationToken)
+		}
+
+		page, err := s3ctx.svcClient.ListObjectsV2(s3Input)
+		// Metrics
+		s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, ListObjectsOperation)
+		// Check if errors exists
+		if err != nil {
+			return nil, err
+		}
+		// Manage folders
+		for _, item := range page.CommonPrefixes {
+			name := strings.TrimPrefix(*item.Prefix, input.Key)
+			folders = append(folders, &ListElementOutput{
+				Type: FolderType,
+				Key:  *item.Prefix,
+				Name: name,
+			})
+		}
+		// Manage files
+		for _, item := range page.Contents {
+			name := strings.TrimPrefix(*item.Key, input.Key)
+			if name != "" {
+				files = append(files, &ListElementOutput{
+					Type:         FileType,
+					ETag:         *item.ETag,
+					Name:         name,
+					LastModified: *item.LastModified,
+					Size:         *item.Size,
+					Key:          *item.Key,
 				})
 			}
-			// Manage files
+		}
+		// Update counter
+		count += int64(len(page.CommonPrefixes) + len(page.Contents))
+		// Check if there are more elements to list
+		if page.IsTruncated == nil || !*page.IsTruncated || page.NextContinuationToken == nil {
+			continuationToken = ""
+
+			break
+		}
+		// Store next continuation token
+		continuationToken = *page.NextContinuationToken
+	}
+	// Concat folders and files
+	all := append(folders, files...)
+
+	return &ListOutput{
+		Entries:               all,
+		NextContinuationToken: continuationToken,
+	}, nil
+}
+
+// ListAllObjects List all objects under a prefix without any delimiter
+func (s3ctx *s3Context) ListAllObjects(input *ListAllObjectsInput) (*ListAllObjectsOutput, error) {
+	// Create child trace
+	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.list-all-objects-request")
+	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
+	childTrace.SetTag("s3-bucket.bucket-region", s3ctx.target.Bucket.Region)
+	childTrace.SetTag("s3-bucket.bucket-prefix", s3ctx.target.Bucket.Prefix)
+	childTrace.SetTag("s3-bucket.bucket-s3-endpoint", s3ctx.target.Bucket.S3Endpoint)
+	childTrace.SetTag("s3-proxy.target-name", s3ctx.target.Name)
+
+	defer childTrace.Finish()
+
+	// Initialize output
+	output := &ListAllObjectsOutput{
+		Entries: make([]*ListElementOutput, 0),
+	}
+	// List all objects under prefix page by page
+	err := s3ctx.svcClient.ListObjectsV2PagesWithContext(
+		aws.BackgroundContext(),
+		&s3.ListObjectsV2Input{
+			Bucket: aws.String(s3ctx.target.Bucket.Name),
+			Prefix: aws.String(input.Prefix),
+		},
+		func(page *s3.ListObjectsV2Output, lastPage bool) bool {
 			for _, item := range page.Contents {
-				name := strings.TrimPrefix(*item.Key, key)
-				if name != "" {
-					files = append(files, &ListElementOutput{
-						Type:         FileType,
-						ETag:         *item.ETag,
-						Name:         name,
-						LastModified: *item.LastModified,
-						Size:         *item.Size,
-						Key:          *item.Key,
-					})
+				name := strings.TrimPrefix(*item.Key, input.Prefix)
+				// Ignore folder objects
+				if name == "" || strings.HasSuffix(name, "/") {
+					continue
+				}
+				// Check if maximum number of objects is reached
+				if int64(len(output.Entries)) >= input.MaxObjects {
+					output.Truncated = true
+
+					return false
 				}
+
+				output.Entries = append(output.Entries, &ListElementOutput{
+					Type:         FileType,
+					ETag:         aws.StringValue(item.ETag),
+					Name:         name,
+					LastModified: aws.TimeValue(item.LastModified),
+					Size:         aws.Int64Value(item.Size),
+					Key:          *item.Key,
+				})
 			}
-			return lastPage
-		})
-	// Metrics
-	s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, ListObjectsOperation)
+
+			return true
+		},
+		s3ctx.countRequests(ListObjectsOperation),
+	)
 	// Check if errors exists
 	if err != nil {
 		return nil, err
 	}
-	// Concat folders and files
-	all := append(folders, files...)
 
-	return all, nil
+	return output, nil
 }
 
 // GetObject Get object from S3 bucket
-func (s3ctx *s3Context) GetObject(key string) (*GetOutput, error) {
+func (s3ctx *s3Context) GetObject(input *GetInput) (*GetOutput, error) {
 	// Create child trace
 	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.get-object-request")
 	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
@@ -110,23 +250,152 @@ func (s3ctx *s3Context) GetObject(key string) (*GetOutput, error) {
 
 	defer childTrace.Finish()
 
-	obj, err := s3ctx.svcClient.GetObject(&s3.GetObjectInput{
-		Bucket: aws.String(s3ctx.target.Bucket.Name),
-		Key:    aws.String(key),
-	})
+	// Check if object can be answered with local cache
+	if s3ctx.objectCache != nil && isObjectCacheable(input) {
+		return s3ctx.getObjectWithCache(input)
+	}
+
+	return s3ctx.getObject(input)
+}
+
+// getObjectWithCache will get object from local cache and will revalidate it with S3 after TTL
+// Objects downloaded from S3 are stored in cache while they are sent.
+func (s3ctx *s3Context) getObjectWithCache(input *GetInput) (*GetOutput, error) {
+	cache := s3ctx.objectCache
+	// Get entry from cache
+	entry := cache.get(input.Key)
+	if entry != nil && cache.isFresh(entry) {
+		output, err := cache.open(entry, input)
+		if err == nil || err == ErrNotModified {
+			// Metrics
+			s3ctx.metricsCtx.IncObjectCacheHits(s3ctx.target.Name, s3ctx.target.Bucket.Name)
+
+			return output, err
+		}
+		// Cache file cannot be read, object will be downloaded again
+		s3ctx.logger.Error(err)
+
+		entry = nil
+	}
+	// Keep invalidation generation to ignore object if it is invalidated during download
+	generation := cache.getGeneration()
+	// Revalidate entry or download object
+	// Request conditions are sent to S3 on a miss to avoid downloading a not modified object.
+	s3Input := &GetInput{Key: input.Key, IfNoneMatch: input.IfNoneMatch, IfModifiedSince: input.IfModifiedSince}
+	if entry != nil {
+		s3Input = &GetInput{Key: input.Key, IfNoneMatch: entry.metadata.ETag}
+	}
+
+	output, err := s3ctx.getObject(s3Input)
+	// Check if entry is still valid
+	if err == ErrNotModified && entry != nil {
+		cache.markValidated(entry)
+
+		output, err = cache.open(entry, input)
+		if err == nil || err == ErrNotModified {
+			// Metrics
+			s3ctx.metricsCtx.IncObjectCacheHits(s3ctx.target.Name, s3ctx.target.Bucket.Name)
+
+			return output, err
+		}
+		// Cache file cannot be read, object will be downloaded again
+		s3ctx.logger.Error(err)
+
+		output, err = s3ctx.getObject(&GetInput{Key: input.Key, IfNoneMatch: input.IfNoneMatch, IfModifiedSince: input.IfModifiedSince})
+	}
+	// Metrics
+	s3ctx.metricsCtx.IncObjectCacheMisses(s3ctx.target.Name, s3ctx.target.Bucket.Name)
+	// Check error
+	if err != nil {
+		// Remove deleted objects from cache
+		if err == ErrNotFound {
+			cache.remove(input.Key)
+		}
+
+		return nil, err
+	}
+	// Check request conditions on object downloaded after a revalidation
+	if isNotModified(input, output) {
+		(*output.Body).Close()
+
+		return nil, ErrNotModified
+	}
+	// Check if object can be stored in cache
+	if output.ContentLength > cache.maxObjectSize || output.ContentLength > cache.maxSize {
+		return output, nil
+	}
+	// Store object in cache while it is read
+	body, err := cache.newFillReader(input.Key, output, generation)
+	if err != nil {
+		// Object is sent without cache
+		s3ctx.logger.Error(err)
+
+		return output, nil
+	}
+
+	output.Body = &body
+
+	return output, nil
+}
+
+// getObject will get object from S3 bucket
+func (s3ctx *s3Context) getObject(input *GetInput) (*GetOutput, error) {
+	// Get server side encryption customer key
+	sseCustomerKey, err := s3ctx.getSSECustomerKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if only object headers are asked
+	if input.HeadOnly {
+		return s3ctx.headObjectForGet(input, sseCustomerKey)
+	}
+
+	// Build input
+	s3Input, ifRangeApplied := s3ctx.buildGetObjectInput(input, true)
+	setGetObjectSSECustomerKey(s3Input, sseCustomerKey)
+
+	obj, err := s3ctx.svcClient.GetObject(s3Input)
 	// Metrics
 	s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, GetObjectOperation)
+	// Check if If-Range condition wasn't respected
+	// In this case, object have changed and the full object must be sent
+	if err != nil && ifRangeApplied && isAWSRequestFailureStatus(err, http.StatusPreconditionFailed) {
+		// Build input without range
+		s3Input, _ = s3ctx.buildGetObjectInput(input, false)
+		setGetObjectSSECustomerKey(s3Input, sseCustomerKey)
+		// Get full object
+		obj, err = s3ctx.svcClient.GetObject(s3Input)
+		// Metrics
+		s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, GetObjectOperation)
+	}
 	// Check if error exists
 	if err != nil {
-		// Try to cast error into an AWS Error if possible
-		aerr, ok := err.(awserr.Error)
-		if ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		// Check if it is a not found error
+		if isAWSErrorCode(err, s3.ErrCodeNoSuchKey) || isAWSErrorCode(err, errCodeNoSuchVersion) {
 			return nil, ErrNotFound
 		}
+		// Check if it is a range error
+		if isAWSErrorCode(err, errCodeInvalidRange) {
+			return nil, ErrRangeNotSatisfiable
+		}
+		// Check if it is a not modified answer
+		if isAWSRequestFailureStatus(err, http.StatusNotModified) {
+			return nil, ErrNotModified
+		}
+		// Check if it is a precondition failed error
+		if isAWSRequestFailureStatus(err, http.StatusPreconditionFailed) {
+			return nil, ErrPreconditionFailed
+		}
 
 		return nil, err
 	}
-	// Build output
+
+	return newGetOutput(obj), nil
+}
+
+// newGetOutput will build get output from S3 object
+func newGetOutput(obj *s3.GetObjectOutput) *GetOutput {
 	output := &GetOutput{
 		Body: &obj.Body,
 	}
@@ -171,7 +440,291 @@ func (s3ctx *s3Context) GetObject(key string) (*GetOutput, error) {
 		output.LastModified = *obj.LastModified
 	}
 
-	return output, nil
+	if obj.WebsiteRedirectLocation != nil {
+		output.WebsiteRedirectLocation = *obj.WebsiteRedirectLocation
+	}
+
+	return output
+}
+
+// headObjectForGet will answer a get request with object headers and an empty body using a head request
+// Range is ignored as HEAD requests must not have partial answers (RFC 7233).
+func (s3ctx *s3Context) headObjectForGet(input *GetInput, sseCustomerKey string) (*GetOutput, error) {
+	// Build input from get input to keep version and conditions
+	getInput, _ := s3ctx.buildGetObjectInput(input, false)
+	s3Input := &s3.HeadObjectInput{
+		Bucket:            getInput.Bucket,
+		Key:               getInput.Key,
+		VersionId:         getInput.VersionId,
+		IfMatch:           getInput.IfMatch,
+		IfNoneMatch:       getInput.IfNoneMatch,
+		IfModifiedSince:   getInput.IfModifiedSince,
+		IfUnmodifiedSince: getInput.IfUnmodifiedSince,
+	}
+	// Customer key is needed to get headers of objects encrypted with it
+	if sseCustomerKey != "" {
+		s3Input.SSECustomerAlgorithm = aws.String(config.SSECustomerAlgorithm)
+		s3Input.SSECustomerKey = aws.String(sseCustomerKey)
+	}
+
+	obj, err := s3ctx.svcClient.HeadObject(s3Input)
+	// Metrics
+	s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, HeadObjectOperation)
+	// Check if error exists
+	if err != nil {
+		// Head answers don't have a body so errors can only be found with status code
+		if isAWSRequestFailureStatus(err, http.StatusNotFound) {
+			return nil, ErrNotFound
+		}
+		// Check if it is a not modified answer
+		if isAWSRequestFailureStatus(err, http.StatusNotModified) {
+			return nil, ErrNotModified
+		}
+		// Check if it is a precondition failed error
+		if isAWSRequestFailureStatus(err, http.StatusPreconditionFailed) {
+			return nil, ErrPreconditionFailed
+		}
+
+		return nil, err
+	}
+	// Build output with an empty body
+	return newGetOutput(&s3.GetObjectOutput{
+		Body:                    http.NoBody,
+		CacheControl:            obj.CacheControl,
+		Expires:                 obj.Expires,
+		ContentDisposition:      obj.ContentDisposition,
+		ContentEncoding:         obj.ContentEncoding,
+		ContentLanguage:         obj.ContentLanguage,
+		ContentLength:           obj.ContentLength,
+		ContentType:             obj.ContentType,
+		ETag:                    obj.ETag,
+		LastModified:            obj.LastModified,
+		WebsiteRedirectLocation: obj.WebsiteRedirectLocation,
+	}), nil
+}
+
+// PresignGetObject will generate a presigned URL to get object from S3 bucket
+func (s3ctx *s3Context) PresignGetObject(input *PresignGetInput) (string, error) {
+	// Create child trace
+	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.presign-get-object-request")
+	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
+	childTrace.SetTag("s3-bucket.bucket-region", s3ctx.target.Bucket.Region)
+	childTrace.SetTag("s3-bucket.bucket-prefix", s3ctx.target.Bucket.Prefix)
+	childTrace.SetTag("s3-bucket.bucket-s3-endpoint", s3ctx.target.Bucket.S3Endpoint)
+	childTrace.SetTag("s3-proxy.target-name", s3ctx.target.Name)
+
+	defer childTrace.Finish()
+
+	// Build request without sending it
+	req, _ := s3ctx.svcClient.GetObjectRequest(buildPresignGetObjectInput(s3ctx.target.Bucket.Name, input))
+
+	// Sign request
+	return req.Presign(input.Expiry)
+}
+
+// PresignPutObject will generate a presigned URL to put object in S3 bucket
+func (s3ctx *s3Context) PresignPutObject(input *PresignPutInput) (*PresignPutOutput, error) {
+	// Create child trace
+	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.presign-put-object-request")
+	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
+	childTrace.SetTag("s3-bucket.bucket-region", s3ctx.target.Bucket.Region)
+	childTrace.SetTag("s3-bucket.bucket-prefix", s3ctx.target.Bucket.Prefix)
+	childTrace.SetTag("s3-bucket.bucket-s3-endpoint", s3ctx.target.Bucket.S3Endpoint)
+	childTrace.SetTag("s3-proxy.target-name", s3ctx.target.Name)
+
+	defer childTrace.Finish()
+
+	s3Input := &s3.PutObjectInput{
+		Bucket: aws.String(s3ctx.target.Bucket.Name),
+		Key:    aws.String(input.Key),
+	}
+	// Add metadata, tags and storage class to signed headers
+	if input.Metadata != nil {
+		s3Input.Metadata = aws.StringMap(input.Metadata)
+	}
+
+	if len(input.Tags) != 0 {
+		s3Input.Tagging = aws.String(encodeTagging(input.Tags))
+	}
+
+	if input.StorageClass != "" {
+		s3Input.StorageClass = aws.String(input.StorageClass)
+	}
+	// Add declared content type and size to signed headers
+	if input.ContentType != "" {
+		s3Input.ContentType = aws.String(input.ContentType)
+	}
+
+	if input.Size >= 0 {
+		s3Input.ContentLength = aws.Int64(input.Size)
+	}
+	// Add server side encryption to signed headers
+	// Customer keys cannot be used here because they would be sent to client
+	if encryptionCfg := s3ctx.target.Bucket.Encryption; encryptionCfg != nil {
+		if encryptionCfg.ServerSideEncryption != "" {
+			s3Input.ServerSideEncryption = aws.String(encryptionCfg.ServerSideEncryption)
+		}
+
+		if encryptionCfg.KMSKeyID != "" {
+			s3Input.SSEKMSKeyId = aws.String(encryptionCfg.KMSKeyID)
+		}
+	}
+
+	// Build request without sending it
+	req, _ := s3ctx.svcClient.PutObjectRequest(s3Input)
+
+	// Sign request
+	url, signedHeaders, err := req.PresignRequest(input.Expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep headers that client must send
+	headers := map[string]string{}
+	for k, v := range signedHeaders {
+		// Signed header names can be in lower case so they must be canonicalized
+		k = http.CanonicalHeaderKey(k)
+		if k != "Host" && len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+
+	return &PresignPutOutput{URL: url, Headers: headers}, nil
+}
+
+// PresignPostObject will generate a presigned POST form to put object in S3 bucket
+func (s3ctx *s3Context) PresignPostObject(input *PresignPutInput) (*PresignPostOutput, error) {
+	// Create child trace
+	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.presign-post-object-request")
+	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
+	childTrace.SetTag("s3-bucket.bucket-region", s3ctx.target.Bucket.Region)
+	childTrace.SetTag("s3-bucket.bucket-prefix", s3ctx.target.Bucket.Prefix)
+	childTrace.SetTag("s3-bucket.bucket-s3-endpoint", s3ctx.target.Bucket.S3Endpoint)
+	childTrace.SetTag("s3-proxy.target-name", s3ctx.target.Name)
+
+	defer childTrace.Finish()
+
+	// Get credentials used to sign policy
+	creds, err := s3ctx.credentials.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	// Build bucket URL with a request that won't be sent
+	req, _ := s3ctx.svcClient.HeadBucketRequest(&s3.HeadBucketInput{
+		Bucket: aws.String(s3ctx.target.Bucket.Name),
+	})
+
+	err = req.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	u := *req.HTTPRequest.URL
+	u.RawQuery = ""
+
+	// Generate signed form fields
+	fields, err := generatePostFields(
+		creds, s3ctx.target.Bucket.Region, s3ctx.target.Bucket.Name, s3ctx.target.Bucket.Encryption, input, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresignPostOutput{URL: u.String(), Fields: fields}, nil
+}
+
+// buildPresignGetObjectInput will build S3 get object input with response headers overrides
+func buildPresignGetObjectInput(bucketName string, input *PresignGetInput) *s3.GetObjectInput {
+	s3Input := &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(input.Key),
+	}
+	// Manage version
+	if input.VersionID != "" {
+		s3Input.VersionId = aws.String(input.VersionID)
+	}
+	// Manage response headers overrides
+	if input.ResponseCacheControl != "" {
+		s3Input.ResponseCacheControl = aws.String(input.ResponseCacheControl)
+	}
+
+	if input.ResponseContentDisposition != "" {
+		s3Input.ResponseContentDisposition = aws.String(input.ResponseContentDisposition)
+	}
+
+	if input.ResponseContentEncoding != "" {
+		s3Input.ResponseContentEncoding = aws.String(input.ResponseContentEncoding)
+	}
+
+	if input.ResponseContentLanguage != "" {
+		s3Input.ResponseContentLanguage = aws.String(input.ResponseContentLanguage)
+	}
+
+	if input.ResponseContentType != "" {
+		s3Input.ResponseContentType = aws.String(input.ResponseContentType)
+	}
+
+	return s3Input
+}
+
+// buildGetObjectInput will build S3 get object input with conditions and range if asked
+// Will return true if the If-Range condition have been applied
+func (s3ctx *s3Context) buildGetObjectInput(input *GetInput, withRange bool) (*s3.GetObjectInput, bool) {
+	s3Input := &s3.GetObjectInput{
+		Bucket: aws.String(s3ctx.target.Bucket.Name),
+		Key:    aws.String(input.Key),
+	}
+	// Manage version
+	if input.VersionID != "" {
+		s3Input.VersionId = aws.String(input.VersionID)
+	}
+	// Manage conditional requests
+	if input.IfMatch != "" {
+		s3Input.IfMatch = aws.String(input.IfMatch)
+	}
+
+	if input.IfNoneMatch != "" {
+		s3Input.IfNoneMatch = aws.String(input.IfNoneMatch)
+	}
+
+	if input.IfModifiedSince != nil {
+		s3Input.IfModifiedSince = input.IfModifiedSince
+	}
+
+	if input.IfUnmodifiedSince != nil {
+		s3Input.IfUnmodifiedSince = input.IfUnmodifiedSince
+	}
+	// Check if range must be managed
+	if !withRange || input.Range == "" {
+		return s3Input, false
+	}
+	// Manage range case
+	s3Input.Range = aws.String(input.Range)
+	// Check if range is conditional
+	if input.IfRange == "" {
+		return s3Input, false
+	}
+	// If-Range can be a http date or an etag
+	// Condition is applied only if it doesn't override a condition asked by client
+	ifRangeDate, err := http.ParseTime(input.IfRange)
+	if err == nil {
+		if s3Input.IfUnmodifiedSince != nil {
+			return s3Input, false
+		}
+
+		s3Input.IfUnmodifiedSince = aws.Time(ifRangeDate)
+
+		return s3Input, true
+	}
+
+	if s3Input.IfMatch != nil {
+		return s3Input, false
+	}
+
+	s3Input.IfMatch = aws.String(input.IfRange)
+
+	return s3Input, true
 }
 
 func (s3ctx *s3Context) PutObject(input *PutInput) error {
@@ -185,6 +738,22 @@ func (s3ctx *s3Context) PutObject(input *PutInput) error {
 
 	defer childTrace.Finish()
 
+	inp, err := s3ctx.buildUploadInput(input)
+	if err != nil {
+		return err
+	}
+	// Upload to S3 bucket
+	_, err = s3ctx.uploader.Upload(inp)
+	// Metrics
+	s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, PutObjectOperation)
+	// Invalidate caches
+	s3ctx.invalidateCaches(input.Key)
+	// Return error
+	return err
+}
+
+// buildUploadInput will build S3 upload input with bucket server side encryption
+func (s3ctx *s3Context) buildUploadInput(input *PutInput) (*s3manager.UploadInput, error) {
 	inp := &s3manager.UploadInput{
 		Bucket: aws.String(s3ctx.target.Bucket.Name),
 		Key:    aws.String(input.Key),
@@ -198,16 +767,67 @@ func (s3ctx *s3Context) PutObject(input *PutInput) error {
 	if input.Metadata != nil {
 		inp.Metadata = aws.StringMap(input.Metadata)
 	}
+	// Manage tags case
+	if len(input.Tags) != 0 {
+		inp.Tagging = aws.String(encodeTagging(input.Tags))
+	}
 	// Manage storage class
 	if input.StorageClass != "" {
 		inp.StorageClass = aws.String(input.StorageClass)
 	}
-	// Upload to S3 bucket
-	_, err := s3ctx.uploader.Upload(inp)
-	// Metrics
-	s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, PutObjectOperation)
-	// Return error
-	return err
+	// Check if encryption is configured
+	encryptionCfg := s3ctx.target.Bucket.Encryption
+	if encryptionCfg == nil {
+		return inp, nil
+	}
+	// Manage server side encryption
+	if encryptionCfg.ServerSideEncryption != "" {
+		inp.ServerSideEncryption = aws.String(encryptionCfg.ServerSideEncryption)
+	}
+
+	if encryptionCfg.KMSKeyID != "" {
+		inp.SSEKMSKeyId = aws.String(encryptionCfg.KMSKeyID)
+	}
+	// Manage customer key
+	sseCustomerKey, err := encryptionCfg.GetCustomerKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if sseCustomerKey != "" {
+		inp.SSECustomerAlgorithm = aws.String(config.SSECustomerAlgorithm)
+		inp.SSECustomerKey = aws.String(sseCustomerKey)
+	}
+
+	return inp, nil
+}
+
+// encodeTagging will encode tags as URL query parameters like S3 is expecting them in tagging header
+func encodeTagging(tags map[string]string) string {
+	values := url.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	// Encode will sort keys
+	return values.Encode()
+}
+
+// getSSECustomerKey will return server side encryption customer key of bucket (empty if not configured)
+func (s3ctx *s3Context) getSSECustomerKey() (string, error) {
+	// Check if encryption is configured
+	if s3ctx.target.Bucket.Encryption == nil {
+		return "", nil
+	}
+
+	return s3ctx.target.Bucket.Encryption.GetCustomerKey()
+}
+
+// setGetObjectSSECustomerKey will add server side encryption customer key in get object input if not empty
+func setGetObjectSSECustomerKey(s3Input *s3.GetObjectInput, sseCustomerKey string) {
+	if sseCustomerKey != "" {
+		s3Input.SSECustomerAlgorithm = aws.String(config.SSECustomerAlgorithm)
+		s3Input.SSECustomerKey = aws.String(sseCustomerKey)
+	}
 }
 
 func (s3ctx *s3Context) HeadObject(key string) (*HeadOutput, error) {
@@ -221,11 +841,24 @@ func (s3ctx *s3Context) HeadObject(key string) (*HeadOutput, error) {
 
 	defer childTrace.Finish()
 
-	// Head object in bucket
-	_, err := s3ctx.svcClient.HeadObject(&s3.HeadObjectInput{
+	// Get server side encryption customer key
+	sseCustomerKey, err := s3ctx.getSSECustomerKey()
+	if err != nil {
+		return nil, err
+	}
+
+	s3Input := &s3.HeadObjectInput{
 		Bucket: aws.String(s3ctx.target.Bucket.Name),
 		Key:    aws.String(key),
-	})
+	}
+	// Customer key is needed to get headers of objects encrypted with it
+	if sseCustomerKey != "" {
+		s3Input.SSECustomerAlgorithm = aws.String(config.SSECustomerAlgorithm)
+		s3Input.SSECustomerKey = aws.String(sseCustomerKey)
+	}
+
+	// Head object in bucket
+	obj, err := s3ctx.svcClient.HeadObject(s3Input)
 	// Metrics
 	s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, HeadObjectOperation)
 	// Test error
@@ -246,11 +879,47 @@ func (s3ctx *s3Context) HeadObject(key string) (*HeadOutput, error) {
 		Type: FileType,
 		Key:  key,
 	}
+
+	if obj.CacheControl != nil {
+		output.CacheControl = *obj.CacheControl
+	}
+
+	if obj.Expires != nil {
+		output.Expires = *obj.Expires
+	}
+
+	if obj.ContentDisposition != nil {
+		output.ContentDisposition = *obj.ContentDisposition
+	}
+
+	if obj.ContentEncoding != nil {
+		output.ContentEncoding = *obj.ContentEncoding
+	}
+
+	if obj.ContentLanguage != nil {
+		output.ContentLanguage = *obj.ContentLanguage
+	}
+
+	if obj.ContentLength != nil {
+		output.ContentLength = *obj.ContentLength
+	}
+
+	if obj.ContentType != nil {
+		output.ContentType = *obj.ContentType
+	}
+
+	if obj.ETag != nil {
+		output.ETag = *obj.ETag
+	}
+
+	if obj.LastModified != nil {
+		output.LastModified = *obj.LastModified
+	}
 	// Return output
 	return output, nil
 }
 
-func (s3ctx *s3Context) DeleteObject(key string) error {
+func (s3ctx *s3Context) DeleteObject(input *DeleteInput) error {
 	// Create child trace
 	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.delete-object-request")
 	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
@@ -261,13 +930,245 @@ func (s3ctx *s3Context) DeleteObject(key string) error {
 
 	defer childTrace.Finish()
 
-	// Delete object
-	_, err := s3ctx.svcClient.DeleteObject(&s3.DeleteObjectInput{
+	s3Input := &s3.DeleteObjectInput{
 		Bucket: aws.String(s3ctx.target.Bucket.Name),
-		Key:    aws.String(key),
-	})
+		Key:    aws.String(input.Key),
+	}
+	// Manage version
+	if input.VersionID != "" {
+		s3Input.VersionId = aws.String(input.VersionID)
+	}
+	// Delete object
+	_, err := s3ctx.svcClient.DeleteObject(s3Input)
 	// Metrics
 	s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, DeleteObjectOperation)
+	// Invalidate caches
+	s3ctx.invalidateCaches(input.Key)
 	// Return error
 	return err
 }
+
+// ListObjectVersions List all versions and delete markers of an object from the most recent to the oldest
+func (s3ctx *s3Context) ListObjectVersions(key string) ([]*ObjectVersionOutput, error) {
+	// Create child trace
+	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.list-object-versions-request")
+	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
+	childTrace.SetTag("s3-bucket.bucket-region", s3ctx.target.Bucket.Region)
+	childTrace.SetTag("s3-bucket.bucket-prefix", s3ctx.target.Bucket.Prefix)
+	childTrace.SetTag("s3-bucket.bucket-s3-endpoint", s3ctx.target.Bucket.S3Endpoint)
+	childTrace.SetTag("s3-proxy.target-name", s3ctx.target.Name)
+
+	defer childTrace.Finish()
+
+	// Initialize output
+	output := make([]*ObjectVersionOutput, 0)
+	// List versions with key as prefix page by page
+	// Other keys beginning with the same prefix are ignored
+	err := s3ctx.svcClient.ListObjectVersionsPagesWithContext(
+		aws.BackgroundContext(),
+		&s3.ListObjectVersionsInput{
+			Bucket: aws.String(s3ctx.target.Bucket.Name),
+			Prefix: aws.String(key),
+		},
+		func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+			// Keys are sorted so listing can be stopped after object key
+			keyPassed := false
+
+			for _, item := range page.Versions {
+				if aws.StringValue(item.Key) != key {
+					keyPassed = keyPassed || aws.StringValue(item.Key) > key
+
+					continue
+				}
+
+				output = append(output, &ObjectVersionOutput{
+					VersionID:    aws.StringValue(item.VersionId),
+					ETag:         aws.StringValue(item.ETag),
+					LastModified: aws.TimeValue(item.LastModified),
+					Size:         aws.Int64Value(item.Size),
+					IsLatest:     aws.BoolValue(item.IsLatest),
+				})
+			}
+
+			for _, item := range page.DeleteMarkers {
+				if aws.StringValue(item.Key) != key {
+					keyPassed = keyPassed || aws.StringValue(item.Key) > key
+
+					continue
+				}
+
+				output = append(output, &ObjectVersionOutput{
+					VersionID:    aws.StringValue(item.VersionId),
+					LastModified: aws.TimeValue(item.LastModified),
+					IsLatest:     aws.BoolValue(item.IsLatest),
+					DeleteMarker: true,
+				})
+			}
+
+			return !keyPassed
+		},
+		// Metrics
+		s3ctx.countRequests(ListObjectVersionsOperation),
+	)
+	// Check if errors exists
+	if err != nil {
+		return nil, err
+	}
+	// Versions and delete markers are returned separately so sort them by date
+	sort.SliceStable(output, func(i, j int) bool {
+		return output[i].LastModified.After(output[j].LastModified)
+	})
+
+	return output, nil
+}
+
+// DeleteFolder Delete all objects under a prefix
+func (s3ctx *s3Context) DeleteFolder(input *DeleteFolderInput) (*DeleteFolderOutput, error) {
+	// Create child trace
+	childTrace := s3ctx.parentTrace.GetChildTrace("s3-bucket.delete-folder-request")
+	childTrace.SetTag("s3-bucket.bucket-name", s3ctx.target.Bucket.Name)
+	childTrace.SetTag("s3-bucket.bucket-region", s3ctx.target.Bucket.Region)
+	childTrace.SetTag("s3-bucket.bucket-prefix", s3ctx.target.Bucket.Prefix)
+	childTrace.SetTag("s3-bucket.bucket-s3-endpoint", s3ctx.target.Bucket.S3Endpoint)
+	childTrace.SetTag("s3-proxy.target-name", s3ctx.target.Name)
+
+	defer childTrace.Finish()
+
+	// Initialize output
+	output := &DeleteFolderOutput{
+		DeletedKeys: make([]string, 0),
+		Errors:      make([]*DeleteErrorOutput, 0),
+	}
+	// Store delete error to stop pagination
+	var deleteErr error
+	// List all objects under prefix with pages of the maximum number of keys allowed in a delete objects request
+	err := s3ctx.svcClient.ListObjectsV2PagesWithContext(
+		aws.BackgroundContext(),
+		&s3.ListObjectsV2Input{
+			Bucket:  aws.String(s3ctx.target.Bucket.Name),
+			Prefix:  aws.String(input.Prefix),
+			MaxKeys: aws.Int64(maxDeleteObjectsKeys),
+		},
+		func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			// Get keys
+			keys := make([]string, 0, len(page.Contents))
+			for _, item := range page.Contents {
+				keys = append(keys, *item.Key)
+			}
+			// Check if there is something to delete
+			if len(keys) == 0 {
+				return true
+			}
+			// Check if it is a dry run
+			if input.DryRun {
+				output.DeletedKeys = append(output.DeletedKeys, keys...)
+
+				return true
+			}
+			// Delete objects
+			deleteErr = s3ctx.deleteObjects(keys, output)
+
+			return deleteErr == nil
+		},
+		s3ctx.countRequests(ListObjectsOperation),
+	)
+	// Invalidate caches
+	if !input.DryRun {
+		s3ctx.invalidateFolderCaches(input.Prefix)
+	}
+	// Check if errors exists
+	if err != nil {
+		return nil, err
+	}
+
+	if deleteErr != nil {
+		return nil, deleteErr
+	}
+
+	return output, nil
+}
+
+// countRequests returns a request option adding each page request of a paginated listing in metrics
+func (s3ctx *s3Context) countRequests(operation string) request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Complete.PushBack(func(r *request.Request) {
+			s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, operation)
+		})
+	}
+}
+
+// invalidateCaches will remove cached object and cached listings of its parent folders after a write
+func (s3ctx *s3Context) invalidateCaches(key string) {
+	if s3ctx.objectCache != nil {
+		s3ctx.objectCache.remove(key)
+	}
+
+	if s3ctx.listingCache != nil {
+		s3ctx.listingCache.invalidate(key)
+	}
+}
+
+// invalidateFolderCaches will remove cached objects and cached listings under a folder and of its parent folders
+func (s3ctx *s3Context) invalidateFolderCaches(prefix string) {
+	if s3ctx.objectCache != nil {
+		s3ctx.objectCache.removePrefix(prefix)
+	}
+
+	if s3ctx.listingCache != nil {
+		s3ctx.listingCache.invalidate(prefix)
+	}
+}
+
+// deleteObjects will delete keys in one request and fill output with results
+func (s3ctx *s3Context) deleteObjects(keys []string, output *DeleteFolderOutput) error {
+	// Build object identifiers
+	objects := make([]*s3.ObjectIdentifier, 0, len(keys))
+	for _, key := range keys {
+		objects = append(objects, &s3.ObjectIdentifier{Key: aws.String(key)})
+	}
+	// Delete objects in quiet mode to get only errors
+	res, err := s3ctx.svcClient.DeleteObjects(&s3.DeleteObjectsInput{
+		Bucket: aws.String(s3ctx.target.Bucket.Name),
+		Delete: &s3.Delete{
+			Objects: objects,
+			Quiet:   aws.Bool(true),
+		},
+	})
+	// Metrics
+	s3ctx.metricsCtx.IncS3Operations(s3ctx.target.Name, s3ctx.target.Bucket.Name, DeleteObjectsOperation)
+	// Check if error exists
+	if err != nil {
+		return err
+	}
+	// Manage errors
+	failedKeys := map[string]bool{}
+	for _, item := range res.Errors {
+		deleteErr := &DeleteErrorOutput{Key: aws.StringValue(item.Key), Code: aws.StringValue(item.Code), Message: aws.StringValue(item.Message)}
+		output.Errors = append(output.Errors, deleteErr)
+		failedKeys[deleteErr.Key] = true
+	}
+	// Manage deleted keys
+	for _, key := range keys {
+		if !failedKeys[key] {
+			output.DeletedKeys = append(output.DeletedKeys, key)
+		}
+	}
+
+	return nil
+}
+
+// isAWSErrorCode will check if error is an AWS error with the given code
+func isAWSErrorCode(err error, code string) bool {
+	// Try to cast error into an AWS Error if possible
+	aerr, ok := err.(awserr.Error)
+
+	return ok && aerr.Code() == code
+}
+
+// isAWSRequestFailureStatus will check if error is an AWS request failure with the given http status code
+func isAWSRequestFailureStatus(err error, statusCode int) bool {
+	// Try to cast error into an AWS request failure if possible
+	reqErr, ok := err.(awserr.RequestFailure)
+
+	return ok && reqErr.StatusCode() == statusCode
+}
@@ -0,0 +1,496 @@
+// +build unit
+
+package s3client
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_s3Context_buildGetObjectInput(t *testing.T) {
+	fakeDate := time.Date(1990, time.December, 25, 1, 1, 1, 0, time.UTC)
+	tgt := &config.TargetConfig{
+		Bucket: &config.BucketConfig{Name: "bucket"},
+	}
+	type args struct {
+		input     *GetInput
+		withRange bool
+	}
+	tests := []struct {
+		name               string
+		args               args
+		want               *s3.GetObjectInput
+		wantIfRangeApplied bool
+	}{
+		{
+			name: "Simple key",
+			args: args{
+				input:     &GetInput{Key: "key"},
+				withRange: true,
+			},
+			want: &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")},
+		},
+		{
+			name: "Version",
+			args: args{
+				input:     &GetInput{Key: "key", VersionID: "version1"},
+				withRange: true,
+			},
+			want: &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key"), VersionId: aws.String("version1")},
+		},
+		{
+			name: "Conditions",
+			args: args{
+				input: &GetInput{
+					Key:               "key",
+					IfMatch:           "etag1",
+					IfNoneMatch:       "etag2",
+					IfModifiedSince:   &fakeDate,
+					IfUnmodifiedSince: &fakeDate,
+				},
+				withRange: true,
+			},
+			want: &s3.GetObjectInput{
+				Bucket:            aws.String("bucket"),
+				Key:               aws.String("key"),
+				IfMatch:           aws.String("etag1"),
+				IfNoneMatch:       aws.String("etag2"),
+				IfModifiedSince:   &fakeDate,
+				IfUnmodifiedSince: &fakeDate,
+			},
+		},
+		{
+			name: "Range without If-Range",
+			args: args{
+				input:     &GetInput{Key: "key", Range: "bytes=0-10"},
+				withRange: true,
+			},
+			want: &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key"), Range: aws.String("bytes=0-10")},
+		},
+		{
+			name: "Range ignored",
+			args: args{
+				input:     &GetInput{Key: "key", Range: "bytes=0-10", IfRange: "etag"},
+				withRange: false,
+			},
+			want: &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")},
+		},
+		{
+			name: "Range with If-Range etag",
+			args: args{
+				input:     &GetInput{Key: "key", Range: "bytes=0-10", IfRange: "\"etag\""},
+				withRange: true,
+			},
+			want: &s3.GetObjectInput{
+				Bucket:  aws.String("bucket"),
+				Key:     aws.String("key"),
+				Range:   aws.String("bytes=0-10"),
+				IfMatch: aws.String("\"etag\""),
+			},
+			wantIfRangeApplied: true,
+		},
+		{
+			name: "Range with If-Range date",
+			args: args{
+				input:     &GetInput{Key: "key", Range: "bytes=0-10", IfRange: "Tue, 25 Dec 1990 01:01:01 GMT"},
+				withRange: true,
+			},
+			want: &s3.GetObjectInput{
+				Bucket:            aws.String("bucket"),
+				Key:               aws.String("key"),
+				Range:             aws.String("bytes=0-10"),
+				IfUnmodifiedSince: &fakeDate,
+			},
+			wantIfRangeApplied: true,
+		},
+		{
+			name: "Range with If-Range etag shouldn't override client If-Match",
+			args: args{
+				input:     &GetInput{Key: "key", Range: "bytes=0-10", IfRange: "etag", IfMatch: "etag1"},
+				withRange: true,
+			},
+			want: &s3.GetObjectInput{
+				Bucket:  aws.String("bucket"),
+				Key:     aws.String("key"),
+				Range:   aws.String("bytes=0-10"),
+				IfMatch: aws.String("etag1"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3ctx := &s3Context{target: tgt}
+			got, gotIfRangeApplied := s3ctx.buildGetObjectInput(tt.args.input, tt.args.withRange)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("s3Context.buildGetObjectInput() got = %+v, want %+v", got, tt.want)
+			}
+			if gotIfRangeApplied != tt.wantIfRangeApplied {
+				t.Errorf("s3Context.buildGetObjectInput() ifRangeApplied = %v, want %v", gotIfRangeApplied, tt.wantIfRangeApplied)
+			}
+		})
+	}
+}
+
+func Test_buildPresignGetObjectInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *PresignGetInput
+		want  *s3.GetObjectInput
+	}{
+		{
+			name:  "Simple key",
+			input: &PresignGetInput{Key: "key", Expiry: time.Minute},
+			want:  &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")},
+		},
+		{
+			name:  "Version",
+			input: &PresignGetInput{Key: "key", VersionID: "version1", Expiry: time.Minute},
+			want:  &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key"), VersionId: aws.String("version1")},
+		},
+		{
+			name: "Response headers overrides",
+			input: &PresignGetInput{
+				Key:                        "key",
+				ResponseCacheControl:       "no-cache",
+				ResponseContentDisposition: "attachment",
+				ResponseContentEncoding:    "gzip",
+				ResponseContentLanguage:    "en",
+				ResponseContentType:        "text/plain",
+			},
+			want: &s3.GetObjectInput{
+				Bucket:                     aws.String("bucket"),
+				Key:                        aws.String("key"),
+				ResponseCacheControl:       aws.String("no-cache"),
+				ResponseContentDisposition: aws.String("attachment"),
+				ResponseContentEncoding:    aws.String("gzip"),
+				ResponseContentLanguage:    aws.String("en"),
+				ResponseContentType:        aws.String("text/plain"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildPresignGetObjectInput("bucket", tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildPresignGetObjectInput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_s3Context_buildUploadInput(t *testing.T) {
+	body := strings.NewReader("body")
+	tests := []struct {
+		name       string
+		encryption *config.BucketEncryptionConfig
+		input      *PutInput
+		want       *s3manager.UploadInput
+		wantErr    bool
+	}{
+		{
+			name:  "Without encryption",
+			input: &PutInput{Key: "key", Body: body, ContentType: "text/plain", Metadata: map[string]string{"m": "v"}, StorageClass: "GLACIER"},
+			want: &s3manager.UploadInput{
+				Bucket:       aws.String("bucket"),
+				Key:          aws.String("key"),
+				Body:         body,
+				ContentType:  aws.String("text/plain"),
+				Metadata:     map[string]*string{"m": aws.String("v")},
+				StorageClass: aws.String("GLACIER"),
+			},
+		},
+		{
+			name:  "With tags",
+			input: &PutInput{Key: "key", Body: body, Tags: map[string]string{"user": "john doe", "a": "b&c"}},
+			want: &s3manager.UploadInput{
+				Bucket:  aws.String("bucket"),
+				Key:     aws.String("key"),
+				Body:    body,
+				Tagging: aws.String("a=b%26c&user=john+doe"),
+			},
+		},
+		{
+			name:       "With KMS encryption",
+			encryption: &config.BucketEncryptionConfig{ServerSideEncryption: "aws:kms", KMSKeyID: "key-id"},
+			input:      &PutInput{Key: "key", Body: body},
+			want: &s3manager.UploadInput{
+				Bucket:               aws.String("bucket"),
+				Key:                  aws.String("key"),
+				Body:                 body,
+				ServerSideEncryption: aws.String("aws:kms"),
+				SSEKMSKeyId:          aws.String("key-id"),
+			},
+		},
+		{
+			name: "With customer key",
+			encryption: &config.BucketEncryptionConfig{
+				CustomerKey: &config.CredentialConfig{Value: "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="},
+			},
+			input: &PutInput{Key: "key", Body: body},
+			want: &s3manager.UploadInput{
+				Bucket:               aws.String("bucket"),
+				Key:                  aws.String("key"),
+				Body:                 body,
+				SSECustomerAlgorithm: aws.String("AES256"),
+				SSECustomerKey:       aws.String("01234567890123456789012345678901"),
+			},
+		},
+		{
+			name: "With invalid customer key",
+			encryption: &config.BucketEncryptionConfig{
+				CustomerKey: &config.CredentialConfig{Value: "invalid"},
+			},
+			input:   &PutInput{Key: "key", Body: body},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3ctx := &s3Context{
+				target: &config.TargetConfig{
+					Bucket: &config.BucketConfig{Name: "bucket", Encryption: tt.encryption},
+				},
+			}
+			got, err := s3ctx.buildUploadInput(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("s3Context.buildUploadInput() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("s3Context.buildUploadInput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// s3ClientTest is a fake S3 client answering get object requests with a not modified error when ETag matches
+type s3ClientTest struct {
+	s3iface.S3API
+	etag       string
+	inputs     []*s3.GetObjectInput
+	headInputs []*s3.HeadObjectInput
+	// pages are answered to list objects requests
+	pages []*s3.ListObjectsV2Output
+	// versionPages are answered to list object versions requests
+	versionPages  []*s3.ListObjectVersionsOutput
+	deletedInputs []*s3.DeleteObjectsInput
+}
+
+func (c *s3ClientTest) ListObjectVersionsPagesWithContext(ctx aws.Context, input *s3.ListObjectVersionsInput, fn func(*s3.ListObjectVersionsOutput, bool) bool, opts ...request.Option) error {
+	for i, page := range c.versionPages {
+		// Run request handlers given in options
+		r := &request.Request{}
+		r.ApplyOptions(opts...)
+		r.Handlers.Complete.Run(r)
+
+		if !fn(page, i == len(c.versionPages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (c *s3ClientTest) ListObjectsV2PagesWithContext(ctx aws.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, opts ...request.Option) error {
+	for i, page := range c.pages {
+		// Run request handlers given in options
+		r := &request.Request{}
+		r.ApplyOptions(opts...)
+		r.Handlers.Complete.Run(r)
+
+		if !fn(page, i == len(c.pages)-1) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (c *s3ClientTest) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	c.deletedInputs = append(c.deletedInputs, input)
+
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func (c *s3ClientTest) HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	c.headInputs = append(c.headInputs, input)
+	if input.IfNoneMatch != nil && *input.IfNoneMatch == c.etag {
+		return nil, awserr.NewRequestFailure(awserr.New("NotModified", "Not Modified", nil), http.StatusNotModified, "id")
+	}
+
+	return &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(7),
+		ContentType:   aws.String("text/plain"),
+		ETag:          aws.String(c.etag),
+	}, nil
+}
+
+func (c *s3ClientTest) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	c.inputs = append(c.inputs, input)
+	if input.IfNoneMatch != nil && *input.IfNoneMatch == c.etag {
+		return nil, awserr.NewRequestFailure(awserr.New("NotModified", "Not Modified", nil), http.StatusNotModified, "id")
+	}
+
+	return &s3.GetObjectOutput{
+		Body:          ioutil.NopCloser(strings.NewReader("content")),
+		ContentLength: aws.Int64(7),
+		ETag:          aws.String(c.etag),
+	}, nil
+}
+
+func Test_s3Context_getObjectWithCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3-proxy-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tgt := &config.TargetConfig{Name: "target", Bucket: &config.BucketConfig{Name: "bucket"}}
+	cache, err := newObjectCache(tgt, &config.ObjectCacheConfig{Enabled: true, Directory: dir, MaxSize: 10, MaxObjectSize: 10, TTL: time.Hour})
+	assert.NoError(t, err)
+
+	svcClient := &s3ClientTest{etag: "\"etag\""}
+	s3ctx := &s3Context{
+		svcClient:   svcClient,
+		target:      tgt,
+		logger:      log.NewLogger(),
+		metricsCtx:  &metricsClientTest{},
+		objectCache: cache,
+	}
+
+	// Request conditions are sent to S3 on a miss
+	_, err = s3ctx.getObjectWithCache(&GetInput{Key: "key", IfNoneMatch: "\"etag\""})
+	assert.Equal(t, ErrNotModified, err)
+	assert.Len(t, svcClient.inputs, 1)
+	assert.Equal(t, aws.String("\"etag\""), svcClient.inputs[0].IfNoneMatch)
+	assert.Nil(t, cache.get("key"))
+
+	// Object is downloaded and stored in cache
+	output, err := s3ctx.getObjectWithCache(&GetInput{Key: "key"})
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(*output.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, (*output.Body).Close())
+	assert.Equal(t, "content", string(b))
+	assert.Len(t, svcClient.inputs, 2)
+	assert.Nil(t, svcClient.inputs[1].IfNoneMatch)
+
+	// Request conditions are checked on cached object
+	_, err = s3ctx.getObjectWithCache(&GetInput{Key: "key", IfNoneMatch: "\"etag\""})
+	assert.Equal(t, ErrNotModified, err)
+	assert.Len(t, svcClient.inputs, 2)
+}
+
+func Test_s3Context_headObjectForGet(t *testing.T) {
+	svcClient := &s3ClientTest{etag: "\"etag\""}
+	s3ctx := &s3Context{
+		svcClient:  svcClient,
+		target:     &config.TargetConfig{Name: "target", Bucket: &config.BucketConfig{Name: "bucket"}},
+		logger:     log.NewLogger(),
+		metricsCtx: &metricsClientTest{},
+	}
+
+	// Version and conditions are sent to S3 and range is ignored
+	output, err := s3ctx.headObjectForGet(&GetInput{Key: "key", VersionID: "version1", Range: "bytes=0-1"}, "")
+	assert.NoError(t, err)
+	body := io.ReadCloser(http.NoBody)
+	assert.Equal(t, &GetOutput{
+		Body:          &body,
+		ContentLength: 7,
+		ContentType:   "text/plain",
+		ETag:          "\"etag\"",
+	}, output)
+	assert.Len(t, svcClient.headInputs, 1)
+	assert.Equal(t, aws.String("bucket"), svcClient.headInputs[0].Bucket)
+	assert.Equal(t, aws.String("key"), svcClient.headInputs[0].Key)
+	assert.Equal(t, aws.String("version1"), svcClient.headInputs[0].VersionId)
+	assert.Empty(t, svcClient.inputs)
+
+	// Not modified answers are detected from status code
+	_, err = s3ctx.headObjectForGet(&GetInput{Key: "key", IfNoneMatch: "\"etag\""}, "")
+	assert.Equal(t, ErrNotModified, err)
+	assert.Equal(t, aws.String("\"etag\""), svcClient.headInputs[1].IfNoneMatch)
+}
+
+func Test_s3Context_DeleteFolder(t *testing.T) {
+	svcClient := &s3ClientTest{pages: []*s3.ListObjectsV2Output{
+		{Contents: []*s3.Object{{Key: aws.String("dir/file1")}, {Key: aws.String("dir/file2")}}},
+		{Contents: []*s3.Object{{Key: aws.String("dir/file3")}}},
+	}}
+	metricsCtx := &metricsClientTest{}
+	s3ctx := &s3Context{
+		svcClient:   svcClient,
+		target:      &config.TargetConfig{Name: "target", Bucket: &config.BucketConfig{Name: "bucket"}},
+		logger:      log.NewLogger(),
+		metricsCtx:  metricsCtx,
+		parentTrace: tracing.StartTrace("test"),
+	}
+
+	output, err := s3ctx.DeleteFolder(&DeleteFolderInput{Prefix: "dir/"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dir/file1", "dir/file2", "dir/file3"}, output.DeletedKeys)
+	assert.Len(t, svcClient.deletedInputs, 2)
+	// List objects requests are counted for each page
+	assert.Equal(t, []string{ListObjectsOperation, DeleteObjectsOperation, ListObjectsOperation, DeleteObjectsOperation}, metricsCtx.operations)
+}
+
+func Test_s3Context_ListAllObjects(t *testing.T) {
+	svcClient := &s3ClientTest{pages: []*s3.ListObjectsV2Output{
+		{Contents: []*s3.Object{{Key: aws.String("dir/")}, {Key: aws.String("dir/file1"), Size: aws.Int64(1)}}},
+		{Contents: []*s3.Object{{Key: aws.String("dir/file2"), Size: aws.Int64(2)}}},
+	}}
+	metricsCtx := &metricsClientTest{}
+	s3ctx := &s3Context{
+		svcClient:   svcClient,
+		target:      &config.TargetConfig{Name: "target", Bucket: &config.BucketConfig{Name: "bucket"}},
+		logger:      log.NewLogger(),
+		metricsCtx:  metricsCtx,
+		parentTrace: tracing.StartTrace("test"),
+	}
+
+	output, err := s3ctx.ListAllObjects(&ListAllObjectsInput{Prefix: "dir/", MaxObjects: 10})
+	assert.NoError(t, err)
+	assert.Len(t, output.Entries, 2)
+	assert.False(t, output.Truncated)
+	// List objects requests are counted for each page
+	assert.Equal(t, []string{ListObjectsOperation, ListObjectsOperation}, metricsCtx.operations)
+}
+
+func Test_s3Context_ListObjectVersions(t *testing.T) {
+	now := time.Now()
+	svcClient := &s3ClientTest{versionPages: []*s3.ListObjectVersionsOutput{
+		{Versions: []*s3.ObjectVersion{{Key: aws.String("file"), VersionId: aws.String("v1"), LastModified: aws.Time(now.Add(-time.Hour))}}},
+		{DeleteMarkers: []*s3.DeleteMarkerEntry{{Key: aws.String("file"), VersionId: aws.String("v2"), LastModified: aws.Time(now), IsLatest: aws.Bool(true)}}},
+	}}
+	metricsCtx := &metricsClientTest{}
+	s3ctx := &s3Context{
+		svcClient:   svcClient,
+		target:      &config.TargetConfig{Name: "target", Bucket: &config.BucketConfig{Name: "bucket"}},
+		logger:      log.NewLogger(),
+		metricsCtx:  metricsCtx,
+		parentTrace: tracing.StartTrace("test"),
+	}
+
+	output, err := s3ctx.ListObjectVersions("file")
+	assert.NoError(t, err)
+	assert.Len(t, output, 2)
+	assert.Equal(t, "v2", output[0].VersionID)
+	assert.True(t, output[0].DeleteMarker)
+	assert.Equal(t, "v1", output[1].VersionID)
+	// List object versions requests are counted for each page
+	assert.Equal(t, []string{ListObjectVersionsOperation, ListObjectVersionsOperation}, metricsCtx.operations)
+}
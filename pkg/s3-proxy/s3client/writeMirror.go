@@ -0,0 +1,407 @@
+package s3client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/tracing"
+)
+
+// mirrorWriteFailedCode Error code of folder deletion errors on mirror buckets
+const mirrorWriteFailedCode = "MirrorWriteFailed"
+
+// errMirrorSpoolFull is raised when an uploaded body cannot be stored for mirror buckets because spool is full
+var errMirrorSpoolFull = errors.New("uploaded body cannot be stored for mirror buckets because write mirror spool is full")
+
+// mirrorBucket is a bucket receiving target bucket writes
+type mirrorBucket struct {
+	// newClient will create bucket client with logger and trace used for writes
+	newClient  func(logger log.Logger, parentTrace tracing.Trace) Client
+	bucketName string
+}
+
+// mirrorBucketError is a write error on a mirror bucket
+type mirrorBucketError struct {
+	bucketName string
+	err        error
+}
+
+// MirrorWriteError is raised when a write succeeded on target bucket but failed on mirror buckets
+// Writes aren't rolled back: target bucket and other mirror buckets keep the change.
+type MirrorWriteError struct {
+	operation string
+	key       string
+	failed    []*mirrorBucketError
+}
+
+func (e *MirrorWriteError) Error() string {
+	msgs := make([]string, 0, len(e.failed))
+	// Loop over failed buckets
+	for _, item := range e.failed {
+		msgs = append(msgs, fmt.Sprintf("bucket %s: %v", item.bucketName, item.err))
+	}
+
+	return fmt.Sprintf("%s of key %s succeeded on target bucket but failed on mirror buckets (%s)", e.operation, e.key, strings.Join(msgs, ", "))
+}
+
+// mirrorQueue is a bounded queue of asynchronous mirror writes shared between requests of a target
+// Writes are done by workers with a logger that isn't linked to requests. Pending writes are drained when queue is closed.
+type mirrorQueue struct {
+	jobs   chan func()
+	logger log.Logger
+	wg     sync.WaitGroup
+	mutex  sync.RWMutex
+	closed bool
+}
+
+func newMirrorQueue(size, workers int, logger log.Logger) *mirrorQueue {
+	q := &mirrorQueue{jobs: make(chan func(), size), logger: logger}
+	// Start workers
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+
+		go func() {
+			defer q.wg.Done()
+			// Loop until queue is closed and drained
+			for job := range q.jobs {
+				job()
+			}
+		}()
+	}
+
+	return q
+}
+
+// push will add a write in queue. It returns false when queue is full or closed.
+func (q *mirrorQueue) push(job func()) bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// close will stop accepting writes and will wait for pending ones
+func (q *mirrorQueue) close() {
+	q.mutex.Lock()
+	// Check if queue is already closed
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mutex.Unlock()
+
+	q.wg.Wait()
+}
+
+// mirrorSpool stores uploaded bodies in temporary files to send them again to mirror buckets
+// Total size of stored bodies is limited and shared between requests of a target.
+type mirrorSpool struct {
+	directory string
+	maxSize   int64
+	mutex     sync.Mutex
+	size      int64
+}
+
+func newMirrorSpool(cfg *config.WriteMirrorConfig) (*mirrorSpool, error) {
+	// Check if a directory is configured (system temporary directory is used otherwise)
+	if cfg.SpoolDirectory != "" {
+		err := os.MkdirAll(cfg.SpoolDirectory, 0750)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &mirrorSpool{directory: cfg.SpoolDirectory, maxSize: cfg.GetMaxSpoolSize()}, nil
+}
+
+// reserve will reserve size in spool. It returns false when spool is full.
+func (s *mirrorSpool) reserve(size int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.size+size > s.maxSize {
+		return false
+	}
+
+	s.size += size
+
+	return true
+}
+
+func (s *mirrorSpool) release(size int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.size -= size
+}
+
+// create will create a spool file to store an uploaded body
+func (s *mirrorSpool) create() (*spoolFile, error) {
+	f, err := ioutil.TempFile(s.directory, "s3-proxy-mirror-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &spoolFile{spool: s, file: f}, nil
+}
+
+// spoolFile is a temporary file storing an uploaded body
+type spoolFile struct {
+	spool *mirrorSpool
+	file  *os.File
+	size  int64
+	// err is the error raised while body was stored
+	err error
+}
+
+// Write will store body until spool is full
+// It never fails to not break target bucket upload: errors are given when stored body is opened.
+func (f *spoolFile) Write(b []byte) (int, error) {
+	// Check if body cannot be stored anymore
+	if f.err != nil {
+		return len(b), nil
+	}
+
+	if !f.spool.reserve(int64(len(b))) {
+		f.err = errMirrorSpoolFull
+
+		return len(b), nil
+	}
+
+	f.size += int64(len(b))
+
+	_, err := f.file.Write(b)
+	if err != nil {
+		f.err = err
+	}
+
+	return len(b), nil
+}
+
+// open will open stored body
+func (f *spoolFile) open() (io.ReadCloser, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return os.Open(f.file.Name())
+}
+
+// remove will remove temporary file and release its size in spool
+func (f *spoolFile) remove() {
+	f.file.Close()
+	os.Remove(f.file.Name())
+	f.spool.release(f.size)
+}
+
+// mirrorClient will write objects on target bucket and then on mirror buckets following write mirror policy.
+// Other operations are done on target bucket client.
+type mirrorClient struct {
+	// Client is the target bucket client
+	Client
+	mirrors    []*mirrorBucket
+	policy     string
+	retries    int
+	retryDelay time.Duration
+	targetName string
+	metricsCtx metrics.Client
+	// logger and parentTrace are request ones
+	logger      log.Logger
+	parentTrace tracing.Trace
+	// queue is used for asynchronous writes (with async policy only)
+	queue *mirrorQueue
+	spool *mirrorSpool
+}
+
+// write will call write function on all mirror buckets in parallel
+// With all policy, it waits for all writes and returns failures. With async policy, writes are pushed in queue
+// and failures are only logged. Cleanup function is called when all writes are done.
+func (mc *mirrorClient) write(operation, key string, fn func(cli Client) error, cleanup func()) []*mirrorBucketError {
+	// Check if writes must be done in background
+	if mc.policy == config.WriteMirrorPolicyAsync {
+		ok := mc.queue.push(func() {
+			// Request is answered so writes have their own trace
+			trace := tracing.StartTrace("s3-proxy.write-mirror")
+			defer trace.Finish()
+
+			mc.run(mc.queue.logger, trace, operation, key, fn, cleanup)
+		})
+		// Check if queue is full or closed (during a reload or a shutdown)
+		if !ok {
+			mc.logger.Warnf("write mirror: queue of target %s is full or closed, %s of key %s is done before answering", mc.targetName, operation, key)
+			mc.run(mc.logger, mc.parentTrace, operation, key, fn, cleanup)
+		}
+
+		return nil
+	}
+
+	return mc.run(mc.logger, mc.parentTrace, operation, key, fn, cleanup)
+}
+
+// run will call write function on all mirror buckets in parallel and will wait for them
+func (mc *mirrorClient) run(logger log.Logger, parentTrace tracing.Trace, operation, key string, fn func(cli Client) error, cleanup func()) []*mirrorBucketError {
+	var wg sync.WaitGroup
+
+	errs := make([]error, len(mc.mirrors))
+	// Loop over mirror buckets
+	for i, mirror := range mc.mirrors {
+		wg.Add(1)
+
+		go func(i int, mirror *mirrorBucket) {
+			defer wg.Done()
+
+			errs[i] = mc.writeWithRetries(mirror.newClient(logger, parentTrace), fn)
+		}(i, mirror)
+	}
+
+	wg.Wait()
+	cleanup()
+
+	failed := make([]*mirrorBucketError, 0)
+	// Loop over results
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		bucketName := mc.mirrors[i].bucketName
+		// Log failure with all information needed to reconcile bucket
+		logger.WithFields(map[string]interface{}{
+			"target":    mc.targetName,
+			"bucket":    bucketName,
+			"operation": operation,
+			"key":       key,
+			"policy":    mc.policy,
+		}).Errorf("write mirror: %s of key %s on bucket %s failed, bucket must be reconciled: %v", operation, key, bucketName, err)
+		// Metrics
+		mc.metricsCtx.IncWriteMirrorFailures(mc.targetName, bucketName, operation)
+
+		failed = append(failed, &mirrorBucketError{bucketName: bucketName, err: err})
+	}
+
+	return failed
+}
+
+// writeWithRetries will call write function on a mirror bucket and will retry it on bucket failures
+func (mc *mirrorClient) writeWithRetries(cli Client, fn func(cli Client) error) error {
+	err := fn(cli)
+	// Loop over retries
+	for i := 0; i < mc.retries && isBucketFailure(err); i++ {
+		time.Sleep(mc.retryDelay)
+
+		err = fn(cli)
+	}
+
+	return err
+}
+
+func (mc *mirrorClient) PutObject(input *PutInput) error {
+	// Store body in spool while it is uploaded on target bucket to upload it again on mirror buckets
+	f, err := mc.spool.create()
+	if err != nil {
+		return err
+	}
+
+	targetInput := *input
+	targetInput.Body = io.TeeReader(input.Body, f)
+	// Put object in target bucket
+	err = mc.Client.PutObject(&targetInput)
+	if err != nil {
+		f.remove()
+
+		return err
+	}
+	// Put object in mirror buckets
+	failed := mc.write(PutObjectOperation, input.Key, func(cli Client) error {
+		body, err := f.open()
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		mirrorInput := *input
+		mirrorInput.Body = body
+
+		return cli.PutObject(&mirrorInput)
+	}, f.remove)
+	// Check if some mirror writes failed
+	if len(failed) != 0 {
+		return &MirrorWriteError{operation: PutObjectOperation, key: input.Key, failed: failed}
+	}
+
+	return nil
+}
+
+func (mc *mirrorClient) DeleteObject(input *DeleteInput) error {
+	// Delete object in target bucket
+	err := mc.Client.DeleteObject(input)
+	if err != nil {
+		return err
+	}
+	// Versions are specific to each bucket so they are only deleted in target bucket
+	if input.VersionID != "" {
+		return nil
+	}
+	// Delete object in mirror buckets
+	failed := mc.write(DeleteObjectOperation, input.Key, func(cli Client) error {
+		return cli.DeleteObject(&DeleteInput{Key: input.Key})
+	}, func() {})
+	// Check if some mirror writes failed
+	if len(failed) != 0 {
+		return &MirrorWriteError{operation: DeleteObjectOperation, key: input.Key, failed: failed}
+	}
+
+	return nil
+}
+
+func (mc *mirrorClient) DeleteFolder(input *DeleteFolderInput) (*DeleteFolderOutput, error) {
+	// Delete folder in target bucket
+	output, err := mc.Client.DeleteFolder(input)
+	// Check if error exists or if it is a dry run
+	if err != nil || input.DryRun {
+		return output, err
+	}
+	// Delete folder in mirror buckets
+	failed := mc.write(DeleteObjectsOperation, input.Prefix, func(cli Client) error {
+		mirrorOutput, err := cli.DeleteFolder(input)
+		if err != nil {
+			return err
+		}
+		// Check if some objects cannot be deleted
+		if len(mirrorOutput.Errors) != 0 {
+			item := mirrorOutput.Errors[0]
+
+			return fmt.Errorf("%d objects cannot be deleted (first one: %s: %s (%s))", len(mirrorOutput.Errors), item.Key, item.Message, item.Code)
+		}
+
+		return nil
+	}, func() {})
+	// Add mirror failures to folder deletion errors
+	for _, item := range failed {
+		output.Errors = append(output.Errors, &DeleteErrorOutput{
+			Key:     input.Prefix,
+			Code:    mirrorWriteFailedCode,
+			Message: fmt.Sprintf("bucket %s: %v", item.bucketName, item.err),
+		})
+	}
+
+	return output, nil
+}
@@ -0,0 +1,324 @@
+// +build unit
+
+package s3client
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeMirrorClientTest is a fake bucket client keeping written objects
+type writeMirrorClientTest struct {
+	Client
+	err error
+	// failures is the number of writes failing with error (all writes fail when it is 0)
+	failures int
+	calls    int
+	mutex    sync.Mutex
+	objects  map[string]string
+	deleted  []string
+}
+
+// newMirrorBucketTest will create a mirror bucket with a fake client
+func newMirrorBucketTest(cli Client, bucketName string) *mirrorBucket {
+	return &mirrorBucket{
+		newClient:  func(logger log.Logger, parentTrace tracing.Trace) Client { return cli },
+		bucketName: bucketName,
+	}
+}
+
+// hasError will count write and will check if it must fail
+func (c *writeMirrorClientTest) hasError() bool {
+	c.calls++
+
+	return c.err != nil && (c.failures == 0 || c.calls <= c.failures)
+}
+
+func newWriteMirrorClientTest(err error) *writeMirrorClientTest {
+	return &writeMirrorClientTest{err: err, objects: map[string]string{}}
+}
+
+func (c *writeMirrorClientTest) PutObject(input *PutInput) error {
+	b, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.hasError() {
+		return c.err
+	}
+
+	c.objects[input.Key] = string(b)
+
+	return nil
+}
+
+func (c *writeMirrorClientTest) DeleteObject(input *DeleteInput) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.hasError() {
+		return c.err
+	}
+
+	c.deleted = append(c.deleted, input.Key+input.VersionID)
+
+	return nil
+}
+
+func (c *writeMirrorClientTest) DeleteFolder(input *DeleteFolderInput) (*DeleteFolderOutput, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.hasError() {
+		return nil, c.err
+	}
+
+	c.deleted = append(c.deleted, input.Prefix)
+
+	return &DeleteFolderOutput{DeletedKeys: []string{input.Prefix + "file"}, Errors: []*DeleteErrorOutput{}}, nil
+}
+
+func (c *writeMirrorClientTest) getObject(key string) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.objects[key]
+}
+
+func Test_mirrorClient(t *testing.T) {
+	fakeErr := errors.New("fake")
+	tests := []struct {
+		name         string
+		policy       string
+		targetErr    error
+		mirror2Err   error
+		wantErr      string
+		wantFailures []string
+	}{
+		{
+			name:   "should write on all buckets",
+			policy: config.WriteMirrorPolicyAll,
+		},
+		{
+			name:      "should not write on mirror buckets when target bucket failed",
+			policy:    config.WriteMirrorPolicyAll,
+			targetErr: fakeErr,
+			wantErr:   "fake",
+		},
+		{
+			name:         "should report mirror bucket failures with all policy",
+			policy:       config.WriteMirrorPolicyAll,
+			mirror2Err:   fakeErr,
+			wantErr:      "put-object of key dir/key succeeded on target bucket but failed on mirror buckets (bucket mirror2: fake)",
+			wantFailures: []string{"mirror2"},
+		},
+		{
+			name:         "should only log mirror bucket failures with async policy",
+			policy:       config.WriteMirrorPolicyAsync,
+			mirror2Err:   fakeErr,
+			wantFailures: []string{"mirror2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newWriteMirrorClientTest(tt.targetErr)
+			mirror1 := newWriteMirrorClientTest(nil)
+			mirror2 := newWriteMirrorClientTest(tt.mirror2Err)
+			metricsCtx := &metricsClientTest{}
+			mc := &mirrorClient{
+				Client: target,
+				mirrors: []*mirrorBucket{
+					newMirrorBucketTest(mirror1, "mirror1"),
+					newMirrorBucketTest(mirror2, "mirror2"),
+				},
+				policy:     tt.policy,
+				targetName: "target",
+				metricsCtx: metricsCtx,
+				logger:     log.NewLogger(),
+				queue:      newMirrorQueue(10, 1, log.NewLogger()),
+				spool:      &mirrorSpool{maxSize: 100},
+			}
+
+			err := mc.PutObject(&PutInput{Key: "dir/key", Body: strings.NewReader("content")})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			// Check mirror buckets content
+			if tt.targetErr != nil {
+				assert.Equal(t, "", mirror1.getObject("dir/key"))
+				assert.Nil(t, metricsCtx.getFailures())
+				return
+			}
+
+			assert.Equal(t, "content", target.getObject("dir/key"))
+			assert.Eventually(t, func() bool { return mirror1.getObject("dir/key") == "content" }, time.Second, 10*time.Millisecond)
+			if tt.policy == config.WriteMirrorPolicyAsync {
+				// Wait for background writes
+				assert.Eventually(t, func() bool { return len(metricsCtx.getFailures()) == len(tt.wantFailures) }, time.Second, 10*time.Millisecond)
+			}
+			assert.Equal(t, tt.wantFailures, metricsCtx.getFailures())
+		})
+	}
+}
+
+func Test_mirrorClient_Delete(t *testing.T) {
+	target := newWriteMirrorClientTest(nil)
+	mirror1 := newWriteMirrorClientTest(nil)
+	mirror2 := newWriteMirrorClientTest(errors.New("fake"))
+	mc := &mirrorClient{
+		Client: target,
+		mirrors: []*mirrorBucket{
+			newMirrorBucketTest(mirror1, "mirror1"),
+			newMirrorBucketTest(mirror2, "mirror2"),
+		},
+		policy:     config.WriteMirrorPolicyAll,
+		targetName: "target",
+		metricsCtx: &metricsClientTest{},
+		logger:     log.NewLogger(),
+	}
+
+	// Versions are only deleted in target bucket
+	err := mc.DeleteObject(&DeleteInput{Key: "key", VersionID: "version1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"keyversion1"}, target.deleted)
+	assert.Nil(t, mirror1.deleted)
+
+	// Objects are deleted in all buckets
+	err = mc.DeleteObject(&DeleteInput{Key: "key"})
+	assert.EqualError(t, err, "delete-object of key key succeeded on target bucket but failed on mirror buckets (bucket mirror2: fake)")
+	assert.Equal(t, []string{"keyversion1", "key"}, target.deleted)
+	assert.Equal(t, []string{"key"}, mirror1.deleted)
+
+	// Folder dry runs are only done in target bucket
+	_, err = mc.DeleteFolder(&DeleteFolderInput{Prefix: "dry/", DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key"}, mirror1.deleted)
+
+	// Mirror bucket failures are added to folder deletion errors
+	output, err := mc.DeleteFolder(&DeleteFolderInput{Prefix: "dir/"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key", "dir/"}, mirror1.deleted)
+	assert.Equal(t, []string{"dir/file"}, output.DeletedKeys)
+	assert.Equal(t, []*DeleteErrorOutput{{Key: "dir/", Code: "MirrorWriteFailed", Message: "bucket mirror2: fake"}}, output.Errors)
+}
+
+func Test_mirrorClient_Retries(t *testing.T) {
+	serverErr := awserr.NewRequestFailure(awserr.New("InternalError", "fake", nil), 500, "id")
+	target := newWriteMirrorClientTest(nil)
+	mirror := newWriteMirrorClientTest(serverErr)
+	mirror.failures = 2
+	metricsCtx := &metricsClientTest{}
+	mc := &mirrorClient{
+		Client:     target,
+		mirrors:    []*mirrorBucket{newMirrorBucketTest(mirror, "mirror")},
+		policy:     config.WriteMirrorPolicyAll,
+		retries:    2,
+		retryDelay: time.Millisecond,
+		targetName: "target",
+		metricsCtx: metricsCtx,
+		logger:     log.NewLogger(),
+		spool:      &mirrorSpool{maxSize: 100},
+	}
+
+	// Bucket failures are retried
+	err := mc.PutObject(&PutInput{Key: "key", Body: strings.NewReader("content")})
+	assert.NoError(t, err)
+	assert.Equal(t, "content", mirror.getObject("key"))
+	assert.Equal(t, 3, mirror.calls)
+	assert.Nil(t, metricsCtx.getFailures())
+
+	// Other errors aren't retried
+	mirror.err = errors.New("fake")
+	mirror.calls = 0
+	mirror.failures = 0
+	err = mc.DeleteObject(&DeleteInput{Key: "key"})
+	assert.EqualError(t, err, "delete-object of key key succeeded on target bucket but failed on mirror buckets (bucket mirror: fake)")
+	assert.Equal(t, 1, mirror.calls)
+}
+
+func Test_mirrorClient_SpoolFull(t *testing.T) {
+	target := newWriteMirrorClientTest(nil)
+	mirror := newWriteMirrorClientTest(nil)
+	spool := &mirrorSpool{maxSize: 10}
+	mc := &mirrorClient{
+		Client:     target,
+		mirrors:    []*mirrorBucket{newMirrorBucketTest(mirror, "mirror")},
+		policy:     config.WriteMirrorPolicyAll,
+		targetName: "target",
+		metricsCtx: &metricsClientTest{},
+		logger:     log.NewLogger(),
+		spool:      spool,
+	}
+
+	// Target bucket upload isn't stopped when body cannot be stored for mirror buckets
+	err := mc.PutObject(&PutInput{Key: "key", Body: strings.NewReader("content bigger than spool")})
+	assert.EqualError(t, err, "put-object of key key succeeded on target bucket but failed on mirror buckets (bucket mirror: "+errMirrorSpoolFull.Error()+")")
+	assert.Equal(t, "content bigger than spool", target.getObject("key"))
+	assert.Equal(t, "", mirror.getObject("key"))
+	// Spool size is released
+	assert.Equal(t, int64(0), spool.size)
+
+	err = mc.PutObject(&PutInput{Key: "key2", Body: strings.NewReader("content")})
+	assert.NoError(t, err)
+	assert.Equal(t, "content", mirror.getObject("key2"))
+	assert.Equal(t, int64(0), spool.size)
+}
+
+func Test_mirrorQueue(t *testing.T) {
+	q := newMirrorQueue(1, 1, log.NewLogger())
+
+	// Block worker to fill queue
+	release := make(chan struct{})
+	assert.True(t, q.push(func() { <-release }))
+	assert.Eventually(t, func() bool { return len(q.jobs) == 0 }, time.Second, time.Millisecond)
+
+	done := false
+	assert.True(t, q.push(func() { done = true }))
+	// Queue is full
+	assert.False(t, q.push(func() {}))
+
+	// Pending writes are drained when queue is closed
+	close(release)
+	q.close()
+	assert.True(t, done)
+	// Closed queue doesn't accept writes
+	assert.False(t, q.push(func() {}))
+}
+
+func Test_mirrorClient_QueueClosed(t *testing.T) {
+	target := newWriteMirrorClientTest(nil)
+	mirror := newWriteMirrorClientTest(nil)
+	queue := newMirrorQueue(10, 1, log.NewLogger())
+	queue.close()
+	mc := &mirrorClient{
+		Client:     target,
+		mirrors:    []*mirrorBucket{newMirrorBucketTest(mirror, "mirror")},
+		policy:     config.WriteMirrorPolicyAsync,
+		targetName: "target",
+		metricsCtx: &metricsClientTest{},
+		logger:     log.NewLogger(),
+		queue:      queue,
+		spool:      &mirrorSpool{maxSize: 100},
+	}
+
+	// Writes are done before answering when queue is closed
+	err := mc.PutObject(&PutInput{Key: "key", Body: strings.NewReader("content")})
+	assert.NoError(t, err)
+	assert.Equal(t, "content", mirror.getObject("key"))
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
@@ -31,10 +32,19 @@ func NewInternalServer(logger log.Logger, cfgManager config.Manager, metricsCl m
 func (svr *InternalServer) Listen() error {
 	svr.logger.Infof("Internal server listening on %s", svr.server.Addr)
 	err := svr.server.ListenAndServe()
+	// Server closed by shutdown isn't an error
+	if err == http.ErrServerClosed {
+		return nil
+	}
 
 	return err
 }
 
+// Shutdown will stop server gracefully
+func (svr *InternalServer) Shutdown(ctx context.Context) error {
+	return svr.server.Shutdown(ctx)
+}
+
 func (svr *InternalServer) GenerateServer() {
 	// Get configuration
 	cfg := svr.cfgManager.GetConfig()
@@ -1,11 +1,12 @@
 package middlewares
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/bucket"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
-	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/server/utils"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/tracing"
 	"golang.org/x/net/context"
@@ -23,7 +24,7 @@ var bucketRequestContextKey = &contextKey{name: "bucket-request-context"}
 // nolint:whitespace
 func BucketRequestContext(
 	tgt *config.TargetConfig, tplConfig *config.TemplateConfig,
-	path string, metricsCli metrics.Client,
+	path string, s3clientManager s3client.Manager,
 ) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -32,22 +33,27 @@ func BucketRequestContext(
 			// Get request URI
 			requestURI := req.URL.RequestURI()
 			errorhandlers := &bucket.ErrorHandlers{
-				HandleForbiddenWithTemplate:           utils.HandleForbiddenWithTemplate,
-				HandleNotFoundWithTemplate:            utils.HandleNotFoundWithTemplate,
-				HandleInternalServerErrorWithTemplate: utils.HandleInternalServerErrorWithTemplate,
-				HandleBadRequestWithTemplate:          utils.HandleBadRequestWithTemplate,
-				HandleUnauthorizedWithTemplate:        utils.HandleUnauthorizedWithTemplate,
+				HandleForbiddenWithTemplate:             utils.HandleForbiddenWithTemplate,
+				HandleNotFoundWithTemplate:              utils.HandleNotFoundWithTemplate,
+				HandleInternalServerErrorWithTemplate:   utils.HandleInternalServerErrorWithTemplate,
+				HandleBadRequestWithTemplate:            utils.HandleBadRequestWithTemplate,
+				HandleRequestEntityTooLargeWithTemplate: utils.HandleRequestEntityTooLargeWithTemplate,
+				HandleBadGatewayWithTemplate:            utils.HandleBadGatewayWithTemplate,
+				HandleUnauthorizedWithTemplate:          utils.HandleUnauthorizedWithTemplate,
 			}
 			// Get request trace
 			trace := tracing.GetTraceFromRequest(req)
-			// Generate new bucket client
-			brctx, err := bucket.NewClient(tgt, tplConfig, logEntry, path, rw, metricsCli, errorhandlers, trace)
-			if err != nil {
+			// Get S3 client for target with request logger and trace
+			s3ctx := s3clientManager.GetClientForTarget(tgt, logEntry, trace)
+			if s3ctx == nil {
+				err := fmt.Errorf("s3 client for target %s not found", tgt.Name)
 				logEntry.Error(err)
 				utils.HandleInternalServerError(logEntry, rw, tplConfig, requestURI, err)
 				// Stop
 				return
 			}
+			// Generate new bucket client
+			brctx := bucket.NewClient(tgt, tplConfig, logEntry, path, rw, s3ctx, errorhandlers)
 			// Add bucket structure to request context by creating a new context
 			ctx := context.WithValue(req.Context(), bucketRequestContextKey, brctx)
 			// Create new request with new context
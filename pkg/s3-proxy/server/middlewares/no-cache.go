@@ -0,0 +1,78 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+var noCacheRequestHeadersKey = &contextKey{name: "no-cache-request-headers"}
+
+// Unix epoch time
+var epoch = time.Unix(0, 0).Format(time.RFC1123)
+
+// Headers set on responses to prevent caching (same as chi NoCache middleware)
+var noCacheHeaders = map[string]string{
+	"Expires":         epoch,
+	"Cache-Control":   "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+	"Pragma":          "no-cache",
+	"X-Accel-Expires": "0",
+}
+
+// Headers removed from requests to prevent caching (same as chi NoCache middleware)
+var etagHeaders = []string{
+	"ETag",
+	"If-Modified-Since",
+	"If-Match",
+	"If-None-Match",
+	"If-Range",
+	"If-Unmodified-Since",
+}
+
+// NoCache will set headers to prevent responses from being cached like chi NoCache middleware.
+// Removed request headers are kept in request context to be used by object requests.
+func NoCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// Delete any ETag headers that may have been set and keep them
+		removedHeaders := http.Header{}
+
+		for _, k := range etagHeaders {
+			if v, ok := req.Header[k]; ok {
+				removedHeaders[k] = v
+
+				req.Header.Del(k)
+			}
+		}
+
+		// Set NoCache headers
+		for k, v := range noCacheHeaders {
+			rw.Header().Set(k, v)
+		}
+		// Add removed headers to request context by creating a new context
+		ctx := context.WithValue(req.Context(), noCacheRequestHeadersKey, removedHeaders)
+		// Next
+		next.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+// cancelNoCache will remove NoCache headers from response
+func cancelNoCache(rw http.ResponseWriter) {
+	// Remove NoCache headers
+	for k := range noCacheHeaders {
+		rw.Header().Del(k)
+	}
+}
+
+// GetRequestHeader will get a request header even if it has been removed by NoCache middleware
+// Object requests need conditional headers to be answered by S3.
+func GetRequestHeader(req *http.Request, key string) string {
+	// Check if header is still present in request
+	if v := req.Header.Get(key); v != "" {
+		return v
+	}
+	// Get header removed by NoCache middleware
+	removedHeaders, _ := req.Context().Value(noCacheRequestHeadersKey).(http.Header)
+
+	return removedHeaders.Get(key)
+}
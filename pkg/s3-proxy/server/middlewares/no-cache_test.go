@@ -0,0 +1,39 @@
+// +build unit
+
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNoCache(t *testing.T) {
+	var ifNoneMatch, removedIfNoneMatch, ifRange string
+	handler := NoCache(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ifNoneMatch = req.Header.Get("If-None-Match")
+		removedIfNoneMatch = GetRequestHeader(req, "If-None-Match")
+		ifRange = GetRequestHeader(req, "If-Range")
+	}))
+
+	req := httptest.NewRequest("GET", "http://localhost/mount/file.txt", nil)
+	req.Header.Set("If-None-Match", `"etag"`)
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	if ifNoneMatch != "" {
+		t.Errorf("If-None-Match header = %v, want removed", ifNoneMatch)
+	}
+
+	if removedIfNoneMatch != `"etag"` {
+		t.Errorf("GetRequestHeader(If-None-Match) = %v, want %v", removedIfNoneMatch, `"etag"`)
+	}
+
+	if ifRange != "" {
+		t.Errorf("GetRequestHeader(If-Range) = %v, want empty", ifRange)
+	}
+
+	if rw.Header().Get("Cache-Control") != noCacheHeaders["Cache-Control"] {
+		t.Errorf("Cache-Control header = %v, want %v", rw.Header().Get("Cache-Control"), noCacheHeaders["Cache-Control"])
+	}
+}
@@ -0,0 +1,152 @@
+package middlewares
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+)
+
+// responseHeadersWriter will apply response headers rules just before writing status code
+type responseHeadersWriter struct {
+	http.ResponseWriter
+	rules       []*config.ResponseHeadersConfig
+	wroteHeader bool
+}
+
+// newResponseHeadersWriter will create a response headers writer supporting the same optional interfaces
+// as the wrapped response writer (like chi WrapResponseWriter)
+func newResponseHeadersWriter(rw http.ResponseWriter, protoMajor int, rules []*config.ResponseHeadersConfig) http.ResponseWriter {
+	bw := responseHeadersWriter{ResponseWriter: rw, rules: rules}
+	_, fl := rw.(http.Flusher)
+
+	if protoMajor == 2 {
+		_, ps := rw.(http.Pusher)
+		if fl && ps {
+			return &http2FancyResponseHeadersWriter{flushResponseHeadersWriter{bw}}
+		}
+	} else {
+		_, hj := rw.(http.Hijacker)
+		_, rf := rw.(io.ReaderFrom)
+		if fl && hj && rf {
+			return &httpFancyResponseHeadersWriter{flushResponseHeadersWriter{bw}}
+		}
+	}
+
+	if fl {
+		return &flushResponseHeadersWriter{bw}
+	}
+
+	return &bw
+}
+
+func (w *responseHeadersWriter) WriteHeader(code int) {
+	// Apply rules only once
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		// Apply rules in order
+		for _, rule := range w.rules {
+			for _, k := range rule.RemoveHeaders {
+				w.Header().Del(k)
+			}
+
+			for k, v := range rule.Headers {
+				w.Header().Set(k, v)
+			}
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseHeadersWriter) Write(b []byte) (int, error) {
+	w.maybeWriteHeader()
+
+	return w.ResponseWriter.Write(b)
+}
+
+// maybeWriteHeader will write status code if not already done
+func (w *responseHeadersWriter) maybeWriteHeader() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// flushResponseHeadersWriter is a response headers writer satisfying http.Flusher
+type flushResponseHeadersWriter struct {
+	responseHeadersWriter
+}
+
+func (w *flushResponseHeadersWriter) Flush() {
+	w.maybeWriteHeader()
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+// httpFancyResponseHeadersWriter is a response headers writer satisfying http.Flusher,
+// http.Hijacker and io.ReaderFrom
+type httpFancyResponseHeadersWriter struct {
+	flushResponseHeadersWriter
+}
+
+func (w *httpFancyResponseHeadersWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *httpFancyResponseHeadersWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.maybeWriteHeader()
+
+	return w.ResponseWriter.(io.ReaderFrom).ReadFrom(r)
+}
+
+// http2FancyResponseHeadersWriter is a response headers writer satisfying http.Flusher and http.Pusher
+type http2FancyResponseHeadersWriter struct {
+	flushResponseHeadersWriter
+}
+
+func (w *http2FancyResponseHeadersWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+// ResponseHeaders will apply all response headers rules matching request path
+// and will cancel NoCache middleware if one of them disables it
+// This must be added before bucket request context middleware to apply rules on bucket responses
+func ResponseHeaders(rules []*config.ResponseHeadersConfig) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			// Find matching rules
+			matchingRules := findResponseHeadersRules(rules, req.URL.Path)
+			// Check if rules are matching
+			if len(matchingRules) == 0 {
+				next.ServeHTTP(rw, req)
+				// Stop
+				return
+			}
+			// Check if NoCache must be cancelled
+			for _, rule := range matchingRules {
+				if rule.DisableNoCache {
+					cancelNoCache(rw)
+
+					break
+				}
+			}
+			// Next with rules applied on response
+			next.ServeHTTP(newResponseHeadersWriter(rw, req.ProtoMajor, matchingRules), req)
+		})
+	}
+}
+
+// findResponseHeadersRules will find all response headers rules matching request path in order
+func findResponseHeadersRules(rules []*config.ResponseHeadersConfig, requestPath string) []*config.ResponseHeadersConfig {
+	res := make([]*config.ResponseHeadersConfig, 0)
+	// Loop over rules
+	for _, rule := range rules {
+		// Check if request path match glob pattern compiled at configuration load
+		if rule.PathGlob.Match(requestPath) {
+			res = append(res, rule)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,131 @@
+// +build unit
+
+package middlewares
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gobwas/glob"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
+)
+
+func TestResponseHeaders(t *testing.T) {
+	rules := []*config.ResponseHeadersConfig{
+		{
+			Path:           "/mount/assets/*",
+			PathGlob:       glob.MustCompile("/mount/assets/*"),
+			Headers:        map[string]string{"Cache-Control": "public, max-age=31536000"},
+			DisableNoCache: true,
+		},
+		{
+			Path:          "/mount/**",
+			PathGlob:      glob.MustCompile("/mount/**"),
+			Headers:       map[string]string{"X-Frame-Options": "DENY"},
+			RemoveHeaders: []string{"X-Backend"},
+		},
+	}
+	tests := []struct {
+		name            string
+		requestPath     string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:        "should keep NoCache when no rule disables it",
+			requestPath: "/mount/index.html",
+			expectedHeaders: map[string]string{
+				"Cache-Control":   noCacheHeaders["Cache-Control"],
+				"Expires":         epoch,
+				"X-Frame-Options": "DENY",
+				"X-Backend":       "",
+			},
+		},
+		{
+			name:        "should disable NoCache",
+			requestPath: "/mount/assets/app.js",
+			expectedHeaders: map[string]string{
+				"Cache-Control":   "public, max-age=31536000",
+				"Expires":         "",
+				"Pragma":          "",
+				"X-Frame-Options": "DENY",
+				"X-Backend":       "",
+			},
+		},
+		{
+			name:        "should not change response without matching rules",
+			requestPath: "/other/file",
+			expectedHeaders: map[string]string{
+				"Cache-Control":   noCacheHeaders["Cache-Control"],
+				"X-Frame-Options": "",
+				"X-Backend":       "backend",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ifNoneMatch := ""
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				ifNoneMatch = req.Header.Get("If-None-Match")
+				rw.Header().Set("X-Backend", "backend")
+				rw.Write([]byte("content"))
+			})
+			h := NoCache(ResponseHeaders(rules)(next))
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "http://localhost"+tt.requestPath, nil)
+			req.Header.Set("If-None-Match", "\"etag\"")
+			h.ServeHTTP(recorder, req)
+			// Conditional headers are only available for object requests
+			if ifNoneMatch != "" {
+				t.Errorf("ResponseHeaders() => If-None-Match = %v, want removed", ifNoneMatch)
+			}
+			for k, v := range tt.expectedHeaders {
+				if got := recorder.Header().Get(k); got != v {
+					t.Errorf("ResponseHeaders() => header %s = %v, want %v", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+// hijackRecorder is a response recorder supporting all HTTP/1 optional interfaces
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) { return nil, nil, nil }
+func (r *hijackRecorder) ReadFrom(src io.Reader) (int64, error) {
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestResponseHeaders_OptionalInterfaces(t *testing.T) {
+	rules := []*config.ResponseHeadersConfig{
+		{Path: "/**", PathGlob: glob.MustCompile("/**"), Headers: map[string]string{"X-Frame-Options": "DENY"}},
+	}
+	recorder := &hijackRecorder{httptest.NewRecorder()}
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if _, ok := rw.(http.Hijacker); !ok {
+			t.Errorf("ResponseHeaders() => response writer isn't a http.Hijacker")
+		}
+		if _, ok := rw.(http.Flusher); !ok {
+			t.Errorf("ResponseHeaders() => response writer isn't a http.Flusher")
+		}
+		// Write body with io.ReaderFrom
+		rf, ok := rw.(io.ReaderFrom)
+		if !ok {
+			t.Fatalf("ResponseHeaders() => response writer isn't a io.ReaderFrom")
+		}
+		rf.ReadFrom(strings.NewReader("content"))
+	})
+	ResponseHeaders(rules)(next).ServeHTTP(recorder, httptest.NewRequest("GET", "http://localhost/file", nil))
+	if got := recorder.Header().Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("ResponseHeaders() => header X-Frame-Options = %v, want DENY", got)
+	}
+	if got := recorder.Body.String(); got != "content" {
+		t.Errorf("ResponseHeaders() => body = %v, want content", got)
+	}
+}
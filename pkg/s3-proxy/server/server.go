@@ -1,8 +1,12 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -13,6 +17,7 @@ import (
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/log"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/metrics"
+	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/s3client"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/server/middlewares"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/server/utils"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/tracing"
@@ -30,6 +35,12 @@ type Server struct {
 	metricsCl  metrics.Client
 	server     *http.Server
 	tracingSvc tracing.Service
+	// s3clientManager is the S3 client manager used by current router
+	// It is replaced on configuration reload and closed on shutdown so it is guarded by mutex.
+	s3clientManager s3client.Manager
+	// stopped is true when server is shut down (configuration reloads are ignored)
+	stopped bool
+	mutex   sync.Mutex
 }
 
 func NewServer(logger log.Logger, cfgManager config.Manager, metricsCl metrics.Client, tracingSvc tracing.Service) *Server {
@@ -44,10 +55,34 @@ func NewServer(logger log.Logger, cfgManager config.Manager, metricsCl metrics.C
 func (svr *Server) Listen() error {
 	svr.logger.Infof("Server listening on %s", svr.server.Addr)
 	err := svr.server.ListenAndServe()
+	// Server closed by shutdown isn't an error
+	if err == http.ErrServerClosed {
+		return nil
+	}
 
 	return err
 }
 
+// Shutdown will stop server gracefully and will release S3 clients resources
+// This will wait for asynchronous mirror writes.
+func (svr *Server) Shutdown(ctx context.Context) error {
+	err := svr.server.Shutdown(ctx)
+	if err != nil {
+		return err
+	}
+
+	svr.mutex.Lock()
+	defer svr.mutex.Unlock()
+	// Check if server is already stopped to not close S3 client manager twice
+	if svr.stopped {
+		return nil
+	}
+
+	svr.stopped = true
+
+	return svr.s3clientManager.Close()
+}
+
 func (svr *Server) GenerateServer() error {
 	// Get configuration
 	cfg := svr.cfgManager.GetConfig()
@@ -66,7 +101,18 @@ func (svr *Server) GenerateServer() error {
 
 	// Prepare for configuration onChange
 	svr.cfgManager.AddOnChangeHook(func() {
+		svr.mutex.Lock()
+		defer svr.mutex.Unlock()
+		// Check if server is stopped because S3 client manager is already closed
+		if svr.stopped {
+			svr.logger.Info("Server is stopped, configuration reload is ignored")
+
+			return
+		}
+		// Keep previous S3 client manager
+		previousS3clientManager := svr.s3clientManager
 		// Generate router
+		// This will also rebuild S3 clients with the new configuration
 		r, err2 := svr.generateRouter()
 		if err2 != nil {
 			svr.logger.Fatal(err2)
@@ -74,6 +120,11 @@ func (svr *Server) GenerateServer() error {
 		// Change server handler
 		server.Handler = r
 		svr.logger.Info("Server handler reloaded")
+		// Release previous S3 clients resources
+		err2 = previousS3clientManager.Close()
+		if err2 != nil {
+			svr.logger.Error(err2)
+		}
 	})
 
 	// Store server
@@ -82,6 +133,8 @@ func (svr *Server) GenerateServer() error {
 	return nil
 }
 
+// generateRouter will generate router with a new S3 client manager
+// Caller must hold server mutex when server is running.
 func (svr *Server) generateRouter() (http.Handler, error) {
 	// Get configuration
 	cfg := svr.cfgManager.GetConfig()
@@ -89,6 +142,15 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 	// Create authentication service
 	authenticationSvc := authentication.NewAuthenticationService(cfg, svr.metricsCl)
 
+	// Create S3 client manager
+	// This will create one S3 session per target that will be reused by all requests
+	s3clientManager, err := s3client.NewManager(cfg, svr.metricsCl, svr.logger)
+	if err != nil {
+		return nil, err
+	}
+	// Store S3 client manager to close it on reload
+	svr.s3clientManager = s3clientManager
+
 	// Create router
 	r := chi.NewRouter()
 
@@ -106,7 +168,7 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 		"application/rss+xml",
 		"image/svg+xml",
 	))
-	r.Use(middleware.NoCache)
+	r.Use(middlewares.NoCache)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
@@ -177,7 +239,7 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 
 				rt2.Get("/", func(rw http.ResponseWriter, req *http.Request) {
 					logEntry := middlewares.GetLogEntry(req)
-					generateTargetList(rw, req.RequestURI, logEntry, cfg)
+					generateTargetList(rw, req, logEntry, cfg)
 				})
 			})
 		})
@@ -206,8 +268,11 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 		// Loop over path list
 		funk.ForEach(tgt.Mount.Path, func(path string) {
 			rt.Route(path, func(rt2 chi.Router) {
+				// Add response headers middleware to apply target rules on all responses
+				rt2.Use(middlewares.ResponseHeaders(tgt.ResponseHeaders))
+
 				// Add Bucket request context middleware to initialize it
-				rt2.Use(middlewares.BucketRequestContext(tgt, cfg.Templates, path, svr.metricsCl))
+				rt2.Use(middlewares.BucketRequestContext(tgt, cfg.Templates, path, s3clientManager))
 
 				// Add authentication middleware to router
 				rt2.Use(authenticationSvc.Middleware(tgt.Resources))
@@ -217,15 +282,53 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 
 				// Check if GET action is enabled
 				if tgt.Actions.GET != nil && tgt.Actions.GET.Enabled {
-					// Add GET method to router
-					rt2.Get("/*", func(rw http.ResponseWriter, req *http.Request) {
+					// Create GET handler also used for HEAD requests to answer with the same headers
+					// Bodies written for HEAD requests (like folder listings) are discarded by the HTTP server.
+					getHandler := func(rw http.ResponseWriter, req *http.Request) {
 						// Get bucket request context
 						brctx := middlewares.GetBucketRequestContext(req)
 						// Get request path
 						requestPath := chi.URLParam(req, "*")
+						// Get query parameters
+						qs := req.URL.Query()
+						// Parse maximum number of entries for folder listing if present
+						var maxKeys int64
+						if qs.Get(bucket.MaxKeysQueryParam) != "" {
+							var err error
+							maxKeys, err = strconv.ParseInt(qs.Get(bucket.MaxKeysQueryParam), 10, 64)
+							if err != nil || maxKeys <= 0 {
+								err = fmt.Errorf("%s query parameter must be a positive integer", bucket.MaxKeysQueryParam)
+								brctx.HandleBadRequest(err, requestPath)
+								// Stop
+								return
+							}
+						}
+						// Check if file versions are asked
+						_, versions := qs[bucket.VersionsQueryParam]
 						// Proxy GET Request
-						brctx.Get(requestPath)
-					})
+						brctx.Get(&bucket.GetInput{
+							RequestPath:                requestPath,
+							Range:                      req.Header.Get("Range"),
+							IfRange:                    middlewares.GetRequestHeader(req, "If-Range"),
+							IfMatch:                    middlewares.GetRequestHeader(req, "If-Match"),
+							IfNoneMatch:                middlewares.GetRequestHeader(req, "If-None-Match"),
+							IfModifiedSince:            httpTimeHeader(req, "If-Modified-Since"),
+							IfUnmodifiedSince:          httpTimeHeader(req, "If-Unmodified-Since"),
+							ContinuationToken:          qs.Get(bucket.ContinuationTokenQueryParam),
+							MaxKeys:                    maxKeys,
+							PreviousContinuationTokens: qs[bucket.PreviousContinuationTokenQueryParam],
+							JSONListing:                utils.IsJSONRequested(req),
+							Archive:                    qs.Get(bucket.ArchiveQueryParam),
+							VersionID:                  qs.Get(bucket.VersionIDQueryParam),
+							Versions:                   versions,
+							AcceptEncoding:             req.Header.Get("Accept-Encoding"),
+							HeadOnly:                   req.Method == http.MethodHead,
+						})
+					}
+					// Add GET method to router
+					rt2.Get("/*", getHandler)
+					// Add HEAD method to router
+					rt2.Head("/*", getHandler)
 				}
 
 				// Check if PUT action is enabled
@@ -236,6 +339,54 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 						brctx := middlewares.GetBucketRequestContext(req)
 						// Get request path
 						requestPath := chi.URLParam(req, "*")
+						// Create upload context for metadata and tags templates
+						uploadCtx := &bucket.UploadContext{
+							User:     authentication.GetAuthenticatedUser(req),
+							ClientIP: utils.ClientIP(req),
+							Time:     time.Now(),
+						}
+						// Check if a presigned upload URL is asked instead of an upload
+						if methods, ok := req.URL.Query()[bucket.PresignedUploadQueryParam]; ok {
+							inp := &bucket.PresignUploadInput{
+								RequestPath:   requestPath,
+								Method:        methods[0],
+								UploadContext: uploadCtx,
+								ContentType:   req.URL.Query().Get(bucket.PresignedUploadContentTypeQueryParam),
+								Size:          -1,
+							}
+							// Get declared size if exists
+							if sizeStr := req.URL.Query().Get(bucket.PresignedUploadSizeQueryParam); sizeStr != "" {
+								size, err := strconv.ParseInt(sizeStr, 10, 64)
+								if err != nil || size < 0 {
+									middlewares.GetLogEntry(req).Error(bucket.ErrPresignedUploadSizeInvalid)
+									brctx.HandleBadRequest(bucket.ErrPresignedUploadSizeInvalid, path)
+									// Stop
+									return
+								}
+
+								inp.Size = size
+							}
+
+							brctx.PresignUpload(inp)
+							// Stop
+							return
+						}
+						// Check if request body is the raw file content
+						if !utils.IsMultipartFormRequest(req) {
+							// Create input for put request with request path as key
+							inp := &bucket.PutInput{
+								RequestPath:   requestPath,
+								UploadContext: uploadCtx,
+								Files: []*bucket.PutFileInput{{
+									Body:        req.Body,
+									ContentType: req.Header.Get("Content-Type"),
+									Size:        req.ContentLength,
+								}},
+							}
+							brctx.Put(inp)
+							// Stop
+							return
+						}
 						// Get logger
 						logEntry := middlewares.GetLogEntry(req)
 						if err := req.ParseForm(); err != nil {
@@ -250,19 +401,41 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 							brctx.HandleInternalServerError(err, path)
 							return
 						}
-						// Get file from form
-						file, fileHeader, err := req.FormFile("file")
-						if err != nil {
-							logEntry.Error(err)
-							brctx.HandleInternalServerError(err, path)
+						// Get files from form
+						fileHeaders := req.MultipartForm.File[bucket.PutFileFormKey]
+						if len(fileHeaders) == 0 {
+							logEntry.Error(http.ErrMissingFile)
+							brctx.HandleBadRequest(http.ErrMissingFile, path)
 							return
 						}
+						// Get optional relative paths given in the same order as files
+						relativePaths := req.MultipartForm.Value[bucket.PutRelativePathFormKey]
 						// Create input for put request
 						inp := &bucket.PutInput{
-							RequestPath: requestPath,
-							Filename:    fileHeader.Filename,
-							Body:        file,
-							ContentType: fileHeader.Header.Get("Content-Type"),
+							RequestPath:   requestPath,
+							Files:         make([]*bucket.PutFileInput, 0, len(fileHeaders)),
+							UploadContext: uploadCtx,
+						}
+						for i, fileHeader := range fileHeaders {
+							file, err := fileHeader.Open()
+							if err != nil {
+								logEntry.Error(err)
+								brctx.HandleInternalServerError(err, path)
+								return
+							}
+							// Close file at the end of the request
+							defer file.Close()
+							// Use relative path as filename if given
+							filename := fileHeader.Filename
+							if i < len(relativePaths) && relativePaths[i] != "" {
+								filename = relativePaths[i]
+							}
+							inp.Files = append(inp.Files, &bucket.PutFileInput{
+								Filename:    filename,
+								Body:        file,
+								ContentType: fileHeader.Header.Get("Content-Type"),
+								Size:        fileHeader.Size,
+							})
 						}
 						brctx.Put(inp)
 					})
@@ -276,8 +449,12 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 						brctx := middlewares.GetBucketRequestContext(req)
 						// Get request path
 						requestPath := chi.URLParam(req, "*")
-						// Proxy GET Request
-						brctx.Delete(requestPath)
+						// Proxy DELETE Request
+						brctx.Delete(&bucket.DeleteInput{
+							RequestPath: requestPath,
+							DryRun:      req.URL.Query().Get(bucket.DryRunQueryParam) == "true",
+							VersionID:   req.URL.Query().Get(bucket.VersionIDQueryParam),
+						})
 					})
 				}
 			})
@@ -292,13 +469,72 @@ func (svr *Server) generateRouter() (http.Handler, error) {
 	return r, nil
 }
 
-func generateTargetList(rw http.ResponseWriter, path string, logger log.Logger, cfg *config.Config) {
-	err := utils.TemplateExecution(cfg.Templates.TargetList, "", logger, rw, struct{ Targets []*config.TargetConfig }{Targets: cfg.Targets}, 200)
+// targetListJSONData Target list data for JSON responses
+type targetListJSONData struct {
+	Targets []*targetJSONData `json:"targets"`
+}
+
+// targetJSONData Target data for JSON responses
+// Only public information are exposed here
+type targetJSONData struct {
+	Name  string               `json:"name"`
+	Mount *targetMountJSONData `json:"mount"`
+}
+
+// targetMountJSONData Target mount data for JSON responses
+type targetMountJSONData struct {
+	Host string   `json:"host"`
+	Path []string `json:"path"`
+}
+
+// newTargetListJSONData will create target list data for JSON responses from configuration
+func newTargetListJSONData(targets []*config.TargetConfig) *targetListJSONData {
+	res := &targetListJSONData{Targets: make([]*targetJSONData, 0, len(targets))}
+	// Loop over targets
+	for _, tgt := range targets {
+		res.Targets = append(res.Targets, &targetJSONData{
+			Name: tgt.Name,
+			Mount: &targetMountJSONData{
+				Host: tgt.Mount.Host,
+				Path: tgt.Mount.Path,
+			},
+		})
+	}
+
+	return res
+}
+
+func generateTargetList(rw http.ResponseWriter, req *http.Request, logger log.Logger, cfg *config.Config) {
+	var err error
+	// Check if JSON response is asked
+	if utils.IsJSONRequested(req) {
+		err = utils.JSONExecution(rw, newTargetListJSONData(cfg.Targets), http.StatusOK)
+	} else {
+		err = utils.TemplateExecution(cfg.Templates.TargetList, "", logger, rw, struct{ Targets []*config.TargetConfig }{Targets: cfg.Targets}, 200)
+	}
+	// Check if error exists
 	if err != nil {
 		logger.Error(err)
 		// ! In this case, use default default local files for error
-		utils.HandleInternalServerError(logger, rw, cfg.Templates, path, err)
+		utils.HandleInternalServerError(logger, rw, cfg.Templates, req.RequestURI, err)
 		// Stop here
 		return
 	}
 }
+
+// httpTimeHeader will parse a http date header (even if it has been removed by NoCache middleware)
+// Invalid or missing dates are ignored as asked by RFC 7232
+func httpTimeHeader(req *http.Request, name string) *time.Time {
+	value := middlewares.GetRequestHeader(req, name)
+	// Check if header is present
+	if value == "" {
+		return nil
+	}
+	// Parse date
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return nil
+	}
+
+	return &t
+}
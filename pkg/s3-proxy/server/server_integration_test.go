@@ -3,7 +3,11 @@
 package server
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,13 +16,20 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/gobwas/glob"
 	"github.com/golang/mock/gomock"
 	"github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config"
 	cmocks "github.com/oxyno-zeta/s3-proxy/pkg/s3-proxy/config/mocks"
@@ -50,6 +61,11 @@ func TestPublicRouter(t *testing.T) {
 	type args struct {
 		cfg *config.Config
 	}
+	type inputFile struct {
+		name         string
+		relativePath string
+		body         string
+	}
 	tests := []struct {
 		name               string
 		args               args
@@ -60,6 +76,8 @@ func TestPublicRouter(t *testing.T) {
 		inputBody          string
 		inputFileName      string
 		inputFileKey       string
+		inputFiles         []*inputFile
+		inputHeaders       map[string]string
 		expectedCode       int
 		expectedBody       string
 		expectedHeaders    map[string]string
@@ -160,7 +178,7 @@ func TestPublicRouter(t *testing.T) {
 			},
 		},
 		{
-			name: "GET a folder without index document enabled and custom folder list template",
+			name: "GET a folder page with max keys",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -193,27 +211,56 @@ func TestPublicRouter(t *testing.T) {
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
-							Templates: &config.TargetTemplateConfig{
-								FolderList: &config.TargetTemplateConfigItem{
-									InBucket: true,
-									Path:     "templates/folder-list.tpl",
-								},
-							},
 						},
 					},
 				},
 			},
 			inputMethod:  "GET",
-			inputURL:     "http://localhost/mount/folder1/",
+			inputURL:     "http://localhost/mount/?max-keys=2",
 			expectedCode: 200,
-			expectedBody: "fake template !",
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Index of /mount/</h1>
+    <table style="width:100%">
+        <thead>
+            <tr>
+                <th style="border-right:1px solid black;text-align:start">Entry</th>
+                <th style="border-right:1px solid black;text-align:start">Size</th>
+                <th style="border-right:1px solid black;text-align:start">Last modified</th>
+            </tr>
+        </thead>
+        <tbody style="border-top:1px solid black">
+          <tr>
+            <td style="border-right:1px solid black;padding: 0 5px"><a href="..">..</a></td>
+            <td style="border-right:1px solid black;padding: 0 5px"> - </td>
+            <td style="padding: 0 5px"> - </td>
+          </tr>
+          <tr>
+              <td style="border-right:1px solid black;padding: 0 5px"><a href="/mount/folder1/">folder1/</a></td>
+              <td style="border-right:1px solid black;padding: 0 5px">-</td>
+              <td style="padding: 0 5px">0001-01-01 00:00:00 &#43;0000 UTC</td>
+          </tr>
+          <tr>
+              <td style="border-right:1px solid black;padding: 0 5px"><a href="/mount/folder2/">folder2/</a></td>
+              <td style="border-right:1px solid black;padding: 0 5px">-</td>
+              <td style="padding: 0 5px">0001-01-01 00:00:00 &#43;0000 UTC</td>
+          </tr>
+        </tbody>
+    </table>
+    <p>
+      <a href="/mount/?continuation-token=Zm9sZGVyMi9pbmRleC5odG1s&amp;max-keys=2&amp;previous-token=">Next page</a>
+    </p>
+  </body>
+</html>
+`,
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
 				"Content-Type":  "text/html; charset=utf-8",
 			},
 		},
 		{
-			name: "GET a file with success",
+			name: "GET a folder next page",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -251,16 +298,46 @@ func TestPublicRouter(t *testing.T) {
 				},
 			},
 			inputMethod:  "GET",
-			inputURL:     "http://localhost/mount/folder1/test.txt",
+			inputURL:     "http://localhost/mount/?continuation-token=Zm9sZGVyMi9pbmRleC5odG1s&max-keys=2&previous-token=",
 			expectedCode: 200,
-			expectedBody: "Hello folder1!",
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Index of /mount/</h1>
+    <table style="width:100%">
+        <thead>
+            <tr>
+                <th style="border-right:1px solid black;text-align:start">Entry</th>
+                <th style="border-right:1px solid black;text-align:start">Size</th>
+                <th style="border-right:1px solid black;text-align:start">Last modified</th>
+            </tr>
+        </thead>
+        <tbody style="border-top:1px solid black">
+          <tr>
+            <td style="border-right:1px solid black;padding: 0 5px"><a href="..">..</a></td>
+            <td style="border-right:1px solid black;padding: 0 5px"> - </td>
+            <td style="padding: 0 5px"> - </td>
+          </tr>
+          <tr>
+              <td style="border-right:1px solid black;padding: 0 5px"><a href="/mount/templates/">templates/</a></td>
+              <td style="border-right:1px solid black;padding: 0 5px">-</td>
+              <td style="padding: 0 5px">0001-01-01 00:00:00 &#43;0000 UTC</td>
+          </tr>
+        </tbody>
+    </table>
+    <p>
+      <a href="/mount/?max-keys=2">Previous page</a>
+    </p>
+  </body>
+</html>
+`,
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/plain; charset=utf-8",
+				"Content-Type":  "text/html; charset=utf-8",
 			},
 		},
 		{
-			name: "GET a file with a not found error",
+			name: "GET a folder as JSON with format query parameter",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -297,23 +374,17 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:  "GET",
-			inputURL:     "http://localhost/mount/folder1/test.txt-not-existing",
-			expectedCode: 404,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Not Found /mount/folder1/test.txt-not-existing</h1>
-  </body>
-</html>
-`,
+			inputMethod:     "GET",
+			inputURL:        "http://localhost/mount/folder1/?format=json",
+			expectedCode:    200,
+			notExpectedBody: "<!DOCTYPE html>",
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/html; charset=utf-8",
+				"Content-Type":  "application/json; charset=utf-8",
 			},
 		},
 		{
-			name: "GET a file with a not found error because of not valid host",
+			name: "GET a folder as JSON with accept header and index document enabled",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -342,32 +413,27 @@ func TestPublicRouter(t *testing.T) {
 							},
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
-								Host: "test.local",
 							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
+							IndexDocument: "index.html",
 						},
 					},
 				},
 			},
-			inputMethod:  "GET",
-			inputURL:     "http://localhost/mount/folder1/test.txt",
-			expectedCode: 404,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Not Found /mount/folder1/test.txt</h1>
-  </body>
-</html>
-`,
+			inputMethod:     "GET",
+			inputURL:        "http://localhost/mount/folder1/",
+			inputHeaders:    map[string]string{"Accept": "application/json"},
+			expectedCode:    200,
+			notExpectedBody: "<!DOCTYPE html>",
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/html; charset=utf-8",
+				"Content-Type":  "application/json; charset=utf-8",
 			},
 		},
 		{
-			name: "GET a file with success on specific host",
+			name: "GET a folder with an invalid max keys",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -396,7 +462,6 @@ func TestPublicRouter(t *testing.T) {
 							},
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
-								Host: "test.local",
 							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
@@ -406,16 +471,15 @@ func TestPublicRouter(t *testing.T) {
 				},
 			},
 			inputMethod:  "GET",
-			inputURL:     "http://test.local/mount/folder1/test.txt",
-			expectedCode: 200,
-			expectedBody: "Hello folder1!",
+			inputURL:     "http://localhost/mount/?max-keys=wrong",
+			expectedCode: 400,
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/plain; charset=utf-8",
+				"Content-Type":  "text/html; charset=utf-8",
 			},
 		},
 		{
-			name: "GET a file with forbidden error in case of no resource found",
+			name: "GET a folder without index document enabled and custom folder list template",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -429,13 +493,6 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -452,47 +509,30 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Resources: []*config.Resource{
-								{
-									Path:     "/mount/folder2/*",
-									Methods:  []string{"GET"},
-									Provider: "provider1",
-									Basic: &config.ResourceBasic{
-										Credentials: []*config.BasicAuthUserConfig{
-											{
-												User: "user1",
-												Password: &config.CredentialConfig{
-													Value: "pass1",
-												},
-											},
-										},
-									},
-								},
-							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
+							Templates: &config.TargetTemplateConfig{
+								FolderList: &config.TargetTemplateConfigItem{
+									InBucket: true,
+									Path:     "templates/folder-list.tpl",
+								},
+							},
 						},
 					},
 				},
 			},
 			inputMethod:  "GET",
-			inputURL:     "http://localhost/mount/folder1/test.txt",
-			expectedCode: 403,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Forbidden</h1>
-  </body>
-</html>
-`,
+			inputURL:     "http://localhost/mount/folder1/",
+			expectedCode: 200,
+			expectedBody: "fake template !",
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
 				"Content-Type":  "text/html; charset=utf-8",
 			},
 		},
 		{
-			name: "GET a file with forbidden error in case of no resource found because no valid http methods",
+			name: "GET a file with success",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -506,13 +546,6 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -529,23 +562,6 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Resources: []*config.Resource{
-								{
-									Path:     "/mount/folder2/*",
-									Methods:  []string{"PUT"},
-									Provider: "provider1",
-									Basic: &config.ResourceBasic{
-										Credentials: []*config.BasicAuthUserConfig{
-											{
-												User: "user1",
-												Password: &config.CredentialConfig{
-													Value: "pass1",
-												},
-											},
-										},
-									},
-								},
-							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -555,21 +571,15 @@ func TestPublicRouter(t *testing.T) {
 			},
 			inputMethod:  "GET",
 			inputURL:     "http://localhost/mount/folder1/test.txt",
-			expectedCode: 403,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Forbidden</h1>
-  </body>
-</html>
-`,
+			expectedCode: 200,
+			expectedBody: "Hello folder1!",
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/html; charset=utf-8",
+				"Content-Type":  "text/plain; charset=utf-8",
 			},
 		},
 		{
-			name: "GET a file with unauthorized error in case of no basic auth",
+			name: "GET a file range with success",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -583,13 +593,6 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -606,23 +609,6 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Resources: []*config.Resource{
-								{
-									Path:     "/mount/folder1/*",
-									Methods:  []string{"GET"},
-									Provider: "provider1",
-									Basic: &config.ResourceBasic{
-										Credentials: []*config.BasicAuthUserConfig{
-											{
-												User: "user1",
-												Password: &config.CredentialConfig{
-													Value: "pass1",
-												},
-											},
-										},
-									},
-								},
-							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -632,22 +618,17 @@ func TestPublicRouter(t *testing.T) {
 			},
 			inputMethod:  "GET",
 			inputURL:     "http://localhost/mount/folder1/test.txt",
-			expectedCode: 401,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Unauthorized</h1>
-  </body>
-</html>
-`,
+			inputHeaders: map[string]string{"Range": "bytes=0-4"},
+			expectedCode: 206,
+			expectedBody: "Hello",
 			expectedHeaders: map[string]string{
-				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":     "text/html; charset=utf-8",
-				"Www-Authenticate": "Basic realm=\"realm1\"",
+				"Accept-Ranges":  "bytes",
+				"Content-Length": "5",
+				"Content-Range":  "bytes 0-4/14",
 			},
 		},
 		{
-			name: "GET a file with unauthorized error in case of not found basic auth user",
+			name: "GET a file range with a valid If-Range",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -661,13 +642,6 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -684,23 +658,6 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Resources: []*config.Resource{
-								{
-									Path:     "/mount/folder1/*",
-									Methods:  []string{"GET"},
-									Provider: "provider1",
-									Basic: &config.ResourceBasic{
-										Credentials: []*config.BasicAuthUserConfig{
-											{
-												User: "user1",
-												Password: &config.CredentialConfig{
-													Value: "pass1",
-												},
-											},
-										},
-									},
-								},
-							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -708,26 +665,19 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:        "GET",
-			inputURL:           "http://localhost/mount/folder1/test.txt",
-			inputBasicUser:     "user2",
-			inputBasicPassword: "pass2",
-			expectedCode:       401,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Unauthorized</h1>
-  </body>
-</html>
-`,
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			inputHeaders: map[string]string{"Range": "bytes=0-4", "If-Range": `"c3e030a544fde7d10ea1aa8929354661"`},
+			expectedCode: 206,
+			expectedBody: "Hello",
 			expectedHeaders: map[string]string{
-				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":     "text/html; charset=utf-8",
-				"Www-Authenticate": "Basic realm=\"realm1\"",
+				"Accept-Ranges":  "bytes",
+				"Content-Length": "5",
+				"Content-Range":  "bytes 0-4/14",
 			},
 		},
 		{
-			name: "GET a file with unauthorized error in case of wrong basic auth password",
+			name: "GET a full file with a stale If-Range",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -741,13 +691,6 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -764,23 +707,6 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Resources: []*config.Resource{
-								{
-									Path:     "/mount/folder1/*",
-									Methods:  []string{"GET"},
-									Provider: "provider1",
-									Basic: &config.ResourceBasic{
-										Credentials: []*config.BasicAuthUserConfig{
-											{
-												User: "user1",
-												Password: &config.CredentialConfig{
-													Value: "pass1",
-												},
-											},
-										},
-									},
-								},
-							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -788,26 +714,18 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:        "GET",
-			inputURL:           "http://localhost/mount/folder1/test.txt",
-			inputBasicUser:     "user1",
-			inputBasicPassword: "pass2",
-			expectedCode:       401,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Unauthorized</h1>
-  </body>
-</html>
-`,
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			inputHeaders: map[string]string{"Range": "bytes=0-4", "If-Range": `"other-etag"`},
+			expectedCode: 200,
+			expectedBody: "Hello folder1!",
 			expectedHeaders: map[string]string{
-				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":     "text/html; charset=utf-8",
-				"Www-Authenticate": "Basic realm=\"realm1\"",
+				"Accept-Ranges":  "bytes",
+				"Content-Length": "14",
 			},
 		},
 		{
-			name: "GET a file with success in case of valid basic auth",
+			name: "GET a file range with a range not satisfiable error",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -821,13 +739,6 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -844,23 +755,6 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Resources: []*config.Resource{
-								{
-									Path:     "/mount/folder1/*",
-									Methods:  []string{"GET"},
-									Provider: "provider1",
-									Basic: &config.ResourceBasic{
-										Credentials: []*config.BasicAuthUserConfig{
-											{
-												User: "user1",
-												Password: &config.CredentialConfig{
-													Value: "pass1",
-												},
-											},
-										},
-									},
-								},
-							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -868,19 +762,16 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:        "GET",
-			inputURL:           "http://localhost/mount/folder1/test.txt",
-			inputBasicUser:     "user1",
-			inputBasicPassword: "pass1",
-			expectedCode:       200,
-			expectedBody:       "Hello folder1!",
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			inputHeaders: map[string]string{"Range": "bytes=100-200"},
+			expectedCode: 416,
 			expectedHeaders: map[string]string{
-				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/plain; charset=utf-8",
+				"Content-Range": "bytes */14",
 			},
 		},
 		{
-			name: "GET a file with success in case of whitelist",
+			name: "GET a file not modified",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -894,13 +785,6 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -917,28 +801,6 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Resources: []*config.Resource{
-								{
-									Path:      "/mount/folder1/test.txt",
-									Methods:   []string{"GET"},
-									WhiteList: &trueValue,
-								},
-								{
-									Path:     "/mount/folder1/*",
-									Methods:  []string{"GET"},
-									Provider: "provider1",
-									Basic: &config.ResourceBasic{
-										Credentials: []*config.BasicAuthUserConfig{
-											{
-												User: "user1",
-												Password: &config.CredentialConfig{
-													Value: "pass1",
-												},
-											},
-										},
-									},
-								},
-							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -948,24 +810,15 @@ func TestPublicRouter(t *testing.T) {
 			},
 			inputMethod:  "GET",
 			inputURL:     "http://localhost/mount/folder1/test.txt",
-			expectedCode: 200,
-			expectedBody: "Hello folder1!",
-			expectedHeaders: map[string]string{
-				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/plain; charset=utf-8",
-			},
+			inputHeaders: map[string]string{"If-None-Match": `"c3e030a544fde7d10ea1aa8929354661"`},
+			expectedCode: 304,
 		},
 		{
-			name: "GET target list",
+			name: "GET a file modified",
 			args: args{
 				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{
-						Enabled: true,
-						Mount: &config.MountConfig{
-							Path: []string{"/"},
-						},
-					},
-					Tracing: tracingConfig,
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
 					Templates: &config.TemplateConfig{
 						FolderList:          "../../../templates/folder-list.tpl",
 						TargetList:          "../../../templates/target-list.tpl",
@@ -999,60 +852,17 @@ func TestPublicRouter(t *testing.T) {
 				},
 			},
 			inputMethod:  "GET",
-			inputURL:     "http://localhost/",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			inputHeaders: map[string]string{"If-None-Match": `"other-etag"`},
 			expectedCode: 200,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Target buckets list</h1>
-    <ul>
-        <li>target1:
-          <ul>
-            <li><a href="/mount/">/mount/</a></li>
-          </ul>
-        </li>
-    </ul>
-  </body>
-</html>
-`,
-			expectedHeaders: map[string]string{
-				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/html; charset=utf-8",
-			},
+			expectedBody: "Hello folder1!",
 		},
 		{
-			name: "GET target list protected with basic authentication and without any password",
+			name: "GET a file with a precondition failed error",
 			args: args{
 				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{
-						Enabled: true,
-						Mount: &config.MountConfig{
-							Path: []string{"/"},
-						},
-						Resource: &config.Resource{
-							Path:     "/*",
-							Methods:  []string{"GET"},
-							Provider: "provider1",
-							Basic: &config.ResourceBasic{
-								Credentials: []*config.BasicAuthUserConfig{
-									{
-										User: "user1",
-										Password: &config.CredentialConfig{
-											Value: "pass1",
-										},
-									},
-								},
-							},
-						},
-					},
-					Tracing: tracingConfig,
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
 					Templates: &config.TemplateConfig{
 						FolderList:          "../../../templates/folder-list.tpl",
 						TargetList:          "../../../templates/target-list.tpl",
@@ -1086,54 +896,16 @@ func TestPublicRouter(t *testing.T) {
 				},
 			},
 			inputMethod:  "GET",
-			inputURL:     "http://localhost/",
-			expectedCode: 401,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Unauthorized</h1>
-  </body>
-</html>
-`,
-			expectedHeaders: map[string]string{
-				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":     "text/html; charset=utf-8",
-				"Www-Authenticate": "Basic realm=\"realm1\"",
-			},
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			inputHeaders: map[string]string{"If-Match": `"other-etag"`},
+			expectedCode: 412,
 		},
 		{
-			name: "GET target list protected with basic authentication and with wrong user",
+			name: "GET a file with a not found error",
 			args: args{
 				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{
-						Enabled: true,
-						Mount: &config.MountConfig{
-							Path: []string{"/"},
-						},
-						Resource: &config.Resource{
-							Path:     "/*",
-							Methods:  []string{"GET"},
-							Provider: "provider1",
-							Basic: &config.ResourceBasic{
-								Credentials: []*config.BasicAuthUserConfig{
-									{
-										User: "user1",
-										Password: &config.CredentialConfig{
-											Value: "pass1",
-										},
-									},
-								},
-							},
-						},
-					},
-					Tracing: tracingConfig,
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
 					Templates: &config.TemplateConfig{
 						FolderList:          "../../../templates/folder-list.tpl",
 						TargetList:          "../../../templates/target-list.tpl",
@@ -1166,57 +938,27 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:        "GET",
-			inputURL:           "http://localhost/",
-			inputBasicUser:     "user2",
-			inputBasicPassword: "pass1",
-			expectedCode:       401,
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/test.txt-not-existing",
+			expectedCode: 404,
 			expectedBody: `<!DOCTYPE html>
 <html>
   <body>
-    <h1>Unauthorized</h1>
+    <h1>Not Found /mount/folder1/test.txt-not-existing</h1>
   </body>
 </html>
 `,
 			expectedHeaders: map[string]string{
-				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":     "text/html; charset=utf-8",
-				"Www-Authenticate": "Basic realm=\"realm1\"",
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
 			},
 		},
 		{
-			name: "GET target list protected with basic authentication and with wrong password",
+			name: "GET a file with a not found error because of not valid host",
 			args: args{
 				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{
-						Enabled: true,
-						Mount: &config.MountConfig{
-							Path: []string{"/"},
-						},
-						Resource: &config.Resource{
-							Path:     "/*",
-							Methods:  []string{"GET"},
-							Provider: "provider1",
-							Basic: &config.ResourceBasic{
-								Credentials: []*config.BasicAuthUserConfig{
-									{
-										User: "user1",
-										Password: &config.CredentialConfig{
-											Value: "pass1",
-										},
-									},
-								},
-							},
-						},
-					},
-					Tracing: tracingConfig,
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
 					Templates: &config.TemplateConfig{
 						FolderList:          "../../../templates/folder-list.tpl",
 						TargetList:          "../../../templates/target-list.tpl",
@@ -1241,6 +983,7 @@ func TestPublicRouter(t *testing.T) {
 							},
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
+								Host: "test.local",
 							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
@@ -1249,57 +992,27 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:        "GET",
-			inputURL:           "http://localhost/",
-			inputBasicUser:     "user1",
-			inputBasicPassword: "pass2",
-			expectedCode:       401,
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			expectedCode: 404,
 			expectedBody: `<!DOCTYPE html>
 <html>
   <body>
-    <h1>Unauthorized</h1>
+    <h1>Not Found /mount/folder1/test.txt</h1>
   </body>
 </html>
 `,
 			expectedHeaders: map[string]string{
-				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":     "text/html; charset=utf-8",
-				"Www-Authenticate": "Basic realm=\"realm1\"",
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
 			},
 		},
 		{
-			name: "GET target list protected with basic authentication with success",
+			name: "GET a file with success on specific host",
 			args: args{
 				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{
-						Enabled: true,
-						Mount: &config.MountConfig{
-							Path: []string{"/"},
-						},
-						Resource: &config.Resource{
-							Path:     "/*",
-							Methods:  []string{"GET"},
-							Provider: "provider1",
-							Basic: &config.ResourceBasic{
-								Credentials: []*config.BasicAuthUserConfig{
-									{
-										User: "user1",
-										Password: &config.CredentialConfig{
-											Value: "pass1",
-										},
-									},
-								},
-							},
-						},
-					},
-					Tracing: tracingConfig,
-					AuthProviders: &config.AuthProviderConfig{
-						Basic: map[string]*config.BasicAuthConfig{
-							"provider1": {
-								Realm: "realm1",
-							},
-						},
-					},
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
 					Templates: &config.TemplateConfig{
 						FolderList:          "../../../templates/folder-list.tpl",
 						TargetList:          "../../../templates/target-list.tpl",
@@ -1324,6 +1037,7 @@ func TestPublicRouter(t *testing.T) {
 							},
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
+								Host: "test.local",
 							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
@@ -1332,32 +1046,17 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:        "GET",
-			inputURL:           "http://localhost/",
-			inputBasicUser:     "user1",
-			inputBasicPassword: "pass1",
-			expectedCode:       http.StatusOK,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Target buckets list</h1>
-    <ul>
-        <li>target1:
-          <ul>
-            <li><a href="/mount/">/mount/</a></li>
-          </ul>
-        </li>
-    </ul>
-  </body>
-</html>
-`,
+			inputMethod:  "GET",
+			inputURL:     "http://test.local/mount/folder1/test.txt",
+			expectedCode: 200,
+			expectedBody: "Hello folder1!",
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/html; charset=utf-8",
+				"Content-Type":  "text/plain; charset=utf-8",
 			},
 		},
 		{
-			name: "GET index document with index document enabled with success",
+			name: "GET a file with forbidden error in case of no resource found",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -1371,6 +1070,13 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -1384,10 +1090,26 @@ func TestPublicRouter(t *testing.T) {
 								},
 								DisableSSL: true,
 							},
-							IndexDocument: "index.html",
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
+							Resources: []*config.Resource{
+								{
+									Path:     "/mount/folder2/*",
+									Methods:  []string{"GET"},
+									Provider: "provider1",
+									Basic: &config.ResourceBasic{
+										Credentials: []*config.BasicAuthUserConfig{
+											{
+												User: "user1",
+												Password: &config.CredentialConfig{
+													Value: "pass1",
+												},
+											},
+										},
+									},
+								},
+							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -1396,16 +1118,22 @@ func TestPublicRouter(t *testing.T) {
 				},
 			},
 			inputMethod:  "GET",
-			inputURL:     "http://localhost/mount/folder1/",
-			expectedCode: 200,
-			expectedBody: "<!DOCTYPE html><html><body><h1>Hello folder1!</h1></body></html>",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			expectedCode: 403,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Forbidden</h1>
+  </body>
+</html>
+`,
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
 				"Content-Type":  "text/html; charset=utf-8",
 			},
 		},
 		{
-			name: "GET a path with index document enabled with success",
+			name: "GET a file with forbidden error in case of no resource found because no valid http methods",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -1419,6 +1147,13 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -1432,10 +1167,26 @@ func TestPublicRouter(t *testing.T) {
 								},
 								DisableSSL: true,
 							},
-							IndexDocument: "index.html-fake",
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
+							Resources: []*config.Resource{
+								{
+									Path:     "/mount/folder2/*",
+									Methods:  []string{"PUT"},
+									Provider: "provider1",
+									Basic: &config.ResourceBasic{
+										Credentials: []*config.BasicAuthUserConfig{
+											{
+												User: "user1",
+												Password: &config.CredentialConfig{
+													Value: "pass1",
+												},
+											},
+										},
+									},
+								},
+							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -1443,17 +1194,23 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:     "GET",
-			inputURL:        "http://localhost/mount/folder1/",
-			expectedCode:    200,
-			notExpectedBody: "<!DOCTYPE html><html><body><h1>Hello folder1!</h1></body></html>",
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			expectedCode: 403,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Forbidden</h1>
+  </body>
+</html>
+`,
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
 				"Content-Type":  "text/html; charset=utf-8",
 			},
 		},
 		{
-			name: "DELETE a path with a 405 error (method not allowed) because DELETE not enabled",
+			name: "GET a file with unauthorized error in case of no basic auth",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -1467,6 +1224,13 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -1483,6 +1247,23 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
+							Resources: []*config.Resource{
+								{
+									Path:     "/mount/folder1/*",
+									Methods:  []string{"GET"},
+									Provider: "provider1",
+									Basic: &config.ResourceBasic{
+										Credentials: []*config.BasicAuthUserConfig{
+											{
+												User: "user1",
+												Password: &config.CredentialConfig{
+													Value: "pass1",
+												},
+											},
+										},
+									},
+								},
+							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
 							},
@@ -1490,15 +1271,24 @@ func TestPublicRouter(t *testing.T) {
 					},
 				},
 			},
-			inputMethod:  "DELETE",
-			inputURL:     "http://localhost/mount/folder1/text.txt",
-			expectedCode: http.StatusMethodNotAllowed,
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			expectedCode: 401,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Unauthorized</h1>
+  </body>
+</html>
+`,
 			expectedHeaders: map[string]string{
-				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":     "text/html; charset=utf-8",
+				"Www-Authenticate": "Basic realm=\"realm1\"",
 			},
 		},
 		{
-			name: "DELETE a path with success",
+			name: "GET a file with unauthorized error in case of not found basic auth user",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -1512,6 +1302,13 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -1528,23 +1325,50 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
+							Resources: []*config.Resource{
+								{
+									Path:     "/mount/folder1/*",
+									Methods:  []string{"GET"},
+									Provider: "provider1",
+									Basic: &config.ResourceBasic{
+										Credentials: []*config.BasicAuthUserConfig{
+											{
+												User: "user1",
+												Password: &config.CredentialConfig{
+													Value: "pass1",
+												},
+											},
+										},
+									},
+								},
+							},
 							Actions: &config.ActionsConfig{
-								GET:    &config.GetActionConfig{Enabled: true},
-								DELETE: &config.DeleteActionConfig{Enabled: true},
+								GET: &config.GetActionConfig{Enabled: true},
 							},
 						},
 					},
 				},
 			},
-			inputMethod:  "DELETE",
-			inputURL:     "http://localhost/mount/folder1/text.txt",
-			expectedCode: 204,
+			inputMethod:        "GET",
+			inputURL:           "http://localhost/mount/folder1/test.txt",
+			inputBasicUser:     "user2",
+			inputBasicPassword: "pass2",
+			expectedCode:       401,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Unauthorized</h1>
+  </body>
+</html>
+`,
 			expectedHeaders: map[string]string{
-				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":     "text/html; charset=utf-8",
+				"Www-Authenticate": "Basic realm=\"realm1\"",
 			},
 		},
 		{
-			name: "PUT in a path with success without allow override and don't need it",
+			name: "GET a file with unauthorized error in case of wrong basic auth password",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -1558,6 +1382,13 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -1574,34 +1405,50 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Actions: &config.ActionsConfig{
-								GET: &config.GetActionConfig{Enabled: true},
-								PUT: &config.PutActionConfig{
-									Enabled: true,
-									Config: &config.PutActionConfigConfig{
-										StorageClass: "Standard",
-										Metadata: map[string]string{
-											"meta1": "meta1",
-										},
-									},
-								},
-							},
-						},
-					},
+							Resources: []*config.Resource{
+								{
+									Path:     "/mount/folder1/*",
+									Methods:  []string{"GET"},
+									Provider: "provider1",
+									Basic: &config.ResourceBasic{
+										Credentials: []*config.BasicAuthUserConfig{
+											{
+												User: "user1",
+												Password: &config.CredentialConfig{
+													Value: "pass1",
+												},
+											},
+										},
+									},
+								},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
 				},
 			},
-			inputMethod:   "PUT",
-			inputURL:      "http://localhost/mount/folder1/",
-			inputFileName: "test2.txt",
-			inputFileKey:  "file",
-			inputBody:     "Hello test2!",
-			expectedCode:  204,
+			inputMethod:        "GET",
+			inputURL:           "http://localhost/mount/folder1/test.txt",
+			inputBasicUser:     "user1",
+			inputBasicPassword: "pass2",
+			expectedCode:       401,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Unauthorized</h1>
+  </body>
+</html>
+`,
 			expectedHeaders: map[string]string{
-				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":     "text/html; charset=utf-8",
+				"Www-Authenticate": "Basic realm=\"realm1\"",
 			},
 		},
 		{
-			name: "PUT in a path without allow override should failed",
+			name: "GET a file with success in case of valid basic auth",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -1615,6 +1462,13 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -1631,42 +1485,43 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Actions: &config.ActionsConfig{
-								GET: &config.GetActionConfig{Enabled: true},
-								PUT: &config.PutActionConfig{
-									Enabled: true,
-									Config: &config.PutActionConfigConfig{
-										StorageClass: "Standard",
-										Metadata: map[string]string{
-											"meta1": "meta1",
+							Resources: []*config.Resource{
+								{
+									Path:     "/mount/folder1/*",
+									Methods:  []string{"GET"},
+									Provider: "provider1",
+									Basic: &config.ResourceBasic{
+										Credentials: []*config.BasicAuthUserConfig{
+											{
+												User: "user1",
+												Password: &config.CredentialConfig{
+													Value: "pass1",
+												},
+											},
 										},
 									},
 								},
 							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
 						},
 					},
 				},
 			},
-			inputMethod:   "PUT",
-			inputURL:      "http://localhost/mount/folder1/",
-			inputFileName: "test.txt",
-			inputFileKey:  "file",
-			inputBody:     "Hello test1!",
-			expectedCode:  403,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Forbidden</h1>
-  </body>
-</html>
-`,
+			inputMethod:        "GET",
+			inputURL:           "http://localhost/mount/folder1/test.txt",
+			inputBasicUser:     "user1",
+			inputBasicPassword: "pass1",
+			expectedCode:       200,
+			expectedBody:       "Hello folder1!",
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/html; charset=utf-8",
+				"Content-Type":  "text/plain; charset=utf-8",
 			},
 		},
 		{
-			name: "PUT in a path with allow override should be ok",
+			name: "GET a file with success in case of whitelist",
 			args: args{
 				cfg: &config.Config{
 					ListTargets: &config.ListTargetsConfig{},
@@ -1680,6 +1535,13 @@ func TestPublicRouter(t *testing.T) {
 						InternalServerError: "../../../templates/internal-server-error.tpl",
 						Unauthorized:        "../../../templates/unauthorized.tpl",
 					},
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
 					Targets: []*config.TargetConfig{
 						{
 							Name: "target1",
@@ -1696,39 +1558,55 @@ func TestPublicRouter(t *testing.T) {
 							Mount: &config.MountConfig{
 								Path: []string{"/mount/"},
 							},
-							Actions: &config.ActionsConfig{
-								GET: &config.GetActionConfig{Enabled: true},
-								PUT: &config.PutActionConfig{
-									Enabled: true,
-									Config: &config.PutActionConfigConfig{
-										StorageClass: "Standard",
-										Metadata: map[string]string{
-											"meta1": "meta1",
+							Resources: []*config.Resource{
+								{
+									Path:      "/mount/folder1/test.txt",
+									Methods:   []string{"GET"},
+									WhiteList: &trueValue,
+								},
+								{
+									Path:     "/mount/folder1/*",
+									Methods:  []string{"GET"},
+									Provider: "provider1",
+									Basic: &config.ResourceBasic{
+										Credentials: []*config.BasicAuthUserConfig{
+											{
+												User: "user1",
+												Password: &config.CredentialConfig{
+													Value: "pass1",
+												},
+											},
 										},
-										AllowOverride: true,
 									},
 								},
 							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
 						},
 					},
 				},
 			},
-			inputMethod:   "PUT",
-			inputURL:      "http://localhost/mount/folder1/",
-			inputFileName: "test.txt",
-			inputFileKey:  "file",
-			inputBody:     "Hello test1!",
-			expectedCode:  204,
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			expectedCode: 200,
+			expectedBody: "Hello folder1!",
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/plain; charset=utf-8",
 			},
 		},
 		{
-			name: "PUT in a path should fail because no input",
+			name: "GET target list",
 			args: args{
 				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{},
-					Tracing:     tracingConfig,
+					ListTargets: &config.ListTargetsConfig{
+						Enabled: true,
+						Mount: &config.MountConfig{
+							Path: []string{"/"},
+						},
+					},
+					Tracing: tracingConfig,
 					Templates: &config.TemplateConfig{
 						FolderList:          "../../../templates/folder-list.tpl",
 						TargetList:          "../../../templates/target-list.tpl",
@@ -1756,22 +1634,25 @@ func TestPublicRouter(t *testing.T) {
 							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
-								PUT: &config.PutActionConfig{
-									Enabled: true,
-								},
 							},
 						},
 					},
 				},
 			},
-			inputMethod:  "PUT",
-			inputURL:     "http://localhost/mount/folder1/",
-			expectedCode: 500,
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/",
+			expectedCode: 200,
 			expectedBody: `<!DOCTYPE html>
 <html>
   <body>
-    <h1>Internal Server Error</h1>
-    <p>missing form body</p>
+    <h1>Target buckets list</h1>
+    <ul>
+        <li>target1:
+          <ul>
+            <li><a href="/mount/">/mount/</a></li>
+          </ul>
+        </li>
+    </ul>
   </body>
 </html>
 `,
@@ -1781,11 +1662,16 @@ func TestPublicRouter(t *testing.T) {
 			},
 		},
 		{
-			name: "PUT in a path should fail because wrong key in form",
+			name: "GET target list as JSON",
 			args: args{
 				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{},
-					Tracing:     tracingConfig,
+					ListTargets: &config.ListTargetsConfig{
+						Enabled: true,
+						Mount: &config.MountConfig{
+							Path: []string{"/"},
+						},
+					},
+					Tracing: tracingConfig,
 					Templates: &config.TemplateConfig{
 						FolderList:          "../../../templates/folder-list.tpl",
 						TargetList:          "../../../templates/target-list.tpl",
@@ -1813,81 +1699,2181 @@ func TestPublicRouter(t *testing.T) {
 							},
 							Actions: &config.ActionsConfig{
 								GET: &config.GetActionConfig{Enabled: true},
-								PUT: &config.PutActionConfig{
-									Enabled: true,
-								},
 							},
 						},
 					},
 				},
 			},
-			inputMethod:   "PUT",
-			inputURL:      "http://localhost/mount/folder1/",
-			inputFileName: "test.txt",
-			inputFileKey:  "wrongkey",
-			inputBody:     "Hello test1!",
-			expectedCode:  500,
-			expectedBody: `<!DOCTYPE html>
-<html>
-  <body>
-    <h1>Internal Server Error</h1>
-    <p>http: no such file</p>
-  </body>
-</html>
-`,
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/",
+			inputHeaders: map[string]string{"Accept": "application/json"},
+			expectedCode: 200,
+			expectedBody: `{"targets":[{"name":"target1","mount":{"host":"","path":["/mount/"]}}]}`,
 			expectedHeaders: map[string]string{
 				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/html; charset=utf-8",
+				"Content-Type":  "application/json; charset=utf-8",
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Create go mock controller
-			ctrl := gomock.NewController(t)
-			cfgManagerMock := cmocks.NewMockManager(ctrl)
-
-			// Load configuration in manager
-			cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(tt.args.cfg)
-
-			logger := log.NewLogger()
-			// Create tracing service
-			tsvc, err := tracing.New(cfgManagerMock, logger)
-			assert.NoError(t, err)
-
-			svr := &Server{
-				logger:     logger,
-				cfgManager: cfgManagerMock,
-				metricsCl:  metricsCtx,
-				tracingSvc: tsvc,
-			}
-			got, err := svr.generateRouter()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GenerateRouter() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			// If want error at this moment => stop
-			if tt.wantErr {
-				return
-			}
-			w := httptest.NewRecorder()
-			req, err := http.NewRequest(
-				tt.inputMethod,
-				tt.inputURL,
-				nil,
-			)
-			if err != nil {
-				t.Error(err)
-				return
-			}
-			// multipart form
-			if tt.inputBody != "" {
-				body := &bytes.Buffer{}
-				writer := multipart.NewWriter(body)
-				part, err := writer.CreateFormFile(tt.inputFileKey, filepath.Base(tt.inputFileName))
-				if err != nil {
-					t.Error(err)
-					return
+		{
+			name: "GET target list protected with basic authentication and without any password",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{
+						Enabled: true,
+						Mount: &config.MountConfig{
+							Path: []string{"/"},
+						},
+						Resource: &config.Resource{
+							Path:     "/*",
+							Methods:  []string{"GET"},
+							Provider: "provider1",
+							Basic: &config.ResourceBasic{
+								Credentials: []*config.BasicAuthUserConfig{
+									{
+										User: "user1",
+										Password: &config.CredentialConfig{
+											Value: "pass1",
+										},
+									},
+								},
+							},
+						},
+					},
+					Tracing: tracingConfig,
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/",
+			expectedCode: 401,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Unauthorized</h1>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":     "text/html; charset=utf-8",
+				"Www-Authenticate": "Basic realm=\"realm1\"",
+			},
+		},
+		{
+			name: "GET target list protected with basic authentication and with wrong user",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{
+						Enabled: true,
+						Mount: &config.MountConfig{
+							Path: []string{"/"},
+						},
+						Resource: &config.Resource{
+							Path:     "/*",
+							Methods:  []string{"GET"},
+							Provider: "provider1",
+							Basic: &config.ResourceBasic{
+								Credentials: []*config.BasicAuthUserConfig{
+									{
+										User: "user1",
+										Password: &config.CredentialConfig{
+											Value: "pass1",
+										},
+									},
+								},
+							},
+						},
+					},
+					Tracing: tracingConfig,
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:        "GET",
+			inputURL:           "http://localhost/",
+			inputBasicUser:     "user2",
+			inputBasicPassword: "pass1",
+			expectedCode:       401,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Unauthorized</h1>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":     "text/html; charset=utf-8",
+				"Www-Authenticate": "Basic realm=\"realm1\"",
+			},
+		},
+		{
+			name: "GET target list protected with basic authentication and with wrong password",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{
+						Enabled: true,
+						Mount: &config.MountConfig{
+							Path: []string{"/"},
+						},
+						Resource: &config.Resource{
+							Path:     "/*",
+							Methods:  []string{"GET"},
+							Provider: "provider1",
+							Basic: &config.ResourceBasic{
+								Credentials: []*config.BasicAuthUserConfig{
+									{
+										User: "user1",
+										Password: &config.CredentialConfig{
+											Value: "pass1",
+										},
+									},
+								},
+							},
+						},
+					},
+					Tracing: tracingConfig,
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:        "GET",
+			inputURL:           "http://localhost/",
+			inputBasicUser:     "user1",
+			inputBasicPassword: "pass2",
+			expectedCode:       401,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Unauthorized</h1>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control":    "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":     "text/html; charset=utf-8",
+				"Www-Authenticate": "Basic realm=\"realm1\"",
+			},
+		},
+		{
+			name: "GET target list protected with basic authentication with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{
+						Enabled: true,
+						Mount: &config.MountConfig{
+							Path: []string{"/"},
+						},
+						Resource: &config.Resource{
+							Path:     "/*",
+							Methods:  []string{"GET"},
+							Provider: "provider1",
+							Basic: &config.ResourceBasic{
+								Credentials: []*config.BasicAuthUserConfig{
+									{
+										User: "user1",
+										Password: &config.CredentialConfig{
+											Value: "pass1",
+										},
+									},
+								},
+							},
+						},
+					},
+					Tracing: tracingConfig,
+					AuthProviders: &config.AuthProviderConfig{
+						Basic: map[string]*config.BasicAuthConfig{
+							"provider1": {
+								Realm: "realm1",
+							},
+						},
+					},
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:        "GET",
+			inputURL:           "http://localhost/",
+			inputBasicUser:     "user1",
+			inputBasicPassword: "pass1",
+			expectedCode:       http.StatusOK,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Target buckets list</h1>
+    <ul>
+        <li>target1:
+          <ul>
+            <li><a href="/mount/">/mount/</a></li>
+          </ul>
+        </li>
+    </ul>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "GET index document with index document enabled with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							IndexDocument: "index.html",
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder1/",
+			expectedCode: 200,
+			expectedBody: "<!DOCTYPE html><html><body><h1>Hello folder1!</h1></body></html>",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "GET a path with index document enabled with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							IndexDocument: "index.html-fake",
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:     "GET",
+			inputURL:        "http://localhost/mount/folder1/",
+			expectedCode:    200,
+			notExpectedBody: "<!DOCTYPE html><html><body><h1>Hello folder1!</h1></body></html>",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "HEAD a file with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "HEAD",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			expectedCode: 200,
+			expectedHeaders: map[string]string{
+				"Accept-Ranges":  "bytes",
+				"Content-Length": "14",
+			},
+		},
+		{
+			name: "HEAD a file with a not found error",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "HEAD",
+			inputURL:     "http://localhost/mount/folder1/test.txt-not-existing",
+			expectedCode: 404,
+		},
+		{
+			name: "HEAD a folder without index document enabled",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "HEAD",
+			inputURL:     "http://localhost/mount/folder1/",
+			expectedCode: 200,
+			expectedBody: "",
+			expectedHeaders: map[string]string{
+				"Content-Type": "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "HEAD a folder with index document enabled",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+							IndexDocument: "index.html",
+						},
+					},
+				},
+			},
+			inputMethod:  "HEAD",
+			inputURL:     "http://localhost/mount/folder1/",
+			expectedCode: 200,
+			expectedHeaders: map[string]string{
+				"Accept-Ranges":  "bytes",
+				"Content-Length": "64",
+			},
+		},
+		{
+			name: "HEAD a file with forbidden error in case of resource without HEAD method",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+							Resources: []*config.Resource{
+								{
+									Path:      "/mount/folder1/*",
+									Methods:   []string{"GET"},
+									WhiteList: &trueValue,
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "HEAD",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			expectedCode: 403,
+		},
+		{
+			name: "HEAD a file with success in case of resource with HEAD method",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+							Resources: []*config.Resource{
+								{
+									Path:      "/mount/folder1/*",
+									Methods:   []string{"GET", "HEAD"},
+									WhiteList: &trueValue,
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "HEAD",
+			inputURL:     "http://localhost/mount/folder1/test.txt",
+			expectedCode: 200,
+			expectedHeaders: map[string]string{
+				"Content-Length": "14",
+			},
+		},
+		{
+			name: "DELETE a path with a 405 error (method not allowed) because DELETE not enabled",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "DELETE",
+			inputURL:     "http://localhost/mount/folder1/text.txt",
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+			},
+		},
+		{
+			name: "DELETE a path with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET:    &config.GetActionConfig{Enabled: true},
+								DELETE: &config.DeleteActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "DELETE",
+			inputURL:     "http://localhost/mount/folder1/text.txt",
+			expectedCode: 204,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+			},
+		},
+		{
+			name: "DELETE a folder with a 500 error because recursive deletion isn't enabled",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								DELETE: &config.DeleteActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "DELETE",
+			inputURL:     "http://localhost/mount/folder1/",
+			expectedCode: http.StatusInternalServerError,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+			},
+		},
+		{
+			name: "DELETE a folder recursively in dry run mode",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								DELETE: &config.DeleteActionConfig{
+									Enabled: true,
+									Config:  &config.DeleteActionConfigConfig{Recursive: true},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "DELETE",
+			inputURL:     "http://localhost/mount/folder1/?dry-run=true",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"dryRun":true,"deleted":["/mount/folder1/index.html","/mount/folder1/test.txt"],"failed":[]}`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "application/json; charset=utf-8",
+			},
+		},
+		{
+			name: "DELETE a not existing folder recursively with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								DELETE: &config.DeleteActionConfig{
+									Enabled: true,
+									Config:  &config.DeleteActionConfigConfig{Recursive: true},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "DELETE",
+			inputURL:     "http://localhost/mount/not-found/",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"dryRun":false,"deleted":[],"failed":[]}`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "application/json; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT in a path with success without allow override and don't need it",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+									Config: &config.PutActionConfigConfig{
+										StorageClass: "Standard",
+										Metadata: map[string]string{
+											"meta1": "meta1",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:   "PUT",
+			inputURL:      "http://localhost/mount/folder1/",
+			inputFileName: "test2.txt",
+			inputFileKey:  "file",
+			inputBody:     "Hello test2!",
+			expectedCode:  204,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+			},
+		},
+		{
+			name: "PUT in a path without allow override should failed",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+									Config: &config.PutActionConfigConfig{
+										StorageClass: "Standard",
+										Metadata: map[string]string{
+											"meta1": "meta1",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:   "PUT",
+			inputURL:      "http://localhost/mount/folder1/",
+			inputFileName: "test.txt",
+			inputFileKey:  "file",
+			inputBody:     "Hello test1!",
+			expectedCode:  403,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Forbidden</h1>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT in a path with allow override should be ok",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+									Config: &config.PutActionConfigConfig{
+										StorageClass: "Standard",
+										Metadata: map[string]string{
+											"meta1": "meta1",
+										},
+										AllowOverride: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:   "PUT",
+			inputURL:      "http://localhost/mount/folder1/",
+			inputFileName: "test.txt",
+			inputFileKey:  "file",
+			inputBody:     "Hello test1!",
+			expectedCode:  204,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+			},
+		},
+		{
+			name: "PUT in a path should fail because no input",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "PUT",
+			inputURL:     "http://localhost/mount/folder1/",
+			inputHeaders: map[string]string{"Content-Type": "multipart/form-data; boundary=fake"},
+			expectedCode: 500,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Internal Server Error</h1>
+    <p>missing form body</p>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT in a path should fail because wrong key in form",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:   "PUT",
+			inputURL:      "http://localhost/mount/folder1/",
+			inputFileName: "test.txt",
+			inputFileKey:  "wrongkey",
+			inputBody:     "Hello test1!",
+			expectedCode:  400,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Bad Request</h1>
+    <p>http: no such file</p>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT a raw body file with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "PUT",
+			inputURL:     "http://localhost/mount/folder3/raw.txt",
+			inputBody:    "Hello raw!",
+			inputHeaders: map[string]string{"Content-Type": "text/plain"},
+			expectedCode: 204,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+			},
+		},
+		{
+			name: "PUT a raw body file with server side encryption configured",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+								Encryption: &config.BucketEncryptionConfig{
+									ServerSideEncryption: "aws:kms",
+									KMSKeyID:             "key-id",
+								},
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "PUT",
+			inputURL:     "http://localhost/mount/folder3/encrypted.txt",
+			inputBody:    "Hello encrypted!",
+			inputHeaders: map[string]string{"Content-Type": "text/plain"},
+			expectedCode: 204,
+		},
+		{
+			name: "GET a file uploaded with a raw body",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder3/raw.txt",
+			expectedCode: 200,
+			expectedBody: "Hello raw!",
+		},
+		{
+			name: "PUT a raw body on a folder path should fail",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "PUT",
+			inputURL:     "http://localhost/mount/folder3/",
+			inputBody:    "Hello raw!",
+			expectedCode: 400,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Bad Request</h1>
+    <p>request path must contain a file name for raw body uploads</p>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT multiple files with relative paths with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod: "PUT",
+			inputURL:    "http://localhost/mount/folder4/",
+			inputFiles: []*inputFile{
+				{name: "file1.txt", body: "Hello file1!"},
+				{name: "file2.txt", relativePath: "sub/file2.txt", body: "Hello file2!"},
+			},
+			expectedCode: 200,
+			expectedBody: `{"uploaded":["/mount/folder4/file1.txt","/mount/folder4/sub/file2.txt"],"failed":[]}`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "application/json; charset=utf-8",
+			},
+		},
+		{
+			name: "GET a file uploaded with a relative path",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{Enabled: true},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/mount/folder4/sub/file2.txt",
+			expectedCode: 200,
+			expectedBody: "Hello file2!",
+		},
+		{
+			name: "PUT multiple files with a failure because override isn't allowed",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								GET: &config.GetActionConfig{Enabled: true},
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+									Config:  &config.PutActionConfigConfig{AllowOverride: false},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod: "PUT",
+			inputURL:    "http://localhost/mount/folder4/",
+			inputFiles: []*inputFile{
+				{name: "file1.txt", body: "Hello file1!"},
+				{name: "file3.txt", body: "Hello file3!"},
+			},
+			expectedCode: 403,
+			expectedBody: `{"uploaded":["/mount/folder4/file3.txt"],"failed":[{"path":"/mount/folder4/file1.txt","message":"object already exists and override isn't allowed"}]}`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "application/json; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT a file greater than maximum size should fail",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+									Config: &config.PutActionConfigConfig{
+										MaxSize:                  5,
+										AllowedContentTypes:      []string{"text/*"},
+										AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("text/*")},
+										AllowedFilenames:         []string{"*.txt"},
+										AllowedFilenamesGlobs:    []glob.Glob{glob.MustCompile("*.txt")},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "PUT",
+			inputURL:     "http://localhost/mount/folder5/file.txt",
+			inputBody:    "Hello policy!",
+			inputHeaders: map[string]string{"Content-Type": "text/plain"},
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Bad Request</h1>
+    <p>file size is greater than the maximum size allowed</p>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT a file with a content type not allowed should fail",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+									Config: &config.PutActionConfigConfig{
+										MaxSize:                  5,
+										AllowedContentTypes:      []string{"text/*"},
+										AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("text/*")},
+										AllowedFilenames:         []string{"*.txt"},
+										AllowedFilenamesGlobs:    []glob.Glob{glob.MustCompile("*.txt")},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "PUT",
+			inputURL:     "http://localhost/mount/folder5/file.txt",
+			inputBody:    "Hi!",
+			inputHeaders: map[string]string{"Content-Type": "application/x-msdownload"},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Bad Request</h1>
+    <p>file content type isn&#39;t allowed</p>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT a file with a file name not allowed should fail",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+									Config: &config.PutActionConfigConfig{
+										MaxSize:                  5,
+										AllowedContentTypes:      []string{"text/*"},
+										AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("text/*")},
+										AllowedFilenames:         []string{"*.txt"},
+										AllowedFilenamesGlobs:    []glob.Glob{glob.MustCompile("*.txt")},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "PUT",
+			inputURL:     "http://localhost/mount/folder5/file.exe",
+			inputBody:    "Hi!",
+			inputHeaders: map[string]string{"Content-Type": "text/plain"},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Bad Request</h1>
+    <p>file name isn&#39;t allowed</p>
+  </body>
+</html>
+`,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+		},
+		{
+			name: "PUT a file respecting upload policy with success",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     tracingConfig,
+					Templates: &config.TemplateConfig{
+						FolderList:          "../../../templates/folder-list.tpl",
+						TargetList:          "../../../templates/target-list.tpl",
+						NotFound:            "../../../templates/not-found.tpl",
+						Forbidden:           "../../../templates/forbidden.tpl",
+						BadRequest:          "../../../templates/bad-request.tpl",
+						InternalServerError: "../../../templates/internal-server-error.tpl",
+						Unauthorized:        "../../../templates/unauthorized.tpl",
+					},
+					Targets: []*config.TargetConfig{
+						{
+							Name: "target1",
+							Bucket: &config.BucketConfig{
+								Name:       bucket,
+								Region:     region,
+								S3Endpoint: s3server.URL,
+								Credentials: &config.BucketCredentialConfig{
+									AccessKey: &config.CredentialConfig{Value: accessKey},
+									SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+								},
+								DisableSSL: true,
+							},
+							Mount: &config.MountConfig{
+								Path: []string{"/mount/"},
+							},
+							Actions: &config.ActionsConfig{
+								PUT: &config.PutActionConfig{
+									Enabled: true,
+									Config: &config.PutActionConfigConfig{
+										MaxSize:                  5,
+										AllowedContentTypes:      []string{"text/*"},
+										AllowedContentTypesGlobs: []glob.Glob{glob.MustCompile("text/*")},
+										AllowedFilenames:         []string{"*.txt"},
+										AllowedFilenamesGlobs:    []glob.Glob{glob.MustCompile("*.txt")},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			inputMethod:  "PUT",
+			inputURL:     "http://localhost/mount/folder5/file.txt",
+			inputBody:    "Hi!",
+			inputHeaders: map[string]string{"Content-Type": "text/plain"},
+			expectedCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create go mock controller
+			ctrl := gomock.NewController(t)
+			cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+			// Load configuration in manager
+			cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(tt.args.cfg)
+
+			logger := log.NewLogger()
+			// Create tracing service
+			tsvc, err := tracing.New(cfgManagerMock, logger)
+			assert.NoError(t, err)
+
+			svr := &Server{
+				logger:     logger,
+				cfgManager: cfgManagerMock,
+				metricsCl:  metricsCtx,
+				tracingSvc: tsvc,
+			}
+			got, err := svr.generateRouter()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateRouter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			// If want error at this moment => stop
+			if tt.wantErr {
+				return
+			}
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(
+				tt.inputMethod,
+				tt.inputURL,
+				nil,
+			)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// multipart form
+			if tt.inputBody != "" && tt.inputFileKey != "" {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				part, err := writer.CreateFormFile(tt.inputFileKey, filepath.Base(tt.inputFileName))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				_, err = io.Copy(part, strings.NewReader(tt.inputBody))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				err = writer.Close()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				req, err = http.NewRequest(
+					tt.inputMethod,
+					tt.inputURL,
+					body,
+				)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				req.Header.Set("Content-Type", writer.FormDataContentType())
+			}
+			// multipart form with multiple files
+			if len(tt.inputFiles) != 0 {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				for _, f := range tt.inputFiles {
+					part, err := writer.CreateFormFile("file", f.name)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					_, err = io.Copy(part, strings.NewReader(f.body))
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					err = writer.WriteField("relativePath", f.relativePath)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+				}
+				err = writer.Close()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				req, err = http.NewRequest(
+					tt.inputMethod,
+					tt.inputURL,
+					body,
+				)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				req.Header.Set("Content-Type", writer.FormDataContentType())
+			}
+			// Raw body
+			if tt.inputBody != "" && tt.inputFileKey == "" {
+				req, err = http.NewRequest(
+					tt.inputMethod,
+					tt.inputURL,
+					strings.NewReader(tt.inputBody),
+				)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			// Add basic auth
+			if tt.inputBasicUser != "" {
+				req.SetBasicAuth(tt.inputBasicUser, tt.inputBasicPassword)
+			}
+			// Add headers
+			for k, v := range tt.inputHeaders {
+				req.Header.Set(k, v)
+			}
+			got.ServeHTTP(w, req)
+
+			if tt.expectedBody != "" {
+				body := w.Body.String()
+				if tt.expectedBody != body {
+					t.Errorf("Integration test on GenerateRouter() body = \"%v\", expected body \"%v\"", body, tt.expectedBody)
+				}
+			}
+
+			if tt.notExpectedBody != "" {
+				body := w.Body.String()
+				if tt.notExpectedBody == body {
+					t.Errorf("Integration test on GenerateRouter() body = \"%v\", not expected body \"%v\"", body, tt.notExpectedBody)
+				}
+			}
+
+			if tt.expectedHeaders != nil {
+				for key, val := range tt.expectedHeaders {
+					wheader := w.HeaderMap.Get(key)
+					if val != wheader {
+						t.Errorf("Integration test on GenerateRouter() header %s = %v, expected %v", key, wheader, val)
+					}
+				}
+			}
+
+			if tt.expectedCode != w.Code {
+				t.Errorf("Integration test on GenerateRouter() status code = %v, expected status code %v", w.Code, tt.expectedCode)
+			}
+		})
+	}
+}
+
+func TestTracing(t *testing.T) {
+	// trueValue := true
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tplConfig := &config.TemplateConfig{
+		FolderList:          "../../../templates/folder-list.tpl",
+		TargetList:          "../../../templates/target-list.tpl",
+		NotFound:            "../../../templates/not-found.tpl",
+		Forbidden:           "../../../templates/forbidden.tpl",
+		BadRequest:          "../../../templates/bad-request.tpl",
+		InternalServerError: "../../../templates/internal-server-error.tpl",
+		Unauthorized:        "../../../templates/unauthorized.tpl",
+	}
+	targetsCfg := []*config.TargetConfig{
+		{
+			Name: "target1",
+			Bucket: &config.BucketConfig{
+				Name:       bucket,
+				Region:     region,
+				S3Endpoint: s3server.URL,
+				Credentials: &config.BucketCredentialConfig{
+					AccessKey: &config.CredentialConfig{Value: accessKey},
+					SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+				},
+				DisableSSL: true,
+			},
+			Mount: &config.MountConfig{
+				Path: []string{"/mount/"},
+			},
+			Actions: &config.ActionsConfig{
+				GET: &config.GetActionConfig{Enabled: true},
+			},
+		},
+	}
+	type args struct {
+		cfg *config.Config
+	}
+	tests := []struct {
+		name               string
+		args               args
+		inputMethod        string
+		inputURL           string
+		inputBasicUser     string
+		inputBasicPassword string
+		inputBody          string
+		inputFileName      string
+		inputFileKey       string
+		expectedCode       int
+		expectedBody       string
+		expectedHeaders    map[string]string
+		notExpectedBody    string
+		wantErr            bool
+	}{
+		{
+			name: "GET a not found path without any tracing configuration",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing:     &config.TracingConfig{},
+					Templates:   tplConfig,
+					Targets:     targetsCfg,
+				},
+			},
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/not-found/",
+			expectedCode: 404,
+			expectedBody: "404 page not found\n",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/plain; charset=utf-8",
+			},
+		},
+		{
+			name: "GET a not found path with a tracing configuration",
+			args: args{
+				cfg: &config.Config{
+					ListTargets: &config.ListTargetsConfig{},
+					Tracing: &config.TracingConfig{
+						Enabled:       true,
+						UDPHost:       "localhost:6831",
+						FlushInterval: "120s",
+					},
+					Templates: tplConfig,
+					Targets:   targetsCfg,
+				},
+			},
+			inputMethod:  "GET",
+			inputURL:     "http://localhost/not-found/",
+			expectedCode: 404,
+			expectedBody: "404 page not found\n",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
+				"Content-Type":  "text/plain; charset=utf-8",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create go mock controller
+			ctrl := gomock.NewController(t)
+			cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+			// Load configuration in manager
+			cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(tt.args.cfg)
+
+			logger := log.NewLogger()
+			// Create tracing service
+			tsvc, err := tracing.New(cfgManagerMock, logger)
+			assert.NoError(t, err)
+
+			svr := &Server{
+				logger:     logger,
+				cfgManager: cfgManagerMock,
+				metricsCl:  metricsCtx,
+				tracingSvc: tsvc,
+			}
+			got, err := svr.generateRouter()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateRouter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			// If want error at this moment => stop
+			if tt.wantErr {
+				return
+			}
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(
+				tt.inputMethod,
+				tt.inputURL,
+				nil,
+			)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// multipart form
+			if tt.inputBody != "" {
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				part, err := writer.CreateFormFile(tt.inputFileKey, filepath.Base(tt.inputFileName))
+				if err != nil {
+					t.Error(err)
+					return
 				}
 				_, err = io.Copy(part, strings.NewReader(tt.inputBody))
 				if err != nil {
@@ -1916,38 +3902,1958 @@ func TestPublicRouter(t *testing.T) {
 			}
 			got.ServeHTTP(w, req)
 
-			if tt.expectedBody != "" {
-				body := w.Body.String()
-				if tt.expectedBody != body {
-					t.Errorf("Integration test on GenerateRouter() body = \"%v\", expected body \"%v\"", body, tt.expectedBody)
-				}
-			}
+			if tt.expectedBody != "" {
+				body := w.Body.String()
+				if tt.expectedBody != body {
+					t.Errorf("Integration test on GenerateRouter() body = \"%v\", expected body \"%v\"", body, tt.expectedBody)
+				}
+			}
+
+			if tt.notExpectedBody != "" {
+				body := w.Body.String()
+				if tt.notExpectedBody == body {
+					t.Errorf("Integration test on GenerateRouter() body = \"%v\", not expected body \"%v\"", body, tt.notExpectedBody)
+				}
+			}
+
+			if tt.expectedHeaders != nil {
+				for key, val := range tt.expectedHeaders {
+					wheader := w.HeaderMap.Get(key)
+					if val != wheader {
+						t.Errorf("Integration test on GenerateRouter() header %s = %v, expected %v", key, wheader, val)
+					}
+				}
+			}
+
+			if tt.expectedCode != w.Code {
+				t.Errorf("Integration test on GenerateRouter() status code = %v, expected status code %v", w.Code, tt.expectedCode)
+			}
+		})
+	}
+}
+
+func TestPresignedURLRedirect(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		AuthProviders: &config.AuthProviderConfig{
+			Basic: map[string]*config.BasicAuthConfig{
+				"provider1": {
+					Realm: "realm1",
+				},
+			},
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name: "target1",
+				Bucket: &config.BucketConfig{
+					Name:       bucket,
+					Region:     region,
+					S3Endpoint: s3server.URL,
+					Credentials: &config.BucketCredentialConfig{
+						AccessKey: &config.CredentialConfig{Value: accessKey},
+						SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+					},
+					DisableSSL: true,
+				},
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Resources: []*config.Resource{
+					{
+						Path:     "/mount/*",
+						Methods:  []string{"GET"},
+						Provider: "provider1",
+						Basic: &config.ResourceBasic{
+							Credentials: []*config.BasicAuthUserConfig{
+								{
+									User: "user1",
+									Password: &config.CredentialConfig{
+										Value: "pass1",
+									},
+								},
+							},
+						},
+					},
+				},
+				Actions: &config.ActionsConfig{
+					GET: &config.GetActionConfig{
+						Enabled: true,
+						Config: &config.GetActionConfigConfig{
+							PresignedURLRedirect: &config.PresignedURLRedirectConfig{
+								Enabled: true,
+								Expiry:  time.Hour,
+								ResponseHeaders: &config.PresignedURLResponseHeadersConfig{
+									ContentDisposition: "attachment",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	// Authentication must still be checked before redirect
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost/mount/folder1/test.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "", w.Header().Get("Location"))
+
+	// Authenticated request must be redirected
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder1/test.txt", nil)
+	assert.NoError(t, err)
+	req.SetBasicAuth("user1", "pass1")
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusFound, w.Code)
+
+	location := w.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, s3server.URL+"/test-bucket/folder1/test.txt?"), location)
+	assert.Contains(t, location, "X-Amz-Expires=3600")
+	assert.Contains(t, location, "response-content-disposition=attachment")
+
+	// Presigned URL must give file content
+	res, err := http.Get(location)
+	assert.NoError(t, err)
+
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Hello folder1!", string(body))
+}
+
+func TestPresignedUpload(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name: "target1",
+				Bucket: &config.BucketConfig{
+					Name:       bucket,
+					Region:     region,
+					S3Endpoint: s3server.URL,
+					Credentials: &config.BucketCredentialConfig{
+						AccessKey: &config.CredentialConfig{Value: accessKey},
+						SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+					},
+					DisableSSL: true,
+				},
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET: &config.GetActionConfig{Enabled: true},
+					PUT: &config.PutActionConfig{
+						Enabled: true,
+						Config: &config.PutActionConfigConfig{
+							Metadata: map[string]string{"meta1": "value1"},
+							MaxSize:  100,
+							PresignedUpload: &config.PresignedUploadConfig{
+								Enabled: true,
+								Expiry:  time.Hour,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	// Unsupported method must be refused
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "http://localhost/mount/folder3/put.txt?presigned-upload=get", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Existing file must be refused because override isn't allowed
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("PUT", "http://localhost/mount/folder1/test.txt?presigned-upload=put&size=10", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Size must be declared because of maximum size
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("PUT", "http://localhost/mount/folder3/put.txt?presigned-upload=put", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Declared size must be valid
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("PUT", "http://localhost/mount/folder3/put.txt?presigned-upload=put&size=fake", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Declared size must respect maximum size
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("PUT", "http://localhost/mount/folder3/put.txt?presigned-upload=put&size=101", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Ask a presigned PUT URL
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("PUT", "http://localhost/mount/folder3/put.txt?presigned-upload=put&size=10&content-type=text/plain", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var putAnswer struct {
+		Method  string            `json:"method"`
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &putAnswer))
+	assert.Equal(t, "PUT", putAnswer.Method)
+	assert.True(t, strings.HasPrefix(putAnswer.URL, s3server.URL+"/test-bucket/folder3/put.txt?"), putAnswer.URL)
+	assert.Contains(t, putAnswer.URL, "X-Amz-Expires=3600")
+	assert.Equal(t, "value1", putAnswer.Headers["X-Amz-Meta-Meta1"])
+	// Declared content type and size are signed
+	assert.Equal(t, "text/plain", putAnswer.Headers["Content-Type"])
+	assert.Equal(t, "10", putAnswer.Headers["Content-Length"])
+
+	// Upload file directly on S3 with presigned URL
+	req, err = http.NewRequest("PUT", putAnswer.URL, strings.NewReader("Hello put!"))
+	assert.NoError(t, err)
+	for k, v := range putAnswer.Headers {
+		req.Header.Set(k, v)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// Ask a presigned POST form
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("PUT", "http://localhost/mount/folder3/post.txt?presigned-upload=post&size=11", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var postAnswer struct {
+		Method string            `json:"method"`
+		URL    string            `json:"url"`
+		Fields map[string]string `json:"fields"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &postAnswer))
+	assert.Equal(t, "POST", postAnswer.Method)
+	assert.Equal(t, s3server.URL+"/test-bucket", postAnswer.URL)
+	assert.Equal(t, "folder3/post.txt", postAnswer.Fields["key"])
+	assert.NotEmpty(t, postAnswer.Fields["policy"])
+	assert.NotEmpty(t, postAnswer.Fields["x-amz-signature"])
+
+	// Upload file directly on S3 with presigned form
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for k, v := range postAnswer.Fields {
+		assert.NoError(t, writer.WriteField(k, v))
+	}
+	part, err := writer.CreateFormFile("file", "post.txt")
+	assert.NoError(t, err)
+	_, err = io.WriteString(part, "Hello post!")
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	res, err = http.Post(postAnswer.URL, writer.FormDataContentType(), body)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// Uploaded files must be available through proxy
+	for p, content := range map[string]string{"/mount/folder3/put.txt": "Hello put!", "/mount/folder3/post.txt": "Hello post!"} {
+		w = httptest.NewRecorder()
+		req, err = http.NewRequest("GET", "http://localhost"+p, nil)
+		assert.NoError(t, err)
+		got.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, content, w.Body.String())
+	}
+}
+
+func TestArchiveDownload(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	newTarget := func(name, mountPath string, archiveCfg *config.ArchiveConfig) *config.TargetConfig {
+		return &config.TargetConfig{
+			Name: name,
+			Bucket: &config.BucketConfig{
+				Name:       bucket,
+				Region:     region,
+				S3Endpoint: s3server.URL,
+				Credentials: &config.BucketCredentialConfig{
+					AccessKey: &config.CredentialConfig{Value: accessKey},
+					SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+				},
+				DisableSSL: true,
+			},
+			Mount: &config.MountConfig{
+				Path: []string{mountPath},
+			},
+			Actions: &config.ActionsConfig{
+				GET: &config.GetActionConfig{
+					Enabled: true,
+					Config:  &config.GetActionConfigConfig{Archive: archiveCfg},
+				},
+			},
+		}
+	}
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			newTarget("target1", "/mount/", &config.ArchiveConfig{Enabled: true}),
+			newTarget("target2", "/limited/", &config.ArchiveConfig{Enabled: true, MaxObjects: 1}),
+			newTarget("target3", "/disabled/", nil),
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	expectedFiles := map[string]string{
+		"index.html": "<!DOCTYPE html><html><body><h1>Hello folder1!</h1></body></html>",
+		"test.txt":   "Hello folder1!",
+	}
+
+	// ZIP archive
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost/mount/folder1/?archive=zip", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="folder1.zip"`, w.Header().Get("Content-Disposition"))
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.NoError(t, err)
+	zipFiles := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		b, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		r.Close()
+		zipFiles[f.Name] = string(b)
+	}
+	assert.Equal(t, expectedFiles, zipFiles)
+
+	// TAR GZ archive
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder1/?archive=tar.gz", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="folder1.tar.gz"`, w.Header().Get("Content-Disposition"))
+
+	gr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+	tarFiles := map[string]string{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		b, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		tarFiles[h.Name] = string(b)
+	}
+	assert.Equal(t, expectedFiles, tarFiles)
+
+	// Errors
+	tests := []struct {
+		name         string
+		inputURL     string
+		expectedCode int
+	}{
+		{name: "unsupported format", inputURL: "http://localhost/mount/folder1/?archive=rar", expectedCode: http.StatusBadRequest},
+		{name: "file path", inputURL: "http://localhost/mount/folder1/test.txt?archive=zip", expectedCode: http.StatusBadRequest},
+		{name: "not found folder", inputURL: "http://localhost/mount/not-found/?archive=zip", expectedCode: http.StatusNotFound},
+		{name: "too many objects", inputURL: "http://localhost/limited/folder1/?archive=zip", expectedCode: http.StatusBadRequest},
+		{name: "archive disabled", inputURL: "http://localhost/disabled/folder1/?archive=zip", expectedCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.inputURL, nil)
+			assert.NoError(t, err)
+			got.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestAssumeRole(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Create web identity token file
+	tokenDir, err := ioutil.TempDir("", "s3-proxy-sts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tokenDir)
+	tokenFile := filepath.Join(tokenDir, "token")
+	assert.NoError(t, ioutil.WriteFile(tokenFile, []byte("web-identity-token"), 0600))
+
+	// Create STS stub server
+	stsRequests := []url.Values{}
+	stsMutex := &sync.Mutex{}
+	stsServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.NoError(t, req.ParseForm())
+		stsMutex.Lock()
+		stsRequests = append(stsRequests, req.PostForm)
+		stsMutex.Unlock()
+		action := req.PostForm.Get("Action")
+		rw.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(rw, `<%[1]sResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <%[1]sResult>
+    <Credentials>
+      <AccessKeyId>ASSUMEDACCESSKEY</AccessKeyId>
+      <SecretAccessKey>ASSUMEDSECRETKEY</SecretAccessKey>
+      <SessionToken>ASSUMEDSESSIONTOKEN</SessionToken>
+      <Expiration>%[2]s</Expiration>
+    </Credentials>
+  </%[1]sResult>
+  <ResponseMetadata>
+    <RequestId>request-id</RequestId>
+  </ResponseMetadata>
+</%[1]sResponse>`, action, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	defer stsServer.Close()
+
+	newTarget := func(name string, assumeRoleCfg *config.AssumeRoleConfig) *config.TargetConfig {
+		return &config.TargetConfig{
+			Name: name,
+			Bucket: &config.BucketConfig{
+				Name:        bucket,
+				Region:      region,
+				S3Endpoint:  s3server.URL,
+				STSEndpoint: stsServer.URL,
+				Credentials: &config.BucketCredentialConfig{
+					AccessKey:  &config.CredentialConfig{Value: accessKey},
+					SecretKey:  &config.CredentialConfig{Value: secretAccessKey},
+					AssumeRole: assumeRoleCfg,
+				},
+				DisableSSL: true,
+			},
+			Mount: &config.MountConfig{
+				Path: []string{"/" + name + "/"},
+			},
+			Actions: &config.ActionsConfig{
+				GET: &config.GetActionConfig{Enabled: true},
+			},
+		}
+	}
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			newTarget("assume", &config.AssumeRoleConfig{
+				RoleARN:     "arn:aws:iam::123456789012:role/assume",
+				ExternalID:  "external-id",
+				SessionName: "session",
+				Duration:    time.Hour,
+			}),
+			newTarget("web", &config.AssumeRoleConfig{
+				RoleARN:              "arn:aws:iam::123456789012:role/web",
+				WebIdentityTokenFile: tokenFile,
+			}),
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	// Files must be available through both targets with assumed role credentials
+	for _, p := range []string{"/assume/folder1/test.txt", "/web/folder1/test.txt"} {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost"+p, nil)
+		assert.NoError(t, err)
+		got.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Hello folder1!", w.Body.String())
+	}
+
+	// Check STS calls
+	if !assert.Len(t, stsRequests, 2) {
+		return
+	}
+	assert.Equal(t, "AssumeRole", stsRequests[0].Get("Action"))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/assume", stsRequests[0].Get("RoleArn"))
+	assert.Equal(t, "external-id", stsRequests[0].Get("ExternalId"))
+	assert.Equal(t, "session", stsRequests[0].Get("RoleSessionName"))
+	assert.Equal(t, "3600", stsRequests[0].Get("DurationSeconds"))
+	assert.Equal(t, "AssumeRoleWithWebIdentity", stsRequests[1].Get("Action"))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/web", stsRequests[1].Get("RoleArn"))
+	assert.Equal(t, config.DefaultAssumeRoleSessionName, stsRequests[1].Get("RoleSessionName"))
+	assert.Equal(t, "web-identity-token", stsRequests[1].Get("WebIdentityToken"))
+}
+
+func TestFileVersions(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Enable versioning and create versions directly on S3
+	s3Client := s3.New(session.New(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+		Endpoint:         aws.String(s3server.URL),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	_, err = s3Client.PutBucketVersioning(&s3.PutBucketVersioningInput{
+		Bucket:                  aws.String(bucket),
+		VersioningConfiguration: &s3.VersioningConfiguration{Status: aws.String(s3.BucketVersioningStatusEnabled)},
+	})
+	assert.NoError(t, err)
+	versionIDs := make([]string, 0, 2)
+	for _, content := range []string{"Version 1", "Version 2"} {
+		out, err := s3Client.PutObject(&s3.PutObjectInput{
+			Body:   strings.NewReader(content),
+			Bucket: aws.String(bucket),
+			Key:    aws.String("folder3/file.txt"),
+		})
+		assert.NoError(t, err)
+		versionIDs = append(versionIDs, aws.StringValue(out.VersionId))
+	}
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			FileVersions:        "../../../templates/file-versions.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name: "target1",
+				Bucket: &config.BucketConfig{
+					Name:       bucket,
+					Region:     region,
+					S3Endpoint: s3server.URL,
+					Credentials: &config.BucketCredentialConfig{
+						AccessKey: &config.CredentialConfig{Value: accessKey},
+						SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+					},
+					DisableSSL: true,
+				},
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET:    &config.GetActionConfig{Enabled: true},
+					DELETE: &config.DeleteActionConfig{Enabled: true},
+				},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	// Old version must be available
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost/mount/folder3/file.txt?versionId="+url.QueryEscape(versionIDs[0]), nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Version 1", w.Body.String())
+
+	// Current version is answered without version
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder3/file.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Version 2", w.Body.String())
+
+	// Versions can't be asked on folders
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder3/?versions", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Delete old version
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("DELETE", "http://localhost/mount/folder3/file.txt?versionId="+url.QueryEscape(versionIDs[0]), nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// Current version is still available
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder3/file.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Version 2", w.Body.String())
+
+	// List versions in JSON
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder3/file.txt?versions&format=json", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var versionsAnswer struct {
+		Path     string `json:"path"`
+		Versions []struct {
+			VersionID    string `json:"versionId"`
+			Size         int64  `json:"size"`
+			IsLatest     bool   `json:"isLatest"`
+			DeleteMarker bool   `json:"deleteMarker"`
+			Path         string `json:"path"`
+		} `json:"versions"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &versionsAnswer))
+	assert.Equal(t, "/mount/folder3/file.txt", versionsAnswer.Path)
+	if assert.Len(t, versionsAnswer.Versions, 1) {
+		assert.Equal(t, versionIDs[1], versionsAnswer.Versions[0].VersionID)
+		assert.Equal(t, int64(9), versionsAnswer.Versions[0].Size)
+		assert.True(t, versionsAnswer.Versions[0].IsLatest)
+		assert.False(t, versionsAnswer.Versions[0].DeleteMarker)
+		assert.Equal(t, "/mount/folder3/file.txt?versionId="+url.QueryEscape(versionIDs[1]), versionsAnswer.Versions[0].Path)
+	}
+
+	// List versions in HTML
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder3/file.txt?versions", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<h1>Versions of /mount/folder3/file.txt</h1>")
+	assert.Contains(t, w.Body.String(), versionIDs[1])
+}
+
+func TestKeyRewrite(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name: "target1",
+				Bucket: &config.BucketConfig{
+					Name:       bucket,
+					Region:     region,
+					S3Endpoint: s3server.URL,
+					Credentials: &config.BucketCredentialConfig{
+						AccessKey: &config.CredentialConfig{Value: accessKey},
+						SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+					},
+					DisableSSL: true,
+				},
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET: &config.GetActionConfig{Enabled: true},
+					PUT: &config.PutActionConfig{Enabled: true},
+				},
+				// Legacy /v1/{project}/{file} scheme is mapped on {project}/{file} keys
+				KeyRewriteList: []*config.KeyRewriteConfig{
+					{
+						SourceRegexp:        regexp.MustCompile("^/v1/(?P<project>[^/]+)/(?P<file>.*)$"),
+						Target:              "/${project}/${file}",
+						ReverseSourceRegexp: regexp.MustCompile("^/([^/]+)/(.*)$"),
+						ReverseTarget:       "/v1/$1/$2",
+					},
+				},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	// Get file with legacy path
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost/mount/v1/folder1/test.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Hello folder1!", w.Body.String())
+
+	// List folder with legacy path
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/v1/folder1/?format=json", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var listAnswer struct {
+		Entries []struct {
+			Name string `json:"name"`
+			Path string `json:"path"`
+		} `json:"entries"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listAnswer))
+	paths := map[string]string{}
+	for _, entry := range listAnswer.Entries {
+		paths[entry.Name] = entry.Path
+	}
+	assert.Equal(t, map[string]string{
+		"index.html": "/mount/v1/folder1/index.html",
+		"test.txt":   "/mount/v1/folder1/test.txt",
+	}, paths)
+
+	// Upload file with legacy path
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("PUT", "http://localhost/mount/v1/folder4/new.txt", strings.NewReader("new file"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// Check that object is stored with rewritten key
+	s3Client := s3.New(session.New(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+		Endpoint:         aws.String(s3server.URL),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	_, err = s3Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("folder4/new.txt"),
+	})
+	assert.NoError(t, err)
+
+	// Path not matching any rule is kept
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder1/test.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWebsite(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Create website objects directly on S3
+	s3Client := s3.New(session.New(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+		Endpoint:         aws.String(s3server.URL),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	objects := []*s3.PutObjectInput{
+		{Key: aws.String("site/index.html"), Body: strings.NewReader("app")},
+		{Key: aws.String("site/404.html"), Body: strings.NewReader("root error")},
+		{Key: aws.String("site/docs/404.html"), Body: strings.NewReader("docs error")},
+		{Key: aws.String("site/old.html"), Body: strings.NewReader(""), WebsiteRedirectLocation: aws.String("/new.html")},
+	}
+	for _, obj := range objects {
+		obj.Bucket = aws.String(bucket)
+		_, err = s3Client.PutObject(obj)
+		assert.NoError(t, err)
+	}
+
+	newTarget := func(name, mountPath string, websiteCfg *config.WebsiteConfig) *config.TargetConfig {
+		return &config.TargetConfig{
+			Name: name,
+			Bucket: &config.BucketConfig{
+				Name:       bucket,
+				Prefix:     "site/",
+				Region:     region,
+				S3Endpoint: s3server.URL,
+				Credentials: &config.BucketCredentialConfig{
+					AccessKey: &config.CredentialConfig{Value: accessKey},
+					SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+				},
+				DisableSSL: true,
+			},
+			Mount: &config.MountConfig{
+				Path: []string{mountPath},
+			},
+			IndexDocument: "index.html",
+			Actions: &config.ActionsConfig{
+				GET: &config.GetActionConfig{
+					Enabled: true,
+					Config:  &config.GetActionConfigConfig{Website: websiteCfg},
+				},
+			},
+		}
+	}
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			newTarget("spa", "/spa/", &config.WebsiteConfig{Enabled: true, FallbackDocument: "/index.html"}),
+			newTarget("www", "/www/", &config.WebsiteConfig{Enabled: true, ErrorDocument: "404.html"}),
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		inputURL         string
+		expectedCode     int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{name: "index document", inputURL: "http://localhost/spa/", expectedCode: http.StatusOK, expectedBody: "app"},
+		{name: "fallback document", inputURL: "http://localhost/spa/app/route", expectedCode: http.StatusOK, expectedBody: "app"},
+		{name: "redirect location", inputURL: "http://localhost/spa/old.html", expectedCode: http.StatusMovedPermanently, expectedLocation: "/spa/new.html"},
+		{name: "nearest error document", inputURL: "http://localhost/www/docs/sub/not-found.html", expectedCode: http.StatusNotFound, expectedBody: "docs error"},
+		{name: "root error document", inputURL: "http://localhost/www/not-found/", expectedCode: http.StatusNotFound, expectedBody: "root error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tt.inputURL, nil)
+			assert.NoError(t, err)
+			got.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectedLocation, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name: "target1",
+				Bucket: &config.BucketConfig{
+					Name:       bucket,
+					Region:     region,
+					S3Endpoint: s3server.URL,
+					Credentials: &config.BucketCredentialConfig{
+						AccessKey: &config.CredentialConfig{Value: accessKey},
+						SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+					},
+					DisableSSL: true,
+				},
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET: &config.GetActionConfig{Enabled: true},
+				},
+				ResponseHeaders: []*config.ResponseHeadersConfig{
+					{
+						Path:     "/mount/*",
+						PathGlob: glob.MustCompile("/mount/*"),
+						Headers: map[string]string{
+							"strict-transport-security": "max-age=63072000",
+							"x-frame-options":           "DENY",
+						},
+					},
+					{
+						Path:           "/mount/folder1/*",
+						PathGlob:       glob.MustCompile("/mount/folder1/*"),
+						DisableNoCache: true,
+						Headers: map[string]string{
+							"cache-control": "public, max-age=31536000, immutable",
+						},
+						RemoveHeaders: []string{"X-Frame-Options"},
+					},
+				},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	// NoCache is kept on paths without rule disabling it
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "http://localhost/mount/folder2/index.html", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-cache, no-store, no-transform, must-revalidate, private, max-age=0", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "no-cache", w.Header().Get("Pragma"))
+	assert.Equal(t, "max-age=63072000", w.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+
+	// NoCache is disabled and headers are overridden or removed by rules
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder1/test.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Hello folder1!", w.Body.String())
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "", w.Header().Get("Pragma"))
+	assert.Equal(t, "", w.Header().Get("Expires"))
+	assert.Equal(t, "max-age=63072000", w.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "", w.Header().Get("X-Frame-Options"))
+
+	// Rules are applied on errors
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "http://localhost/mount/folder1/not-found.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+}
+
+func TestPrecompressed(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// Create precompressed objects directly on S3
+	s3Client := s3.New(session.New(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+		Endpoint:         aws.String(s3server.URL),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	for key, content := range map[string]string{
+		"assets/app.css":    "original",
+		"assets/app.css.gz": "gzip variant",
+		"assets/app.css.br": "br variant",
+	} {
+		_, err = s3Client.PutObject(&s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+			Body:   strings.NewReader(content),
+		})
+		assert.NoError(t, err)
+	}
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name: "target1",
+				Bucket: &config.BucketConfig{
+					Name:       bucket,
+					Region:     region,
+					S3Endpoint: s3server.URL,
+					Credentials: &config.BucketCredentialConfig{
+						AccessKey: &config.CredentialConfig{Value: accessKey},
+						SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+					},
+					DisableSSL: true,
+				},
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET: &config.GetActionConfig{
+						Enabled: true,
+						Config: &config.GetActionConfigConfig{
+							Precompressed: &config.PrecompressedConfig{Enabled: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name                    string
+		acceptEncoding          string
+		expectedBody            string
+		expectedContentEncoding string
+		expectedContentType     string
+	}{
+		{
+			name:                    "brotli variant",
+			acceptEncoding:          "gzip, deflate, br",
+			expectedBody:            "br variant",
+			expectedContentEncoding: "br",
+			expectedContentType:     "text/css; charset=utf-8",
+		},
+		{
+			name:                    "gzip variant",
+			acceptEncoding:          "gzip, deflate",
+			expectedBody:            "gzip variant",
+			expectedContentEncoding: "gzip",
+			expectedContentType:     "text/css; charset=utf-8",
+		},
+		{
+			name:                "original object",
+			acceptEncoding:      "",
+			expectedBody:        "original",
+			expectedContentType: "text/css; charset=utf-8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "http://localhost/mount/assets/app.css", nil)
+			assert.NoError(t, err)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			got.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectedContentEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		})
+	}
+}
+
+func TestObjectCache(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cacheDir, err := ioutil.TempDir("", "s3-proxy-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name: "target1",
+				Bucket: &config.BucketConfig{
+					Name:       bucket,
+					Region:     region,
+					S3Endpoint: s3server.URL,
+					Credentials: &config.BucketCredentialConfig{
+						AccessKey: &config.CredentialConfig{Value: accessKey},
+						SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+					},
+					DisableSSL: true,
+				},
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET:    &config.GetActionConfig{Enabled: true},
+					PUT:    &config.PutActionConfig{Enabled: true, Config: &config.PutActionConfigConfig{AllowOverride: true}},
+					DELETE: &config.DeleteActionConfig{Enabled: true},
+				},
+				ObjectCache: &config.ObjectCacheConfig{Enabled: true, Directory: cacheDir, TTL: time.Hour},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	s3Client := s3.New(session.New(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+		Endpoint:         aws.String(s3server.URL),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	// get will answer with status code and body of a GET request on file
+	get := func() (int, string) {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost/mount/folder1/test.txt", nil)
+		assert.NoError(t, err)
+		got.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	// Object is downloaded and stored in cache
+	code, body := get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Hello folder1!", body)
+
+	// Object changed directly on S3 is answered from cache until TTL
+	_, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("folder1/test.txt"),
+		Body:   strings.NewReader("Changed on S3"),
+	})
+	assert.NoError(t, err)
+	code, body = get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Hello folder1!", body)
+
+	// Object uploaded through proxy invalidates cache
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "http://localhost/mount/folder1/test.txt", strings.NewReader("Uploaded"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	code, body = get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Uploaded", body)
+
+	// Object deleted through proxy invalidates cache
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("DELETE", "http://localhost/mount/folder1/test.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	code, _ = get()
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestListingCache(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name: "target1",
+				Bucket: &config.BucketConfig{
+					Name:       bucket,
+					Region:     region,
+					S3Endpoint: s3server.URL,
+					Credentials: &config.BucketCredentialConfig{
+						AccessKey: &config.CredentialConfig{Value: accessKey},
+						SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+					},
+					DisableSSL: true,
+				},
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET:    &config.GetActionConfig{Enabled: true},
+					PUT:    &config.PutActionConfig{Enabled: true, Config: &config.PutActionConfigConfig{AllowOverride: true}},
+					DELETE: &config.DeleteActionConfig{Enabled: true},
+				},
+				ListingCache: &config.ListingCacheConfig{Enabled: true, TTL: time.Hour},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	s3Client := s3.New(session.New(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+		Endpoint:         aws.String(s3server.URL),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	// list will answer with file names of the JSON listing of folder1
+	list := func() []string {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost/mount/folder1/?format=json", nil)
+		assert.NoError(t, err)
+		got.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var listAnswer struct {
+			Entries []struct {
+				Name string `json:"name"`
+			} `json:"entries"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listAnswer))
+		names := []string{}
+		for _, entry := range listAnswer.Entries {
+			names = append(names, entry.Name)
+		}
+		return names
+	}
+
+	// Listing is done on S3 and stored in cache
+	assert.Equal(t, []string{"index.html", "test.txt"}, list())
+
+	// Object added directly on S3 isn't listed until TTL
+	_, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("folder1/direct.txt"),
+		Body:   strings.NewReader("direct"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"index.html", "test.txt"}, list())
+
+	// Object uploaded through proxy invalidates listing
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "http://localhost/mount/folder1/uploaded.txt", strings.NewReader("uploaded"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []string{"direct.txt", "index.html", "test.txt", "uploaded.txt"}, list())
+
+	// Object deleted through proxy invalidates listing
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest("DELETE", "http://localhost/mount/folder1/direct.txt", nil)
+	assert.NoError(t, err)
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []string{"index.html", "test.txt", "uploaded.txt"}, list())
+}
+
+func TestReadFailover(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	s3server2, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server2.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// newBucketConfig will create a bucket configuration for a fake S3 server
+	newBucketConfig := func(endpoint string) *config.BucketConfig {
+		return &config.BucketConfig{
+			Name:       bucket,
+			Region:     region,
+			S3Endpoint: endpoint,
+			Credentials: &config.BucketCredentialConfig{
+				AccessKey: &config.CredentialConfig{Value: accessKey},
+				SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+			},
+			DisableSSL: true,
+		}
+	}
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name:   "target1",
+				Bucket: newBucketConfig(s3server.URL),
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET: &config.GetActionConfig{Enabled: true},
+					PUT: &config.PutActionConfig{Enabled: true},
+				},
+				ReadFailover: &config.ReadFailoverConfig{
+					Enabled:    true,
+					Buckets:    []*config.BucketConfig{newBucketConfig(s3server2.URL)},
+					OnNotFound: true,
+					Timeout:    time.Second,
+				},
+			},
+		},
+	}
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	// Add an object on secondary bucket only
+	s3Client := s3.New(session.New(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+		Endpoint:         aws.String(s3server2.URL),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	_, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("folder1/secondary.txt"),
+		Body:   strings.NewReader("Hello secondary!"),
+	})
+	assert.NoError(t, err)
+	// get will get an object through proxy
+	get := func(path string) (int, string) {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "http://localhost"+path, nil)
+		assert.NoError(t, err)
+		got.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	// Object not found on primary bucket is read on secondary bucket
+	code, body := get("/mount/folder1/secondary.txt")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Hello secondary!", body)
+
+	// Writes are done on primary bucket only
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "http://localhost/mount/folder1/primary.txt", strings.NewReader("Hello primary!"))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	got.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	_, err = s3Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("folder1/primary.txt"),
+	})
+	assert.Error(t, err)
+
+	// Reads fail over on secondary bucket when primary bucket is down
+	s3server.Close()
+	code, body = get("/mount/folder1/test.txt")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Hello folder1!", body)
+	code, body = get("/mount/folder1/primary.txt")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Contains(t, body, "Not Found")
+}
+
+func TestWriteMirror(t *testing.T) {
+	accessKey := "YOUR-ACCESSKEYID"
+	secretAccessKey := "YOUR-SECRETACCESSKEY"
+	region := "eu-central-1"
+	bucket := "test-bucket"
+	s3server, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	s3server2, err := setupFakeS3(
+		accessKey,
+		secretAccessKey,
+		region,
+		bucket,
+	)
+	defer s3server2.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// newBucketConfig will create a bucket configuration for a fake S3 server
+	newBucketConfig := func(endpoint string) *config.BucketConfig {
+		return &config.BucketConfig{
+			Name:       bucket,
+			Region:     region,
+			S3Endpoint: endpoint,
+			Credentials: &config.BucketCredentialConfig{
+				AccessKey: &config.CredentialConfig{Value: accessKey},
+				SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+			},
+			DisableSSL: true,
+		}
+	}
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name:   "target1",
+				Bucket: newBucketConfig(s3server.URL),
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET:    &config.GetActionConfig{Enabled: true},
+					PUT:    &config.PutActionConfig{Enabled: true, Config: &config.PutActionConfigConfig{AllowOverride: true}},
+					DELETE: &config.DeleteActionConfig{Enabled: true},
+				},
+				WriteMirror: &config.WriteMirrorConfig{
+					Enabled: true,
+					Buckets: []*config.BucketConfig{newBucketConfig(s3server2.URL)},
+					Policy:  config.WriteMirrorPolicyAll,
+				},
+			},
+		},
+	}
 
-			if tt.notExpectedBody != "" {
-				body := w.Body.String()
-				if tt.notExpectedBody == body {
-					t.Errorf("Integration test on GenerateRouter() body = \"%v\", not expected body \"%v\"", body, tt.notExpectedBody)
-				}
-			}
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
 
-			if tt.expectedHeaders != nil {
-				for key, val := range tt.expectedHeaders {
-					wheader := w.HeaderMap.Get(key)
-					if val != wheader {
-						t.Errorf("Integration test on GenerateRouter() header %s = %v, expected %v", key, wheader, val)
-					}
-				}
-			}
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
 
-			if tt.expectedCode != w.Code {
-				t.Errorf("Integration test on GenerateRouter() status code = %v, expected status code %v", w.Code, tt.expectedCode)
-			}
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
+	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+
+	// getObject will get object content in a fake S3 server
+	getObject := func(endpoint, key string) (string, error) {
+		s3Client := s3.New(session.New(&aws.Config{
+			Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+			Endpoint:         aws.String(endpoint),
+			Region:           aws.String(region),
+			DisableSSL:       aws.Bool(true),
+			S3ForcePathStyle: aws.Bool(true),
+		}))
+		obj, err := s3Client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
 		})
+		if err != nil {
+			return "", err
+		}
+		defer obj.Body.Close()
+		b, err := ioutil.ReadAll(obj.Body)
+		return string(b), err
+	}
+	// do will send a request through proxy
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(method, "http://localhost"+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "text/plain")
+		got.ServeHTTP(w, req)
+		return w
 	}
+
+	// Upload is done on all buckets
+	w := do("PUT", "/mount/folder1/mirrored.txt", "Hello mirror!")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	content, err := getObject(s3server.URL, "folder1/mirrored.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello mirror!", content)
+	content, err = getObject(s3server2.URL, "folder1/mirrored.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello mirror!", content)
+
+	// Deletion is done on all buckets
+	w = do("DELETE", "/mount/folder1/mirrored.txt", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	_, err = getObject(s3server.URL, "folder1/mirrored.txt")
+	assert.Error(t, err)
+	_, err = getObject(s3server2.URL, "folder1/mirrored.txt")
+	assert.Error(t, err)
+
+	// Partial failure is reported and target bucket keeps object
+	s3server2.Close()
+	w = do("PUT", "/mount/folder1/partial.txt", "Hello partial!")
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "put-object of key folder1/partial.txt succeeded on target bucket but failed on mirror buckets (bucket test-bucket: ")
+	content, err = getObject(s3server.URL, "folder1/partial.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello partial!", content)
 }
 
-func TestTracing(t *testing.T) {
-	// trueValue := true
+func TestHeadSameAsGet(t *testing.T) {
 	accessKey := "YOUR-ACCESSKEYID"
 	secretAccessKey := "YOUR-SECRETACCESSKEY"
 	region := "eu-central-1"
@@ -1964,204 +5870,236 @@ func TestTracing(t *testing.T) {
 		return
 	}
 
-	tplConfig := &config.TemplateConfig{
-		FolderList:          "../../../templates/folder-list.tpl",
-		TargetList:          "../../../templates/target-list.tpl",
-		NotFound:            "../../../templates/not-found.tpl",
-		Forbidden:           "../../../templates/forbidden.tpl",
-		BadRequest:          "../../../templates/bad-request.tpl",
-		InternalServerError: "../../../templates/internal-server-error.tpl",
-		Unauthorized:        "../../../templates/unauthorized.tpl",
+	// Create precompressed object directly on S3
+	s3Client := s3.New(session.New(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretAccessKey, ""),
+		Endpoint:         aws.String(s3server.URL),
+		Region:           aws.String(region),
+		DisableSSL:       aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	_, err = s3Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("folder1/test.txt.gz"),
+		Body:   strings.NewReader("gzip variant"),
+	})
+	assert.NoError(t, err)
+
+	bucketCfg := &config.BucketConfig{
+		Name:       bucket,
+		Region:     region,
+		S3Endpoint: s3server.URL,
+		Credentials: &config.BucketCredentialConfig{
+			AccessKey: &config.CredentialConfig{Value: accessKey},
+			SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+		},
+		DisableSSL: true,
 	}
-	targetsCfg := []*config.TargetConfig{
-		{
-			Name: "target1",
-			Bucket: &config.BucketConfig{
-				Name:       bucket,
-				Region:     region,
-				S3Endpoint: s3server.URL,
-				Credentials: &config.BucketCredentialConfig{
-					AccessKey: &config.CredentialConfig{Value: accessKey},
-					SecretKey: &config.CredentialConfig{Value: secretAccessKey},
+	cfg := &config.Config{
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name:   "target1",
+				Bucket: bucketCfg,
+				Mount: &config.MountConfig{
+					Path: []string{"/mount/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET: &config.GetActionConfig{Enabled: true},
 				},
-				DisableSSL: true,
-			},
-			Mount: &config.MountConfig{
-				Path: []string{"/mount/"},
 			},
-			Actions: &config.ActionsConfig{
-				GET: &config.GetActionConfig{Enabled: true},
+			{
+				Name:          "target2",
+				Bucket:        bucketCfg,
+				IndexDocument: "index.html",
+				Mount: &config.MountConfig{
+					Path: []string{"/website/"},
+				},
+				Actions: &config.ActionsConfig{
+					GET: &config.GetActionConfig{
+						Enabled: true,
+						Config: &config.GetActionConfigConfig{
+							Website:       &config.WebsiteConfig{Enabled: true, FallbackDocument: "folder1/index.html"},
+							Precompressed: &config.PrecompressedConfig{Enabled: true, Encodings: []string{"gzip"}},
+						},
+					},
+				},
 			},
 		},
 	}
-	type args struct {
-		cfg *config.Config
+
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
+
+	// Load configuration in manager
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := &Server{
+		logger:     logger,
+		cfgManager: cfgManagerMock,
+		metricsCl:  metricsCtx,
+		tracingSvc: tsvc,
 	}
+	got, err := svr.generateRouter()
+	assert.NoError(t, err)
+	// Use a real server to get headers computed by HTTP server (like Content-Length of folder listings)
+	ts := httptest.NewServer(got)
+	defer ts.Close()
+
 	tests := []struct {
-		name               string
-		args               args
-		inputMethod        string
-		inputURL           string
-		inputBasicUser     string
-		inputBasicPassword string
-		inputBody          string
-		inputFileName      string
-		inputFileKey       string
-		expectedCode       int
-		expectedBody       string
-		expectedHeaders    map[string]string
-		notExpectedBody    string
-		wantErr            bool
+		name         string
+		inputURL     string
+		inputHeaders map[string]string
+		expectedCode int
 	}{
+		{name: "file", inputURL: "/mount/folder1/test.txt", expectedCode: http.StatusOK},
+		{name: "not found file", inputURL: "/mount/folder1/not-found.txt", expectedCode: http.StatusNotFound},
+		{name: "folder listing", inputURL: "/mount/folder1/", expectedCode: http.StatusOK},
 		{
-			name: "GET a not found path without any tracing configuration",
-			args: args{
-				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{},
-					Tracing:     &config.TracingConfig{},
-					Templates:   tplConfig,
-					Targets:     targetsCfg,
-				},
-			},
-			inputMethod:  "GET",
-			inputURL:     "http://localhost/not-found/",
-			expectedCode: 404,
-			expectedBody: "404 page not found\n",
-			expectedHeaders: map[string]string{
-				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/plain; charset=utf-8",
-			},
+			name:         "file not modified",
+			inputURL:     "/mount/folder1/test.txt",
+			inputHeaders: map[string]string{"If-None-Match": "\"c3e030a544fde7d10ea1aa8929354661\""},
+			expectedCode: http.StatusNotModified,
 		},
 		{
-			name: "GET a not found path with a tracing configuration",
-			args: args{
-				cfg: &config.Config{
-					ListTargets: &config.ListTargetsConfig{},
-					Tracing: &config.TracingConfig{
-						Enabled:       true,
-						UDPHost:       "localhost:6831",
-						FlushInterval: "120s",
-					},
-					Templates: tplConfig,
-					Targets:   targetsCfg,
-				},
-			},
-			inputMethod:  "GET",
-			inputURL:     "http://localhost/not-found/",
-			expectedCode: 404,
-			expectedBody: "404 page not found\n",
-			expectedHeaders: map[string]string{
-				"Cache-Control": "no-cache, no-store, no-transform, must-revalidate, private, max-age=0",
-				"Content-Type":  "text/plain; charset=utf-8",
-			},
+			name:         "file with a precondition failed error",
+			inputURL:     "/mount/folder1/test.txt",
+			inputHeaders: map[string]string{"If-Match": "\"other\""},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{name: "website index document", inputURL: "/website/folder1/", expectedCode: http.StatusOK},
+		{name: "website fallback document", inputURL: "/website/app/route", expectedCode: http.StatusOK},
+		{
+			name:         "website precompressed variant",
+			inputURL:     "/website/folder1/test.txt",
+			inputHeaders: map[string]string{"Accept-Encoding": "gzip"},
+			expectedCode: http.StatusOK,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create go mock controller
-			ctrl := gomock.NewController(t)
-			cfgManagerMock := cmocks.NewMockManager(ctrl)
-
-			// Load configuration in manager
-			cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(tt.args.cfg)
-
-			logger := log.NewLogger()
-			// Create tracing service
-			tsvc, err := tracing.New(cfgManagerMock, logger)
-			assert.NoError(t, err)
-
-			svr := &Server{
-				logger:     logger,
-				cfgManager: cfgManagerMock,
-				metricsCl:  metricsCtx,
-				tracingSvc: tsvc,
-			}
-			got, err := svr.generateRouter()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GenerateRouter() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			// If want error at this moment => stop
-			if tt.wantErr {
-				return
-			}
-			w := httptest.NewRecorder()
-			req, err := http.NewRequest(
-				tt.inputMethod,
-				tt.inputURL,
-				nil,
-			)
-			if err != nil {
-				t.Error(err)
-				return
-			}
-			// multipart form
-			if tt.inputBody != "" {
-				body := &bytes.Buffer{}
-				writer := multipart.NewWriter(body)
-				part, err := writer.CreateFormFile(tt.inputFileKey, filepath.Base(tt.inputFileName))
-				if err != nil {
-					t.Error(err)
-					return
-				}
-				_, err = io.Copy(part, strings.NewReader(tt.inputBody))
-				if err != nil {
-					t.Error(err)
-					return
-				}
-				err = writer.Close()
-				if err != nil {
-					t.Error(err)
-					return
-				}
-				req, err = http.NewRequest(
-					tt.inputMethod,
-					tt.inputURL,
-					body,
-				)
-				if err != nil {
-					t.Error(err)
-					return
+			// do will do request with method and will answer with response without body
+			do := func(method string) (*http.Response, string) {
+				req, err := http.NewRequest(method, ts.URL+tt.inputURL, nil)
+				assert.NoError(t, err)
+				// Always set Accept-Encoding because HTTP client only add it for GET requests
+				// Content-Length of compressed responses cannot be known without body
+				req.Header.Set("Accept-Encoding", "identity")
+				for k, v := range tt.inputHeaders {
+					req.Header.Set(k, v)
 				}
-				req.Header.Set("Content-Type", writer.FormDataContentType())
+				res, err := http.DefaultClient.Do(req)
+				assert.NoError(t, err)
+				defer res.Body.Close()
+				body, err := ioutil.ReadAll(res.Body)
+				assert.NoError(t, err)
+				// Date is different between requests
+				// Last-Modified is the current time on fake S3 server
+				res.Header.Del("Date")
+				res.Header.Del("Last-Modified")
+				return res, string(body)
 			}
-			// Add basic auth
-			if tt.inputBasicUser != "" {
-				req.SetBasicAuth(tt.inputBasicUser, tt.inputBasicPassword)
+			getRes, getBody := do(http.MethodGet)
+			headRes, headBody := do(http.MethodHead)
+			// HTTP server only adds Content-Length of empty bodies on GET responses
+			if getBody == "" {
+				getRes.Header.Del("Content-Length")
 			}
-			got.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedCode, getRes.StatusCode)
+			assert.Equal(t, getRes.StatusCode, headRes.StatusCode)
+			assert.Equal(t, getRes.Header, headRes.Header)
+			assert.Equal(t, "", headBody)
+		})
+	}
+}
 
-			if tt.expectedBody != "" {
-				body := w.Body.String()
-				if tt.expectedBody != body {
-					t.Errorf("Integration test on GenerateRouter() body = \"%v\", expected body \"%v\"", body, tt.expectedBody)
-				}
-			}
+// This is in a separate test because this one will need a real server to discuss with OIDC server
+func TestShutdownDuringReload(t *testing.T) {
+	cfg := &config.Config{
+		Server:      &config.ServerConfig{ListenAddr: "", Port: 8080},
+		ListTargets: &config.ListTargetsConfig{},
+		Tracing:     &config.TracingConfig{},
+		Templates: &config.TemplateConfig{
+			FolderList:          "../../../templates/folder-list.tpl",
+			TargetList:          "../../../templates/target-list.tpl",
+			NotFound:            "../../../templates/not-found.tpl",
+			Forbidden:           "../../../templates/forbidden.tpl",
+			BadRequest:          "../../../templates/bad-request.tpl",
+			InternalServerError: "../../../templates/internal-server-error.tpl",
+			Unauthorized:        "../../../templates/unauthorized.tpl",
+		},
+		Targets: []*config.TargetConfig{
+			{
+				Name:    "target1",
+				Bucket:  &config.BucketConfig{Name: "bucket1", Region: "eu-central-1"},
+				Mount:   &config.MountConfig{Path: []string{"/mount/"}},
+				Actions: &config.ActionsConfig{GET: &config.GetActionConfig{Enabled: true}},
+			},
+		},
+	}
 
-			if tt.notExpectedBody != "" {
-				body := w.Body.String()
-				if tt.notExpectedBody == body {
-					t.Errorf("Integration test on GenerateRouter() body = \"%v\", not expected body \"%v\"", body, tt.notExpectedBody)
-				}
-			}
+	// Create go mock controller
+	ctrl := gomock.NewController(t)
+	cfgManagerMock := cmocks.NewMockManager(ctrl)
 
-			if tt.expectedHeaders != nil {
-				for key, val := range tt.expectedHeaders {
-					wheader := w.HeaderMap.Get(key)
-					if val != wheader {
-						t.Errorf("Integration test on GenerateRouter() header %s = %v, expected %v", key, wheader, val)
-					}
-				}
-			}
+	// Load configuration in manager and keep reload hook
+	var hook func()
 
-			if tt.expectedCode != w.Code {
-				t.Errorf("Integration test on GenerateRouter() status code = %v, expected status code %v", w.Code, tt.expectedCode)
-			}
-		})
-	}
+	cfgManagerMock.EXPECT().GetConfig().AnyTimes().Return(cfg)
+	cfgManagerMock.EXPECT().AddOnChangeHook(gomock.Any()).AnyTimes().Do(func(fn func()) { hook = fn })
+
+	logger := log.NewLogger()
+	// Create tracing service
+	tsvc, err := tracing.New(cfgManagerMock, logger)
+	assert.NoError(t, err)
+
+	svr := NewServer(logger, cfgManagerMock, metricsCtx, tsvc)
+	err = svr.GenerateServer()
+	assert.NoError(t, err)
+
+	// Reload configuration while server is shut down
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		hook()
+	}()
+
+	err = svr.Shutdown(context.TODO())
+	assert.NoError(t, err)
+	wg.Wait()
+
+	// S3 client manager isn't replaced anymore when server is stopped
+	svr.mutex.Lock()
+	s3clientManager := svr.s3clientManager
+	svr.mutex.Unlock()
+
+	hook()
+	assert.True(t, s3clientManager == svr.s3clientManager)
+	// S3 client manager isn't closed twice
+	err = svr.Shutdown(context.TODO())
+	assert.NoError(t, err)
 }
 
-// This is in a separate test because this one will need a real server to discuss with OIDC server
 func TestOIDCAuthentication(t *testing.T) {
 	// trueValue := true
 	accessKey := "YOUR-ACCESSKEYID"
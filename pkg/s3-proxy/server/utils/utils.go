@@ -2,8 +2,10 @@ package utils
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
+	"mime"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -94,6 +96,32 @@ func HandleBadRequest(logger log.Logger, rw http.ResponseWriter, tplCfg *config.
 	HandleBadRequestWithTemplate(logger, rw, tplCfg, "", requestPath, err)
 }
 
+// HandleRequestEntityTooLargeWithTemplate Handle request entity too large error following bad request template with given template in parameter
+// nolint:whitespace
+func HandleRequestEntityTooLargeWithTemplate(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+	err2 := TemplateExecution(tplCfg.BadRequest, tplString, logger, rw, struct {
+		Path  string
+		Error error
+	}{Path: requestPath, Error: err}, http.StatusRequestEntityTooLarge)
+	if err2 != nil {
+		logger.Error(err2)
+		HandleInternalServerError(logger, rw, tplCfg, requestPath, err2)
+	}
+}
+
+// HandleBadGatewayWithTemplate Handle bad gateway error following internal server error template with given template in parameter
+// nolint:whitespace
+func HandleBadGatewayWithTemplate(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string, err error) {
+	err2 := TemplateExecution(tplCfg.InternalServerError, tplString, logger, rw, struct {
+		Path  string
+		Error error
+	}{Path: requestPath, Error: err}, http.StatusBadGateway)
+	if err2 != nil {
+		logger.Error(err2)
+		HandleInternalServerError(logger, rw, tplCfg, requestPath, err2)
+	}
+}
+
 // HandleForbiddenWithTemplate Handle forbidden error following response template given in parameters
 func HandleForbiddenWithTemplate(logger log.Logger, rw http.ResponseWriter, tplCfg *config.TemplateConfig, tplString string, requestPath string) {
 	err := TemplateExecution(tplCfg.Forbidden, tplString, logger, rw, struct {
@@ -168,6 +196,60 @@ func TemplateExecution(tplPath, tplString string, logger log.Logger, rw http.Res
 	return nil
 }
 
+// JSONFormatQueryParam Query parameter value used to ask a JSON response
+const JSONFormatQueryParam = "json"
+
+// IsJSONRequested will check if request is asking a JSON response
+// with a "format=json" query parameter or an "Accept: application/json" header
+func IsJSONRequested(r *http.Request) bool {
+	// Check query parameter
+	if r.URL.Query().Get("format") == JSONFormatQueryParam {
+		return true
+	}
+	// Check accept header media types
+	for _, accept := range r.Header["Accept"] {
+		for _, mediaType := range strings.Split(accept, ",") {
+			// Remove parameters like quality
+			mediaType = strings.TrimSpace(strings.Split(mediaType, ";")[0])
+			if strings.EqualFold(mediaType, "application/json") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsMultipartFormRequest will check if request body is a multipart form
+func IsMultipartFormRequest(r *http.Request) bool {
+	// Parse content type header
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	// Check if error exists
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "multipart/form-data"
+}
+
+// JSONExecution will write data as a JSON response
+func JSONExecution(rw http.ResponseWriter, data interface{}, status int) error {
+	// Generate JSON content
+	content, err := json.Marshal(data)
+	// Check if error exists
+	if err != nil {
+		return err
+	}
+	// Set the header
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	// Set status code
+	rw.WriteHeader(status)
+	// Write content
+	_, err = rw.Write(content)
+
+	return err
+}
+
 func GetRequestURI(r *http.Request) string {
 	scheme := "http"
 	if r.TLS != nil {
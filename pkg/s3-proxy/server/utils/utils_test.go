@@ -680,6 +680,148 @@ func TestHandleBadRequest(t *testing.T) {
 	}
 }
 
+func TestHandleRequestEntityTooLargeWithTemplate(t *testing.T) {
+	headers := http.Header{}
+	headers.Add("Content-Type", "text/html; charset=utf-8")
+	type args struct {
+		tplString   string
+		rw          http.ResponseWriter
+		requestPath string
+		err         error
+		tplCfg      *config.TemplateConfig
+	}
+	tests := []struct {
+		name               string
+		args               args
+		expectedHTTPWriter *respWriterTest
+	}{
+		{
+			name: "Template should be ok",
+			args: args{
+				rw: &respWriterTest{
+					Headers: http.Header{},
+				},
+				requestPath: "/request1",
+				err:         errors.New("fake"),
+				tplCfg: &config.TemplateConfig{
+					InternalServerError: "../../../../templates/internal-server-error.tpl",
+					BadRequest:          "../../../../templates/bad-request.tpl",
+				},
+			},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: headers,
+				Status:  413,
+				Resp: []byte(`<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Bad Request</h1>
+    <p>fake</p>
+  </body>
+</html>
+`),
+			},
+		},
+		{
+			name: "Template string should be used",
+			args: args{
+				tplString: "{{ .Path }}: {{ .Error }}",
+				rw: &respWriterTest{
+					Headers: http.Header{},
+				},
+				requestPath: "/request1",
+				err:         errors.New("fake"),
+				tplCfg: &config.TemplateConfig{
+					InternalServerError: "../../../../templates/internal-server-error.tpl",
+					BadRequest:          "templates/bad-request.tpl",
+				},
+			},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: headers,
+				Status:  413,
+				Resp:    []byte("/request1: fake"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			HandleRequestEntityTooLargeWithTemplate(log.NewLogger(), tt.args.rw, tt.args.tplCfg, tt.args.tplString, tt.args.requestPath, tt.args.err)
+			if !reflect.DeepEqual(tt.expectedHTTPWriter, tt.args.rw) {
+				t.Errorf("HandleRequestEntityTooLargeWithTemplate() => httpWriter = %+v, want %+v", tt.args.rw, tt.expectedHTTPWriter)
+			}
+		})
+	}
+}
+
+func TestHandleBadGatewayWithTemplate(t *testing.T) {
+	headers := http.Header{}
+	headers.Add("Content-Type", "text/html; charset=utf-8")
+	type args struct {
+		tplString   string
+		rw          http.ResponseWriter
+		requestPath string
+		err         error
+		tplCfg      *config.TemplateConfig
+	}
+	tests := []struct {
+		name               string
+		args               args
+		expectedHTTPWriter *respWriterTest
+	}{
+		{
+			name: "Template should be ok",
+			args: args{
+				rw: &respWriterTest{
+					Headers: http.Header{},
+				},
+				requestPath: "/request1",
+				err:         errors.New("fake"),
+				tplCfg: &config.TemplateConfig{
+					InternalServerError: "../../../../templates/internal-server-error.tpl",
+				},
+			},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: headers,
+				Status:  502,
+				Resp: []byte(`<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Internal Server Error</h1>
+    <p>fake</p>
+  </body>
+</html>
+`),
+			},
+		},
+		{
+			name: "Template string should be used",
+			args: args{
+				tplString: "{{ .Path }}: {{ .Error }}",
+				rw: &respWriterTest{
+					Headers: http.Header{},
+				},
+				requestPath: "/request1",
+				err:         errors.New("fake"),
+				tplCfg: &config.TemplateConfig{
+					InternalServerError: "templates/internal-server-error.tpl",
+				},
+			},
+			expectedHTTPWriter: &respWriterTest{
+				Headers: headers,
+				Status:  502,
+				Resp:    []byte("/request1: fake"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			HandleBadGatewayWithTemplate(log.NewLogger(), tt.args.rw, tt.args.tplCfg, tt.args.tplString, tt.args.requestPath, tt.args.err)
+			if !reflect.DeepEqual(tt.expectedHTTPWriter, tt.args.rw) {
+				t.Errorf("HandleBadGatewayWithTemplate() => httpWriter = %+v, want %+v", tt.args.rw, tt.expectedHTTPWriter)
+			}
+		})
+	}
+}
+
 func TestHandleForbiddenWithTemplate(t *testing.T) {
 	headers := http.Header{}
 	headers.Add("Content-Type", "text/html; charset=utf-8")
@@ -998,3 +1140,122 @@ func Test_RequestHost(t *testing.T) {
 		})
 	}
 }
+
+func TestIsJSONRequested(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  http.Header
+		inputURL string
+		want     bool
+	}{
+		{
+			name:     "no format asked",
+			inputURL: "http://localhost/",
+			want:     false,
+		},
+		{
+			name:     "html accept header",
+			headers:  http.Header{"Accept": []string{"text/html,application/xhtml+xml,*/*;q=0.8"}},
+			inputURL: "http://localhost/",
+			want:     false,
+		},
+		{
+			name:     "json format query parameter",
+			inputURL: "http://localhost/?format=json",
+			want:     true,
+		},
+		{
+			name:     "other format query parameter",
+			inputURL: "http://localhost/?format=xml",
+			want:     false,
+		},
+		{
+			name:     "json accept header",
+			headers:  http.Header{"Accept": []string{"application/json"}},
+			inputURL: "http://localhost/",
+			want:     true,
+		},
+		{
+			name:     "json accept header with quality",
+			headers:  http.Header{"Accept": []string{"text/html;q=0.9, application/json;q=1.0"}},
+			inputURL: "http://localhost/",
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", tt.inputURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.headers != nil {
+				req.Header = tt.headers
+			}
+			if got := IsJSONRequested(req); got != tt.want {
+				t.Errorf("IsJSONRequested() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMultipartFormRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        bool
+	}{
+		{
+			name:        "no content type",
+			contentType: "",
+			want:        false,
+		},
+		{
+			name:        "multipart form",
+			contentType: "multipart/form-data; boundary=------------------------abcdef",
+			want:        true,
+		},
+		{
+			name:        "binary content type",
+			contentType: "application/octet-stream",
+			want:        false,
+		},
+		{
+			name:        "invalid content type",
+			contentType: "multipart/form-data; boundary",
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("PUT", "http://localhost/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if got := IsMultipartFormRequest(req); got != tt.want {
+				t.Errorf("IsMultipartFormRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONExecution(t *testing.T) {
+	rw := &respWriterTest{Headers: http.Header{}}
+	err := JSONExecution(rw, map[string]string{"key": "value"}, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantHeaders := http.Header{}
+	wantHeaders.Set("Content-Type", "application/json; charset=utf-8")
+	want := &respWriterTest{
+		Headers: wantHeaders,
+		Status:  200,
+		Resp:    []byte(`{"key":"value"}`),
+	}
+	if !reflect.DeepEqual(rw, want) {
+		t.Errorf("JSONExecution() = %+v, want %+v", rw, want)
+	}
+}
@@ -3,7 +3,10 @@
 package server
 
 import (
+	"mime"
+	"net/http"
 	"net/http/httptest"
+	"path"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -21,7 +24,7 @@ var metricsCtx = metrics.NewClient()
 func setupFakeS3(accessKey, secretAccessKey, region, bucket string) (*httptest.Server, error) {
 	backend := s3mem.New()
 	faker := gofakes3.New(backend)
-	ts := httptest.NewServer(faker.Server())
+	ts := httptest.NewServer(conditionalFakeS3Handler(faker.Server()))
 
 	// configure S3 client
 	s3Config := &aws.Config{
@@ -65,3 +68,83 @@ func setupFakeS3(accessKey, secretAccessKey, region, bucket string) (*httptest.S
 
 	return ts, nil
 }
+
+// conditionalFakeS3Handler will answer If-Match and If-None-Match conditions like S3
+// because they aren't supported by fake S3 server
+func conditionalFakeS3Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Add Content-Type on object responses
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			cw := &contentTypeFakeS3Writer{ResponseWriter: w, key: r.URL.Path}
+			// Headers aren't written by fake S3 server on HEAD requests
+			defer cw.flushHeader()
+			w = cw
+		}
+		ifMatch := r.Header.Get("If-Match")
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		// Check if conditions must be checked
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || (ifMatch == "" && ifNoneMatch == "") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Get object ETag
+		headReq := r.Clone(r.Context())
+		headReq.Method = http.MethodHead
+		headReq.Header.Del("Range")
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, headReq)
+		etag := rec.Header().Get("ETag")
+		// Check conditions on existing objects
+		if rec.Code == http.StatusOK {
+			if ifMatch != "" && ifMatch != etag {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				if r.Method == http.MethodGet {
+					w.Write([]byte("<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>"))
+				}
+				return
+			}
+			if ifNoneMatch != "" && ifNoneMatch == etag {
+				w.Header().Set("ETag", etag)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		// Get object without conditions
+		r.Header.Del("If-Match")
+		r.Header.Del("If-None-Match")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// contentTypeFakeS3Writer will add object Content-Type like S3 because it isn't stored by fake S3 server
+// Otherwise, Content-Type is only detected from body on GET requests.
+type contentTypeFakeS3Writer struct {
+	http.ResponseWriter
+	key         string
+	wroteHeader bool
+}
+
+func (w *contentTypeFakeS3Writer) WriteHeader(code int) {
+	w.wroteHeader = true
+	// Check if Content-Type can be found from key extension
+	if w.Header().Get("Content-Type") == "" && code < http.StatusMultipleChoices {
+		if contentType := mime.TypeByExtension(path.Ext(w.key)); contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *contentTypeFakeS3Writer) flushHeader() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (w *contentTypeFakeS3Writer) Write(b []byte) (int, error) {
+	// Headers are written on first write when WriteHeader isn't called
+	w.flushHeader()
+
+	return w.ResponseWriter.Write(b)
+}
@@ -48,3 +48,10 @@ func GetTraceFromRequest(r *http.Request) Trace {
 		span: sp,
 	}
 }
+
+// StartTrace will start a trace that isn't linked to a request (like background tasks)
+func StartTrace(operationName string) Trace {
+	tracer := opentracing.GlobalTracer()
+
+	return &trace{span: tracer.StartSpan(operationName)}
+}
@@ -0,0 +1,31 @@
+<!DOCTYPE html>
+<html>
+  <body>
+    <h1>Versions of {{ .Path }}</h1>
+    <table style="width:100%">
+        <thead>
+            <tr>
+                <th style="border-right:1px solid black;text-align:start">Version</th>
+                <th style="border-right:1px solid black;text-align:start">Size</th>
+                <th style="text-align:start">Last modified</th>
+            </tr>
+        </thead>
+        <tbody style="border-top:1px solid black">
+        {{- range .Versions }}
+          <tr>
+              <td style="border-right:1px solid black;padding: 0 5px">
+                {{- if .DeleteMarker -}}
+                {{ .VersionID }} (deleted)
+                {{- else -}}
+                <a href="{{ .Path }}">{{ .VersionID }}</a>
+                {{- end -}}
+                {{- if .IsLatest }} (latest){{ end -}}
+              </td>
+              <td style="border-right:1px solid black;padding: 0 5px">{{- if .DeleteMarker -}} - {{- else -}}{{ .Size | humanSize }}{{- end -}}</td>
+              <td style="padding: 0 5px">{{ .LastModified }}</td>
+          </tr>
+        {{- end }}
+        </tbody>
+    </table>
+  </body>
+</html>
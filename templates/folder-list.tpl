@@ -25,5 +25,15 @@
         {{- end }}
         </tbody>
     </table>
+    {{- if or .PreviousPageLink .NextPageLink }}
+    <p>
+      {{- if .PreviousPageLink }}
+      <a href="{{ .PreviousPageLink }}">Previous page</a>
+      {{- end }}
+      {{- if .NextPageLink }}
+      <a href="{{ .NextPageLink }}">Next page</a>
+      {{- end }}
+    </p>
+    {{- end }}
   </body>
 </html>